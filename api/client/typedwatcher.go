@@ -0,0 +1,158 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+// ResourceEvent is a strongly typed watch event for a single resource kind.
+type ResourceEvent[T types.Resource] struct {
+	// Type is types.OpPut for adds and updates, or types.OpDelete.
+	Type types.OpType
+	// Resource is the added or updated resource. It is not set for delete
+	// events, which only carry the resource name.
+	Resource T
+	// Name is the name of the affected resource.
+	Name string
+}
+
+// ResourceWatcher streams typed add, update and delete events for a single
+// resource kind.
+type ResourceWatcher[T types.Resource] struct {
+	watcher types.Watcher
+	eventsC chan ResourceEvent[T]
+}
+
+// Events returns the typed event channel.
+func (w *ResourceWatcher[T]) Events() <-chan ResourceEvent[T] {
+	return w.eventsC
+}
+
+// Done returns a channel that closes once the watcher is closed.
+func (w *ResourceWatcher[T]) Done() <-chan struct{} {
+	return w.watcher.Done()
+}
+
+// Error returns the watcher's error, if any.
+func (w *ResourceWatcher[T]) Error() error {
+	return w.watcher.Error()
+}
+
+// Close closes the watcher.
+func (w *ResourceWatcher[T]) Close() error {
+	return w.watcher.Close()
+}
+
+func (w *ResourceWatcher[T]) forwardEvents() {
+	for {
+		select {
+		case event := <-w.watcher.Events():
+			out := ResourceEvent[T]{Type: event.Type}
+			switch event.Type {
+			case types.OpPut:
+				resource, ok := event.Resource.(T)
+				if !ok {
+					continue
+				}
+				out.Resource = resource
+				out.Name = resource.GetName()
+			case types.OpDelete:
+				out.Name = event.Resource.GetName()
+			default:
+				// Skip the init event, the initial state is delivered
+				// as a snapshot by the Watch* helpers.
+				continue
+			}
+			select {
+			case w.eventsC <- out:
+			case <-w.watcher.Done():
+				return
+			}
+		case <-w.watcher.Done():
+			return
+		}
+	}
+}
+
+// watchResources starts a watcher for the given resource kind and wraps it
+// with typed event delivery. The watcher is started before the caller takes
+// its initial snapshot so no events are missed in between.
+func watchResources[T types.Resource](ctx context.Context, clt *Client, kind string) (*ResourceWatcher[T], error) {
+	watcher, err := clt.NewWatcher(ctx, types.Watch{
+		Kinds: []types.WatchKind{{Kind: kind}},
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	w := &ResourceWatcher[T]{
+		watcher: watcher,
+		eventsC: make(chan ResourceEvent[T]),
+	}
+	go w.forwardEvents()
+	return w, nil
+}
+
+// WatchNodes returns a snapshot of the nodes in the given namespace along
+// with a typed watcher delivering subsequent add, update and delete events.
+func (c *Client) WatchNodes(ctx context.Context, namespace string) ([]types.Server, *ResourceWatcher[types.Server], error) {
+	watcher, err := watchResources[types.Server](ctx, c, types.KindNode)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	nodes, err := c.GetNodes(ctx, namespace)
+	if err != nil {
+		watcher.Close()
+		return nil, nil, trace.Wrap(err)
+	}
+	return nodes, watcher, nil
+}
+
+// WatchAccessRequests returns a snapshot of the access requests matching the
+// filter along with a typed watcher delivering subsequent add, update and
+// delete events.
+func (c *Client) WatchAccessRequests(ctx context.Context, filter types.AccessRequestFilter) ([]types.AccessRequest, *ResourceWatcher[types.AccessRequest], error) {
+	watcher, err := watchResources[types.AccessRequest](ctx, c, types.KindAccessRequest)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	requests, err := c.GetAccessRequests(ctx, filter)
+	if err != nil {
+		watcher.Close()
+		return nil, nil, trace.Wrap(err)
+	}
+	return requests, watcher, nil
+}
+
+// WatchLocks returns a snapshot of the cluster's locks along with a typed
+// watcher delivering subsequent add, update and delete events.
+func (c *Client) WatchLocks(ctx context.Context) ([]types.Lock, *ResourceWatcher[types.Lock], error) {
+	watcher, err := watchResources[types.Lock](ctx, c, types.KindLock)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	locks, err := c.GetLocks(ctx, false)
+	if err != nil {
+		watcher.Close()
+		return nil, nil, trace.Wrap(err)
+	}
+	return locks, watcher, nil
+}
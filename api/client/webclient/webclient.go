@@ -394,6 +394,11 @@ type AuthenticationSettings struct {
 	// DeviceTrustDisabled provides a clue to Teleport clients on whether to avoid
 	// device authentication.
 	DeviceTrustDisabled bool `json:"device_trust_disabled,omitempty"`
+	// RecordingEncryptionPublicKey is a base64-encoded curve25519 public key
+	// clients should use to encrypt locally captured session recordings
+	// before upload. Empty when client-side recording encryption is not
+	// configured.
+	RecordingEncryptionPublicKey string `json:"recording_encryption_public_key,omitempty"`
 
 	// HasMessageOfTheDay is a flag indicating that the cluster has MOTD
 	// banner text that must be retrieved, displayed and acknowledged by
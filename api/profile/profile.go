@@ -99,6 +99,19 @@ type Profile struct {
 
 	// PrivateKeyPolicy is a key policy enforced for this profile.
 	PrivateKeyPolicy keys.PrivateKeyPolicy `yaml:"private_key_policy"`
+
+	// AcknowledgedMOTD is the hash of the last message of the day the user
+	// acknowledged, used to avoid re-prompting for the same message.
+	AcknowledgedMOTD string `yaml:"acknowledged_motd,omitempty"`
+
+	// KubeContextTemplate is an optional Go template used to name generated
+	// kubeconfig contexts instead of the default
+	// "<teleport-cluster>-<kube-cluster>" scheme.
+	KubeContextTemplate string `yaml:"kube_context_template,omitempty"`
+
+	// KubeNamespaces maps kubernetes cluster names to the default namespace
+	// to set in generated kubeconfig contexts.
+	KubeNamespaces map[string]string `yaml:"kube_namespaces,omitempty"`
 }
 
 // Copy returns a shallow copy of p, or nil if p is nil.
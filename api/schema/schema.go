@@ -0,0 +1,101 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schema defines the version envelope wrapped around machine-readable
+// JSON outputs of Teleport client tools. Downstream parsers can decode the
+// envelope first, check the kind and schema version against what they were
+// written for, and fail with a clear error instead of silently misparsing
+// output whose shape changed between releases.
+package schema
+
+import (
+	"encoding/json"
+
+	"github.com/gravitational/trace"
+)
+
+// Version identifies the shape of an enveloped payload. The version is scoped
+// to the envelope's kind: it is only incremented when the payload of that
+// kind changes incompatibly (fields removed, renamed or retyped). Adding new
+// fields is backwards compatible and does not change the version.
+type Version string
+
+// V1 is the initial schema version of every payload kind.
+const V1 Version = "v1"
+
+// Envelope wraps a JSON payload with its kind and schema version.
+type Envelope struct {
+	// Kind names the payload, e.g. "nodes" or "profile".
+	Kind string `json:"kind"`
+	// Version is the schema version of the payload for this kind.
+	Version Version `json:"version"`
+	// Data is the payload itself.
+	Data json.RawMessage `json:"data"`
+}
+
+// NewEnvelope marshals the payload and wraps it with the given kind and
+// schema version.
+func NewEnvelope(kind string, version Version, payload interface{}) (*Envelope, error) {
+	if kind == "" {
+		return nil, trace.BadParameter("missing envelope kind")
+	}
+	if version == "" {
+		return nil, trace.BadParameter("missing envelope version")
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Envelope{
+		Kind:    kind,
+		Version: version,
+		Data:    data,
+	}, nil
+}
+
+// Decode unmarshals the envelope's payload into out after verifying the
+// envelope carries the expected kind and version. A mismatch returns a
+// BadParameter error naming both sides, so parsers built against an older
+// schema fail loudly when the producer moved on.
+func (e *Envelope) Decode(kind string, version Version, out interface{}) error {
+	if e.Kind != kind {
+		return trace.BadParameter("expected envelope kind %q, got %q", kind, e.Kind)
+	}
+	if e.Version != version {
+		return trace.BadParameter("expected %q schema version %q, got %q", kind, version, e.Version)
+	}
+	if err := json.Unmarshal(e.Data, out); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// Parse decodes the envelope itself from raw JSON output without touching
+// the payload, so callers can inspect the kind and version before picking a
+// payload type to decode into.
+func Parse(raw []byte) (*Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if envelope.Kind == "" {
+		return nil, trace.BadParameter("output carries no envelope kind, it likely predates schema envelopes")
+	}
+	if envelope.Version == "" {
+		return nil, trace.BadParameter("output carries no schema version, it likely predates schema envelopes")
+	}
+	return &envelope, nil
+}
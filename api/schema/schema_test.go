@@ -0,0 +1,68 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	envelope, err := NewEnvelope("nodes", V1, payload{Name: "node-1"})
+	require.NoError(t, err)
+
+	raw, err := json.Marshal(envelope)
+	require.NoError(t, err)
+
+	parsed, err := Parse(raw)
+	require.NoError(t, err)
+	require.Equal(t, "nodes", parsed.Kind)
+	require.Equal(t, V1, parsed.Version)
+
+	var out payload
+	require.NoError(t, parsed.Decode("nodes", V1, &out))
+	require.Equal(t, "node-1", out.Name)
+
+	// Kind and version mismatches fail loudly.
+	err = parsed.Decode("profile", V1, &out)
+	require.True(t, trace.IsBadParameter(err))
+	err = parsed.Decode("nodes", Version("v2"), &out)
+	require.True(t, trace.IsBadParameter(err))
+}
+
+func TestNewEnvelopeValidation(t *testing.T) {
+	_, err := NewEnvelope("", V1, nil)
+	require.True(t, trace.IsBadParameter(err))
+	_, err = NewEnvelope("nodes", "", nil)
+	require.True(t, trace.IsBadParameter(err))
+}
+
+func TestParseUnversionedOutput(t *testing.T) {
+	// Output that predates schema envelopes is rejected rather than
+	// misparsed.
+	_, err := Parse([]byte(`[{"name": "node-1"}]`))
+	require.Error(t, err)
+	_, err = Parse([]byte(`{"name": "node-1"}`))
+	require.True(t, trace.IsBadParameter(err))
+}
@@ -49,6 +49,35 @@ func (a AuthenticatorAttachment) String() string {
 	return ""
 }
 
+// AuthenticatorTransport identifies a class of authenticator transports.
+type AuthenticatorTransport int
+
+const (
+	// TransportUSB identifies USB security keys.
+	TransportUSB AuthenticatorTransport = iota
+	// TransportNFC identifies NFC security keys.
+	TransportNFC
+	// TransportBLE identifies Bluetooth security keys.
+	TransportBLE
+	// TransportPlatform identifies platform authenticators (Touch ID,
+	// Windows Hello, etc).
+	TransportPlatform
+)
+
+func (t AuthenticatorTransport) String() string {
+	switch t {
+	case TransportUSB:
+		return "usb"
+	case TransportNFC:
+		return "nfc"
+	case TransportBLE:
+		return "ble"
+	case TransportPlatform:
+		return "platform"
+	}
+	return ""
+}
+
 // CredentialInfo holds information about a WebAuthn credential, typically a
 // resident public key credential.
 type CredentialInfo struct {
@@ -96,6 +125,31 @@ type LoginOpts struct {
 	User string
 	// AuthenticatorAttachment specifies the desired authenticator attachment.
 	AuthenticatorAttachment AuthenticatorAttachment
+	// AllowedTransports restricts login to authenticators reachable via the
+	// given transports. An empty slice allows all transports.
+	// Transport restrictions are applied on a best-effort basis: flows that
+	// cannot distinguish transports (e.g. the Windows WebAuthn API) only honor
+	// the platform vs cross-platform distinction.
+	AllowedTransports []AuthenticatorTransport
+}
+
+// allowsTransport returns true if the options allow the given transport.
+func (o *LoginOpts) allowsTransport(transport AuthenticatorTransport) bool {
+	if o == nil || len(o.AllowedTransports) == 0 {
+		return true
+	}
+	for _, t := range o.AllowedTransports {
+		if t == transport {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsCrossPlatform returns true if the options allow at least one
+// cross-platform transport.
+func (o *LoginOpts) allowsCrossPlatform() bool {
+	return o.allowsTransport(TransportUSB) || o.allowsTransport(TransportNFC) || o.allowsTransport(TransportBLE)
 }
 
 // Login performs client-side, U2F-compatible, Webauthn login.
@@ -131,6 +185,20 @@ func Login(
 		user = opts.User
 	}
 
+	// Sanity check attachment against transport restrictions, then narrow the
+	// attachment if the restrictions exclude platform or cross-platform
+	// authenticators entirely.
+	switch {
+	case attachment == AttachmentPlatform && !opts.allowsTransport(TransportPlatform):
+		return nil, "", trace.BadParameter("platform attachment requested, but the platform transport is not allowed")
+	case attachment == AttachmentCrossPlatform && !opts.allowsCrossPlatform():
+		return nil, "", trace.BadParameter("cross-platform attachment requested, but no cross-platform transports are allowed")
+	case attachment == AttachmentAuto && !opts.allowsTransport(TransportPlatform) && opts.allowsCrossPlatform():
+		attachment = AttachmentCrossPlatform
+	case attachment == AttachmentAuto && opts.allowsTransport(TransportPlatform) && !opts.allowsCrossPlatform():
+		attachment = AttachmentPlatform
+	}
+
 	if webauthnwin.IsAvailable() {
 		log.Debug("WebAuthnWin: Using windows webauthn for credential assertion")
 		return webauthnwin.Login(ctx, origin, assertion, &webauthnwin.LoginOpts{
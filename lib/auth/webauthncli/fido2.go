@@ -92,6 +92,26 @@ func isLibfido2Enabled() bool {
 	return !ok || val == "1"
 }
 
+// fido2Devices implements FIDO2Devices.
+func fido2Devices(ctx context.Context) ([]FIDO2DeviceInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	locs, err := fidoDeviceLocations()
+	if err != nil {
+		return nil, trace.Wrap(err, "device locations")
+	}
+	devices := make([]FIDO2DeviceInfo, 0, len(locs))
+	for _, loc := range locs {
+		devices = append(devices, FIDO2DeviceInfo{
+			Path:         loc.Path,
+			Manufacturer: loc.Manufacturer,
+			Product:      loc.Product,
+		})
+	}
+	return devices, nil
+}
+
 // fido2Login implements FIDO2Login.
 func fido2Login(
 	ctx context.Context,
@@ -58,6 +58,24 @@ func FIDO2Register(
 	return fido2Register(ctx, origin, cc, prompt)
 }
 
+// FIDO2DeviceInfo describes a connected FIDO2 device.
+type FIDO2DeviceInfo struct {
+	// Path is the platform-specific device path, unique per device.
+	Path string
+	// Manufacturer is the device manufacturer string, if available.
+	Manufacturer string
+	// Product is the device product string, if available.
+	Product string
+}
+
+// FIDO2Devices enumerates currently-connected FIDO2 devices, allowing callers
+// to implement their own device selection UIs.
+// Requires a libfido2-enabled build, otherwise it errors.
+func FIDO2Devices(ctx context.Context) ([]FIDO2DeviceInfo, error) {
+	devices, err := fido2Devices(ctx)
+	return devices, trace.Wrap(err)
+}
+
 type FIDO2DiagResult struct {
 	Available                           bool
 	RegisterSuccessful, LoginSuccessful bool
@@ -0,0 +1,234 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	apidefaults "github.com/gravitational/teleport/api/defaults"
+	"github.com/gravitational/teleport/api/types"
+)
+
+// AccessRequestSortField determines the ordering of listed access requests.
+type AccessRequestSortField string
+
+const (
+	// AccessRequestSortCreated sorts by creation time.
+	AccessRequestSortCreated AccessRequestSortField = "created"
+	// AccessRequestSortState sorts by request state.
+	AccessRequestSortState AccessRequestSortField = "state"
+	// AccessRequestSortUser sorts by requesting user.
+	AccessRequestSortUser AccessRequestSortField = "user"
+)
+
+// ListAccessRequestsRequest describes a paginated access request listing.
+type ListAccessRequestsRequest struct {
+	// Filter is the basic filter forwarded to the cluster.
+	Filter types.AccessRequestFilter
+	// States, when non-empty, restricts results to requests in one of the
+	// given states.
+	States []types.RequestState
+	// CreatedAfter, when set, restricts results to requests created at or
+	// after the given time.
+	CreatedAfter time.Time
+	// CreatedBefore, when set, restricts results to requests created before
+	// the given time.
+	CreatedBefore time.Time
+	// SortBy determines the result ordering, AccessRequestSortCreated when
+	// unset.
+	SortBy AccessRequestSortField
+	// Descending reverses the sort order.
+	Descending bool
+	// Limit is the maximum page size, apidefaults.DefaultChunkSize when
+	// unset.
+	Limit int
+	// StartKey is the name of the request to resume after, as returned in
+	// ListAccessRequestsResponse.NextKey.
+	StartKey string
+}
+
+// CheckAndSetDefaults validates the request and sets defaults.
+func (r *ListAccessRequestsRequest) CheckAndSetDefaults() error {
+	switch r.SortBy {
+	case "":
+		r.SortBy = AccessRequestSortCreated
+	case AccessRequestSortCreated, AccessRequestSortState, AccessRequestSortUser:
+	default:
+		return trace.BadParameter("unsupported access request sort field %q", r.SortBy)
+	}
+	if r.Limit < 0 {
+		return trace.BadParameter("access request page limit must not be negative")
+	}
+	if r.Limit == 0 {
+		r.Limit = apidefaults.DefaultChunkSize
+	}
+	return nil
+}
+
+// ListAccessRequestsResponse is a single page of access requests.
+type ListAccessRequestsResponse struct {
+	// Requests is the page of matching access requests.
+	Requests []types.AccessRequest
+	// NextKey resumes the listing on the next page; empty when this is the
+	// last page.
+	NextKey string
+}
+
+// ListAccessRequests loads a single page of access requests matching the
+// supplied filters, sorted and paginated so callers displaying large request
+// histories don't need to hold the entire set.
+func (tc *TeleportClient) ListAccessRequests(ctx context.Context, req ListAccessRequestsRequest) (*ListAccessRequestsResponse, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/ListAccessRequests",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	if err := req.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	reqs, err := tc.GetAccessRequests(ctx, req.Filter)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	reqs = filterAccessRequests(reqs, req)
+	sortAccessRequests(reqs, req.SortBy, req.Descending)
+	return paginateAccessRequests(reqs, req.StartKey, req.Limit)
+}
+
+// StreamAccessRequests invokes fn for every access request matching the
+// supplied filters, in sort order, paging through the results. Iteration
+// stops on the first error returned by fn.
+func (tc *TeleportClient) StreamAccessRequests(ctx context.Context, req ListAccessRequestsRequest, fn func(types.AccessRequest) error) error {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/StreamAccessRequests",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	for {
+		page, err := tc.ListAccessRequests(ctx, req)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		for _, r := range page.Requests {
+			if err := ctx.Err(); err != nil {
+				return trace.Wrap(err)
+			}
+			if err := fn(r); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+		if page.NextKey == "" {
+			return nil
+		}
+		req.StartKey = page.NextKey
+	}
+}
+
+// filterAccessRequests applies the state and creation time filters.
+func filterAccessRequests(reqs []types.AccessRequest, req ListAccessRequestsRequest) []types.AccessRequest {
+	filtered := reqs[:0]
+	for _, r := range reqs {
+		if len(req.States) != 0 && !requestStateMatches(r.GetState(), req.States) {
+			continue
+		}
+		if !req.CreatedAfter.IsZero() && r.GetCreationTime().Before(req.CreatedAfter) {
+			continue
+		}
+		if !req.CreatedBefore.IsZero() && !r.GetCreationTime().Before(req.CreatedBefore) {
+			continue
+		}
+		filtered = append(filtered, r)
+	}
+	return filtered
+}
+
+func requestStateMatches(state types.RequestState, states []types.RequestState) bool {
+	for _, s := range states {
+		if state == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sortAccessRequests sorts the requests in place, breaking ties by request
+// name so the ordering is stable across pages.
+func sortAccessRequests(reqs []types.AccessRequest, sortBy AccessRequestSortField, descending bool) {
+	less := func(a, b types.AccessRequest) bool {
+		switch sortBy {
+		case AccessRequestSortState:
+			if a.GetState() != b.GetState() {
+				return a.GetState() < b.GetState()
+			}
+		case AccessRequestSortUser:
+			if a.GetUser() != b.GetUser() {
+				return a.GetUser() < b.GetUser()
+			}
+		default:
+			if !a.GetCreationTime().Equal(b.GetCreationTime()) {
+				return a.GetCreationTime().Before(b.GetCreationTime())
+			}
+		}
+		return a.GetName() < b.GetName()
+	}
+	sort.SliceStable(reqs, func(i, j int) bool {
+		if descending {
+			return less(reqs[j], reqs[i])
+		}
+		return less(reqs[i], reqs[j])
+	})
+}
+
+// paginateAccessRequests cuts a page of up to limit requests out of the
+// sorted result set, resuming after startKey when set.
+func paginateAccessRequests(reqs []types.AccessRequest, startKey string, limit int) (*ListAccessRequestsResponse, error) {
+	start := 0
+	if startKey != "" {
+		found := false
+		for i, r := range reqs {
+			if r.GetName() == startKey {
+				start = i + 1
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, trace.BadParameter("access request listing start key %q not found", startKey)
+		}
+	}
+
+	end := start + limit
+	if end > len(reqs) {
+		end = len(reqs)
+	}
+	resp := &ListAccessRequestsResponse{Requests: reqs[start:end]}
+	if end < len(reqs) {
+		resp.NextKey = reqs[end-1].GetName()
+	}
+	return resp, nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+func accessRequestForTest(t *testing.T, name, user string, state types.RequestState, created time.Time) types.AccessRequest {
+	t.Helper()
+	req, err := types.NewAccessRequest(name, user, "admin")
+	require.NoError(t, err)
+	require.NoError(t, req.SetState(state))
+	req.SetCreationTime(created)
+	return req
+}
+
+func TestListAccessRequestsRequestCheckAndSetDefaults(t *testing.T) {
+	t.Parallel()
+
+	req := ListAccessRequestsRequest{}
+	require.NoError(t, req.CheckAndSetDefaults())
+	require.Equal(t, AccessRequestSortCreated, req.SortBy)
+	require.NotZero(t, req.Limit)
+
+	req = ListAccessRequestsRequest{SortBy: "bogus"}
+	require.True(t, trace.IsBadParameter(req.CheckAndSetDefaults()))
+
+	req = ListAccessRequestsRequest{Limit: -1}
+	require.True(t, trace.IsBadParameter(req.CheckAndSetDefaults()))
+}
+
+func TestAccessRequestFilterSortPaginate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	reqs := []types.AccessRequest{
+		accessRequestForTest(t, "req-a", "bob", types.RequestState_PENDING, now.Add(2*time.Hour)),
+		accessRequestForTest(t, "req-b", "alice", types.RequestState_APPROVED, now),
+		accessRequestForTest(t, "req-c", "carol", types.RequestState_DENIED, now.Add(time.Hour)),
+		accessRequestForTest(t, "req-d", "alice", types.RequestState_PENDING, now.Add(3*time.Hour)),
+	}
+
+	t.Run("filter by state", func(t *testing.T) {
+		filtered := filterAccessRequests(append([]types.AccessRequest{}, reqs...), ListAccessRequestsRequest{
+			States: []types.RequestState{types.RequestState_PENDING},
+		})
+		require.Len(t, filtered, 2)
+		for _, r := range filtered {
+			require.Equal(t, types.RequestState_PENDING, r.GetState())
+		}
+	})
+
+	t.Run("filter by creation time window", func(t *testing.T) {
+		filtered := filterAccessRequests(append([]types.AccessRequest{}, reqs...), ListAccessRequestsRequest{
+			CreatedAfter:  now.Add(30 * time.Minute),
+			CreatedBefore: now.Add(3 * time.Hour),
+		})
+		require.Len(t, filtered, 2)
+		require.Equal(t, "req-a", filtered[0].GetName())
+		require.Equal(t, "req-c", filtered[1].GetName())
+	})
+
+	t.Run("sort by created descending", func(t *testing.T) {
+		sorted := append([]types.AccessRequest{}, reqs...)
+		sortAccessRequests(sorted, AccessRequestSortCreated, true)
+		require.Equal(t, "req-d", sorted[0].GetName())
+		require.Equal(t, "req-b", sorted[3].GetName())
+	})
+
+	t.Run("sort by user breaks ties by name", func(t *testing.T) {
+		sorted := append([]types.AccessRequest{}, reqs...)
+		sortAccessRequests(sorted, AccessRequestSortUser, false)
+		require.Equal(t, "req-b", sorted[0].GetName())
+		require.Equal(t, "req-d", sorted[1].GetName())
+		require.Equal(t, "req-a", sorted[2].GetName())
+		require.Equal(t, "req-c", sorted[3].GetName())
+	})
+
+	t.Run("paginate", func(t *testing.T) {
+		sorted := append([]types.AccessRequest{}, reqs...)
+		sortAccessRequests(sorted, AccessRequestSortCreated, false)
+
+		page, err := paginateAccessRequests(sorted, "", 3)
+		require.NoError(t, err)
+		require.Len(t, page.Requests, 3)
+		require.Equal(t, "req-a", page.NextKey)
+
+		page, err = paginateAccessRequests(sorted, page.NextKey, 3)
+		require.NoError(t, err)
+		require.Len(t, page.Requests, 1)
+		require.Equal(t, "req-d", page.Requests[0].GetName())
+		require.Empty(t, page.NextKey)
+
+		_, err = paginateAccessRequests(sorted, "missing", 3)
+		require.True(t, trace.IsBadParameter(err))
+	})
+}
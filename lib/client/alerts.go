@@ -0,0 +1,271 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/types"
+)
+
+// ClusterAlertFilter matches cluster alerts client-side, on top of whatever
+// filtering the auth server already applied to a GetClusterAlertsRequest.
+type ClusterAlertFilter struct {
+	// Severity is the minimum severity of matching alerts. Alerts with a
+	// lower severity are filtered out.
+	Severity types.AlertSeverity
+	// Labels matches alerts that contain all of the given label pairs.
+	Labels map[string]string
+}
+
+// Match returns true if the given alert passes the filter.
+func (f *ClusterAlertFilter) Match(alert types.ClusterAlert) bool {
+	if alert.Spec.Severity < f.Severity {
+		return false
+	}
+	for key, val := range f.Labels {
+		if alert.Metadata.Labels[key] != val {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterClusterAlerts returns the subset of alerts matching the supplied
+// filter, preserving the original ordering.
+func FilterClusterAlerts(alerts []types.ClusterAlert, filter ClusterAlertFilter) []types.ClusterAlert {
+	var filtered []types.ClusterAlert
+	for _, alert := range alerts {
+		if filter.Match(alert) {
+			filtered = append(filtered, alert)
+		}
+	}
+	return filtered
+}
+
+// GetFilteredClusterAlerts returns a list of matching alerts from the current
+// cluster, with the additional client-side filter applied.
+func (tc *TeleportClient) GetFilteredClusterAlerts(ctx context.Context, req types.GetClusterAlertsRequest, filter ClusterAlertFilter) ([]types.ClusterAlert, error) {
+	ctx, span := tc.Tracer.Start(ctx,
+		"teleportClient/GetFilteredClusterAlerts",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	alerts, err := tc.GetClusterAlerts(ctx, req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return FilterClusterAlerts(alerts, filter), nil
+}
+
+// defaultClusterAlertWatchInterval is how often WatchClusterAlerts checks the
+// cluster for newly raised alerts when no interval is specified.
+const defaultClusterAlertWatchInterval = time.Minute
+
+// WatchClusterAlertsRequest configures a cluster alert subscription.
+type WatchClusterAlertsRequest struct {
+	// Request is the server-side alert query. An empty request matches all
+	// alerts visible to the user.
+	Request types.GetClusterAlertsRequest
+	// Filter is an additional client-side filter applied to each batch.
+	Filter ClusterAlertFilter
+	// Interval is how often the cluster is checked for new alerts. Defaults
+	// to defaultClusterAlertWatchInterval.
+	Interval time.Duration
+}
+
+// CheckAndSetDefaults validates the request and fills in default values.
+func (r *WatchClusterAlertsRequest) CheckAndSetDefaults() error {
+	if r.Interval < 0 {
+		return trace.BadParameter("interval must not be negative")
+	}
+	if r.Interval == 0 {
+		r.Interval = defaultClusterAlertWatchInterval
+	}
+	return nil
+}
+
+// WatchClusterAlerts subscribes to cluster alerts, so long-running UIs can
+// surface newly raised alerts (e.g. an upcoming CA rotation) without polling
+// themselves. The first batch carries all currently matching alerts; after
+// that only alerts not seen before are delivered. The returned channel is
+// closed when the context is canceled or the watch fails.
+func (tc *TeleportClient) WatchClusterAlerts(ctx context.Context, req WatchClusterAlertsRequest) (<-chan types.ClusterAlert, error) {
+	ctx, span := tc.Tracer.Start(ctx,
+		"teleportClient/WatchClusterAlerts",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	if err := req.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	alertCh := make(chan types.ClusterAlert, 64)
+	go func() {
+		defer close(alertCh)
+		defer proxyClient.Close()
+
+		seen := make(map[string]struct{})
+		ticker := time.NewTicker(req.Interval)
+		defer ticker.Stop()
+
+		for {
+			alerts, err := proxyClient.GetClusterAlerts(ctx, req.Request)
+			if err != nil {
+				log.WithError(err).Warn("Cluster alert watch failed.")
+				return
+			}
+			alerts = FilterClusterAlerts(alerts, req.Filter)
+			types.SortClusterAlerts(alerts)
+			for _, alert := range alerts {
+				if _, ok := seen[alert.Metadata.Name]; ok {
+					continue
+				}
+				seen[alert.Metadata.Name] = struct{}{}
+				select {
+				case alertCh <- alert:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return alertCh, nil
+}
+
+// AcknowledgeClusterAlert marks a cluster alert as acknowledged, suppressing
+// it until the acknowledgement expires. Requires permission to update cluster
+// alerts.
+func (tc *TeleportClient) AcknowledgeClusterAlert(ctx context.Context, ack types.AlertAcknowledgement) error {
+	ctx, span := tc.Tracer.Start(ctx,
+		"teleportClient/AcknowledgeClusterAlert",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	if err := ack.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	return trace.Wrap(proxyClient.CreateAlertAck(ctx, ack))
+}
+
+// GetClusterAlertAcks gets the active cluster alert acknowledgements.
+func (tc *TeleportClient) GetClusterAlertAcks(ctx context.Context) ([]types.AlertAcknowledgement, error) {
+	ctx, span := tc.Tracer.Start(ctx,
+		"teleportClient/GetClusterAlertAcks",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	acks, err := proxyClient.GetAlertAcks(ctx)
+	return acks, trace.Wrap(err)
+}
+
+// ClearClusterAlertAcks clears the acknowledgement with the given alert ID,
+// un-suppressing the alert. The special ID '*' clears all acknowledgements.
+func (tc *TeleportClient) ClearClusterAlertAcks(ctx context.Context, alertID string) error {
+	ctx, span := tc.Tracer.Start(ctx,
+		"teleportClient/ClearClusterAlertAcks",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	return trace.Wrap(proxyClient.ClearAlertAcks(ctx, proto.ClearAlertAcksRequest{AlertID: alertID}))
+}
+
+// CreateAlertAck marks a cluster alert as acknowledged.
+func (proxy *ProxyClient) CreateAlertAck(ctx context.Context, ack types.AlertAcknowledgement) error {
+	ctx, span := proxy.Tracer.Start(
+		ctx,
+		"proxyClient/CreateAlertAck",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	site := proxy.CurrentCluster()
+	defer site.Close()
+
+	return trace.Wrap(site.CreateAlertAck(ctx, ack))
+}
+
+// GetAlertAcks gets active cluster alert acknowledgements.
+func (proxy *ProxyClient) GetAlertAcks(ctx context.Context) ([]types.AlertAcknowledgement, error) {
+	ctx, span := proxy.Tracer.Start(
+		ctx,
+		"proxyClient/GetAlertAcks",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	site := proxy.CurrentCluster()
+	defer site.Close()
+
+	acks, err := site.GetAlertAcks(ctx)
+	return acks, trace.Wrap(err)
+}
+
+// ClearAlertAcks clears alert acknowledgements.
+func (proxy *ProxyClient) ClearAlertAcks(ctx context.Context, req proto.ClearAlertAcksRequest) error {
+	ctx, span := proxy.Tracer.Start(
+		ctx,
+		"proxyClient/ClearAlertAcks",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	site := proxy.CurrentCluster()
+	defer site.Close()
+
+	return trace.Wrap(site.ClearAlertAcks(ctx, req))
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+func TestFilterClusterAlerts(t *testing.T) {
+	t.Parallel()
+
+	newAlert := func(name string, severity types.AlertSeverity, opts ...types.AlertOption) types.ClusterAlert {
+		opts = append(opts, types.WithAlertSeverity(severity))
+		alert, err := types.NewClusterAlert(name, "some message", opts...)
+		require.NoError(t, err)
+		return alert
+	}
+
+	alerts := []types.ClusterAlert{
+		newAlert("low", types.AlertSeverity_LOW),
+		newAlert("medium", types.AlertSeverity_MEDIUM, types.WithAlertLabel(types.AlertOnLogin, "yes")),
+		newAlert("high", types.AlertSeverity_HIGH),
+	}
+
+	tests := []struct {
+		name     string
+		filter   ClusterAlertFilter
+		expected []string
+	}{
+		{
+			name:     "empty filter matches everything",
+			filter:   ClusterAlertFilter{},
+			expected: []string{"low", "medium", "high"},
+		},
+		{
+			name:     "severity filter drops lower severities",
+			filter:   ClusterAlertFilter{Severity: types.AlertSeverity_MEDIUM},
+			expected: []string{"medium", "high"},
+		},
+		{
+			name:     "label filter matches exact pairs",
+			filter:   ClusterAlertFilter{Labels: map[string]string{types.AlertOnLogin: "yes"}},
+			expected: []string{"medium"},
+		},
+		{
+			name:     "mismatched label value matches nothing",
+			filter:   ClusterAlertFilter{Labels: map[string]string{types.AlertOnLogin: "no"}},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var names []string
+			for _, alert := range FilterClusterAlerts(alerts, tt.filter) {
+				names = append(names, alert.Metadata.Name)
+			}
+			require.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestWatchClusterAlertsRequestCheckAndSetDefaults(t *testing.T) {
+	t.Parallel()
+
+	req := WatchClusterAlertsRequest{}
+	require.NoError(t, req.CheckAndSetDefaults())
+	require.Equal(t, defaultClusterAlertWatchInterval, req.Interval)
+
+	req = WatchClusterAlertsRequest{Interval: -time.Second}
+	require.Error(t, req.CheckAndSetDefaults())
+}
@@ -18,20 +18,25 @@ package client
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"io"
 	"net"
+	"net/url"
 	"os"
 	"os/exec"
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -41,6 +46,7 @@ import (
 	oteltrace "go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/exp/slices"
 	"golang.org/x/net/http2"
 	"golang.org/x/sync/errgroup"
 
@@ -114,6 +120,22 @@ const (
 	ForwardAgentLocal
 )
 
+// PTYAllocationMode controls whether a server-side PTY is requested for
+// remote command execution, mirroring the OpenSSH -t/-tt/-T flags.
+type PTYAllocationMode int
+
+const (
+	// PTYAllocationAuto requests a PTY for commands only when Interactive is
+	// set and stdin is a terminal. This is the default behavior.
+	PTYAllocationAuto PTYAllocationMode = iota
+	// PTYAllocationForce requests a PTY for commands even when stdin is not
+	// a terminal, like "ssh -tt".
+	PTYAllocationForce
+	// PTYAllocationDisabled never requests a PTY for commands, even when
+	// Interactive is set, like "ssh -T".
+	PTYAllocationDisabled
+)
+
 var log = logrus.WithFields(logrus.Fields{
 	trace.Component: teleport.ComponentClient,
 })
@@ -126,20 +148,28 @@ type ForwardedPort struct {
 	SrcPort  int
 	DestPort int
 	DestHost string
+	// DestSocket is the path of a Unix domain socket on the remote host to
+	// forward to, e.g. /var/run/docker.sock. When set, DestHost and
+	// DestPort are unused.
+	DestSocket string
 }
 
 // ForwardedPorts contains an array of forwarded port structs
 type ForwardedPorts []ForwardedPort
 
 // ToString returns a string representation of a forwarded port spec, compatible
-// with OpenSSH's -L  flag, i.e. "src_host:src_port:dest_host:dest_port".
+// with OpenSSH's -L  flag, i.e. "src_host:src_port:dest_host:dest_port" or
+// "src_host:src_port:dest_socket" for Unix domain socket destinations.
 func (p *ForwardedPort) ToString() string {
 	sport := strconv.Itoa(p.SrcPort)
-	dport := strconv.Itoa(p.DestPort)
+	dest := p.DestSocket
+	if dest == "" {
+		dest = net.JoinHostPort(p.DestHost, strconv.Itoa(p.DestPort))
+	}
 	if utils.IsLocalhost(p.SrcIP) {
-		return sport + ":" + net.JoinHostPort(p.DestHost, dport)
+		return sport + ":" + dest
 	}
-	return net.JoinHostPort(p.SrcIP, sport) + ":" + net.JoinHostPort(p.DestHost, dport)
+	return net.JoinHostPort(p.SrcIP, sport) + ":" + dest
 }
 
 // DynamicForwardedPort local port for dynamic application-level port
@@ -191,12 +221,33 @@ type Config struct {
 	// Labels represent host Labels
 	Labels map[string]string
 
+	// MaxNodeMatches, when positive, caps how many nodes a label-based
+	// target resolution may match. The limit is enforced server-side so the
+	// client never pages through the full node inventory; resolution fails
+	// if more nodes match than the cap allows.
+	MaxNodeMatches int
+
+	// FailOnAmbiguousHost makes label-based target resolution fail when the
+	// filters match more than a single node instead of fanning out to all
+	// matches.
+	FailOnAmbiguousHost bool
+
 	// Namespace is nodes namespace
 	Namespace string
 
 	// HostLogin is a user login on a remote host
 	HostLogin string
 
+	// ExplicitHostLogin is true when HostLogin was specified by the user
+	// (e.g. via a --login flag) rather than defaulted to the local username,
+	// so SelectHostLogin knows not to second-guess it.
+	ExplicitHostLogin bool
+
+	// HostLoginSelector is an optional callback used by SelectHostLogin to
+	// resolve an ambiguous host login choice, typically by prompting the
+	// user. When unset, ambiguity is returned as an error.
+	HostLoginSelector HostLoginSelectorFunc
+
 	// HostPort is a remote host port to connect to. This is used for **explicit**
 	// port setting via -p flag, otherwise '0' is passed which means "use server default"
 	HostPort int
@@ -229,6 +280,13 @@ type Config struct {
 	// InsecureSkipVerify is an option to skip HTTPS cert check
 	InsecureSkipVerify bool
 
+	// CAPins are SPKI hashes (in "sha256:<hex>" format) that the
+	// certificate chain presented by the proxy is verified against before
+	// the first round-trip, mirroring the CA pinning used for node joins.
+	// This protects first logins on hostile networks where the proxy CA is
+	// not yet known to the client.
+	CAPins []string
+
 	// SkipLocalAuth tells the client not to use its own SSH agent or ask user for passwords. This is
 	// used by external programs linking against Teleport client and obtaining credentials from elsewhere.
 	// e.g. from an identity file.
@@ -277,6 +335,17 @@ type Config struct {
 	// process execution (via SSH exec)
 	ExitStatus int
 
+	// ExecResults is populated by multi-node command execution with the
+	// per-node outcome of the run, sorted by host, so script callers can
+	// tell which nodes failed and how.
+	ExecResults []NodeExecResult
+
+	// MultiNodeOutput selects how output from multi-node command execution
+	// is presented: interleaved as it arrives (the default), prefixed with
+	// the originating host per line, or buffered per node and emitted in a
+	// stable order when the run completes.
+	MultiNodeOutput MultiNodeOutputMode
+
 	// SiteName specifies site to execute operation,
 	// if omitted, first available site will be selected
 	SiteName string
@@ -298,15 +367,85 @@ type Config struct {
 	// port forwarding (parameters to -D ssh flag).
 	DynamicForwardedPorts DynamicForwardedPorts
 
+	// ReconnectPortForwards keeps local forwarding listeners usable across
+	// node restarts by re-establishing the node connection once the node
+	// returns, instead of failing every dial forever.
+	ReconnectPortForwards bool
+
+	// OnPortForwardOutage, if set, is called after a lost node connection
+	// backing forwarded ports has been re-established, describing the
+	// outage window.
+	OnPortForwardOutage PortForwardOutageCallback
+
+	// LocalPortRange, if set, enables automatic local port selection: when
+	// the requested local port of a forward or tunnel is busy, a free port
+	// from this range is bound instead and reported via OnLocalPortSelected
+	// or a machine-readable notice.
+	LocalPortRange PortRange
+
+	// OnLocalPortSelected, if set, is called when automatic port selection
+	// replaced a busy requested local port.
+	OnLocalPortSelected LocalPortSelectedCallback
+
+	// PortForwardDNS controls where forwarded destination hostnames are
+	// resolved, mirroring OpenSSH's local/remote resolution semantics: on
+	// the node (the default, required for names that only resolve inside
+	// the node's network) or on the client before dialing. Applies to both
+	// -L destinations and -D SOCKS requests carrying a hostname.
+	PortForwardDNS DNSResolutionMode
+
+	// PersistentConnection makes ConnectToProxy hand out a single shared
+	// proxy/auth connection instead of dialing a fresh one per call. The
+	// shared connection is health checked and transparently rebuilt when it
+	// dies, and must be released with ClosePersistentConnection.
+	PersistentConnection bool
+
+	// MaintenancePolicy controls what happens when an SSH or file transfer
+	// target is marked as under maintenance via the MaintenanceLabel:
+	// warn on stderr (the default) or refuse the connection.
+	MaintenancePolicy MaintenancePolicy
+
+	// BypassMaintenance skips the maintenance marker check entirely,
+	// overriding MaintenancePolicy.
+	BypassMaintenance bool
+
+	// MessageWriter, if set, receives the user-facing messages the client
+	// would otherwise print to Stdout/Stderr, keeping those streams clean
+	// for embedding applications and machine-readable output modes.
+	MessageWriter MessageWriter
+
+	// RequireTrustedDevice refuses SSH, Kubernetes and database operations
+	// unless the current credentials carry trusted device extensions, even
+	// when the cluster itself permits untrusted devices. Credentials without
+	// them trigger one up-front device authentication attempt before the
+	// operation is refused.
+	RequireTrustedDevice bool
+
 	// HostKeyCallback will be called to check host keys of the remote
 	// node, if not specified will be using CheckHostSignature function
 	// that uses local cache to validate hosts
 	HostKeyCallback ssh.HostKeyCallback
 
+	// HostKeyCallbackChain, when set, composes multiple host key callbacks
+	// into the callback used for remote hosts, accepting a key as soon as
+	// any callback in the chain accepts it and logging every decision.
+	// Unless HostKeyCallback is also set, the default local cache check is
+	// appended to the chain, so custom validators extend rather than
+	// replace the default checks.
+	HostKeyCallbackChain *HostKeyCallbackChain
+
 	// KeyDir defines where temporary session keys will be stored.
 	// if empty, they'll go to ~/.tsh
 	KeysDir string
 
+	// ReissueCoordinationDir, when set, enables cross-process coordination
+	// of user certificate reissues: before renewing, the client waits a
+	// short jittered delay and takes a file lock under this directory, and
+	// a plain renewal is skipped entirely if another process refreshed the
+	// certificates in the shared store while it waited. This prevents a
+	// fleet of clients sharing one identity from reissuing simultaneously.
+	ReissueCoordinationDir string
+
 	// SessionID is a session ID to use when opening a new session.
 	SessionID string
 
@@ -314,6 +453,15 @@ type Config struct {
 	// in interactive mode, i.e. attaching the temrinal to it
 	Interactive bool
 
+	// PTYAllocation overrides when a server-side PTY is requested for remote
+	// commands. See PTYAllocationMode.
+	PTYAllocation PTYAllocationMode
+
+	// TerminalModes are the terminal modes sent with PTY requests (e.g.
+	// disabling ECHO for password pipelines). Empty requests the server
+	// defaults.
+	TerminalModes ssh.TerminalModes
+
 	// ClientAddr (if set) specifies the true client IP. Usually it's not needed (since the server
 	// can look at the connecting address to determine client's IP) but for cases when the
 	// client is web-based, this must be set to HTTP's remote addr
@@ -323,12 +471,40 @@ type Config struct {
 	// by default does not use caching
 	CachePolicy *CachePolicy
 
+	// TimeoutPolicy defines client-wide timeouts (dial, RPC, whole operation)
+	// applied to TeleportClient methods; by default no timeouts are imposed
+	// beyond the caller's context.
+	TimeoutPolicy *TimeoutPolicy
+
+	// ConnectionRetry, if set, makes ConnectToProxy, Ping and resource
+	// listing calls retry transient connection errors with backoff and
+	// jitter instead of failing on the first attempt.
+	ConnectionRetry *ConnectionRetryPolicy
+
+	// PingCacheTTL bounds how long a successful Ping response is cached by
+	// the client. Zero means the response is cached for the client lifetime.
+	PingCacheTTL time.Duration
+
+	// Metrics, if set, collects Prometheus metrics about the client's port
+	// forwarding and file transfer activity. See NewClientMetrics.
+	Metrics *ClientMetrics
+
 	// CertificateFormat is the format of the SSH certificate.
 	CertificateFormat string
 
 	// AuthConnector is the name of the authentication connector to use.
 	AuthConnector string
 
+	// IdentityFile is a path to an identity file to use instead of the
+	// client store. lib/client does not load it itself; embedding binaries
+	// are expected to read it the way tsh handles its --identity flag.
+	IdentityFile string
+
+	// WebauthnTransports restricts WebAuthn logins to authenticators
+	// reachable via the given transports (USB, NFC, BLE, platform). An empty
+	// slice allows all transports.
+	WebauthnTransports []wancli.AuthenticatorTransport
+
 	// AuthenticatorAttachment is the desired authenticator attachment.
 	AuthenticatorAttachment wancli.AuthenticatorAttachment
 
@@ -342,12 +518,24 @@ type Config struct {
 	CheckVersions bool
 
 	// BindAddr is an optional host:port to bind to for SSO redirect flows.
+	// The port may be an inclusive range, e.g. "127.0.0.1:8080-8090".
 	BindAddr string
 
+	// SSOCallbackServer is an optional pre-bound local server to receive SSO
+	// callbacks on. When set it takes precedence over BindAddr and is reused
+	// across logins instead of binding a fresh port per login.
+	SSOCallbackServer *CallbackServer
+
 	// NoRemoteExec will not execute a remote command after connecting to a host,
 	// will block instead. Useful when port forwarding. Equivalent of -N for OpenSSH.
 	NoRemoteExec bool
 
+	// BatchMFA allows multi-node command execution to acquire the
+	// single-use MFA-verified certificates for all target nodes in an
+	// up-front batch phase, so the commands themselves can run in parallel.
+	// Without it, MFA-protected nodes are executed sequentially.
+	BatchMFA bool
+
 	// Browser can be used to pass the name of a browser to override the system default
 	// (not currently implemented), or set to 'none' to suppress browser opening entirely.
 	Browser string
@@ -364,9 +552,84 @@ type Config struct {
 	// terminal.
 	EnableEscapeSequences bool
 
+	// EscapePassThrough starts sessions with escape sequence pass-through
+	// enabled: the disconnect sequence "~." is forwarded literally to the
+	// remote end instead of being intercepted, for TUI apps that need the
+	// input. Pass-through can be toggled at runtime with the "~p" escape
+	// sequence. Only effective when EnableEscapeSequences is set.
+	EscapePassThrough bool
+
+	// RemoteShell, if set, is executed in place of the user's default shell
+	// when starting an interactive session. It may name a shell binary or a
+	// wrapper command (e.g. "tmux new -A -s teleport"). It is sent as an
+	// SSH exec request with a TTY, so the command is recorded in the audit
+	// trail. Ignored when joining an existing session.
+	RemoteShell string
+
+	// LocalRecordingDir, when set, makes interactive sessions write a
+	// script(1)-compatible typescript and timing file pair into this
+	// directory. The capture happens entirely on the client and is
+	// independent of any server-side session recording.
+	LocalRecordingDir string
+
+	// RecordingEncryptionKey is a base64-encoded curve25519 public key used
+	// to encrypt recordings written to LocalRecordingDir before they leave
+	// the endpoint. It is discovered via Ping when the cluster publishes
+	// one, and can also be set explicitly.
+	RecordingEncryptionKey string
+
 	// MockSSOLogin is used in tests for mocking the SSO login response.
 	MockSSOLogin SSOLoginFunc
 
+	// RememberMOTDAck makes ShowMOTD skip the acknowledgment prompt when the
+	// message matches the last one acknowledged, and records new
+	// acknowledgments in the profile so they persist across logins.
+	RememberMOTDAck bool
+
+	// AcknowledgedMOTD is the hash of the last message of the day the user
+	// acknowledged. It is loaded from and saved to the profile.
+	AcknowledgedMOTD string
+
+	// KubeContextTemplate is an optional Go template used to name generated
+	// kubeconfig contexts instead of the default
+	// "<teleport-cluster>-<kube-cluster>" scheme. Persisted in the profile.
+	KubeContextTemplate string
+
+	// KubeNamespaces maps kubernetes cluster names to the default namespace
+	// to set in generated kubeconfig contexts. Persisted in the profile.
+	KubeNamespaces map[string]string
+
+	// LoginProgress, if set, is invoked as the login ceremony moves between
+	// stages (pinging the proxy, waiting for an SSO redirect or hardware key
+	// touch, issuing certificates, saving the profile). Returning an error
+	// cancels the login. See LoginStage for the stages reported.
+	LoginProgress LoginProgressFunc
+
+	// OnCertExtensionsChanged, if set, is invoked after user certificates
+	// are reissued with the extensions parsed from the new certificates, so
+	// UIs can refresh their view of roles, traits and allowed logins without
+	// re-implementing certificate parsing.
+	OnCertExtensionsChanged CertExtensionsChangedFunc
+
+	// CustomRelogin, if set, replaces the interactive login performed by
+	// RetryWithRelogin, letting embedding applications supply their own
+	// re-authentication routine (e.g. fetching a fresh identity from a
+	// secret store or reusing an existing browser SSO session).
+	CustomRelogin ReloginFunc
+
+	// ReloginPolicy, if set, is consulted before RetryWithRelogin attempts
+	// a relogin; returning false leaves the original error untouched.
+	ReloginPolicy ReloginPolicy
+
+	// FaultInjector, if set, is consulted at well-defined points in the
+	// client so tests can deterministically inject faults. Never set in
+	// production.
+	FaultInjector FaultInjector
+
+	// PlaybackWatermark optionally renders a watermark identifying the viewer
+	// into the terminal during session playback.
+	PlaybackWatermark *PlaybackWatermark
+
 	// HomePath is where tsh stores profiles
 	HomePath string
 
@@ -498,6 +761,14 @@ func VirtualPathEnvNames(kind VirtualPathKind, params VirtualPathParams) []strin
 	return vars
 }
 
+// ReloginFunc is a custom re-authentication routine invoked by
+// RetryWithRelogin in place of the interactive login.
+type ReloginFunc func(ctx context.Context, tc *TeleportClient) error
+
+// ReloginPolicy decides whether RetryWithRelogin should attempt a relogin to
+// resolve the given error.
+type ReloginPolicy func(err error) bool
+
 // RetryWithRelogin is a helper error handling method, attempts to relogin and
 // retry the function once.
 func RetryWithRelogin(ctx context.Context, tc *TeleportClient, fn func() error) error {
@@ -514,6 +785,21 @@ func RetryWithRelogin(ctx context.Context, tc *TeleportClient, fn func() error)
 		return trace.Wrap(err)
 	}
 
+	if tc.ReloginPolicy != nil && !tc.ReloginPolicy(err) {
+		log.Debugf("Relogin on %v disabled by policy.", err)
+		return trace.Wrap(err)
+	}
+
+	// Embedding applications may supply their own re-authentication routine,
+	// which also covers identity file / external identity setups.
+	if tc.CustomRelogin != nil {
+		log.Debugf("Activating custom relogin on %v.", err)
+		if loginErr := tc.CustomRelogin(ctx, tc); loginErr != nil {
+			return trace.Wrap(loginErr)
+		}
+		return fn()
+	}
+
 	// Don't try to login when using an identity file / external identity.
 	if tc.SkipLocalAuth {
 		return trace.Wrap(err)
@@ -599,6 +885,9 @@ func (c *Config) LoadProfile(ps ProfileStore, proxyAddr string) error {
 	c.AuthConnector = profile.AuthConnector
 	c.LoadAllCAs = profile.LoadAllCAs
 	c.PrivateKeyPolicy = profile.PrivateKeyPolicy
+	c.AcknowledgedMOTD = profile.AcknowledgedMOTD
+	c.KubeContextTemplate = profile.KubeContextTemplate
+	c.KubeNamespaces = profile.KubeNamespaces
 	c.AuthenticatorAttachment, err = parseMFAMode(profile.MFAMode)
 	if err != nil {
 		return trace.BadParameter("unable to parse mfa mode in user profile: %v.", err)
@@ -625,20 +914,23 @@ func (c *Config) SaveProfile(makeCurrent bool) error {
 	}
 
 	p := &profile.Profile{
-		Username:          c.Username,
-		WebProxyAddr:      c.WebProxyAddr,
-		SSHProxyAddr:      c.SSHProxyAddr,
-		KubeProxyAddr:     c.KubeProxyAddr,
-		PostgresProxyAddr: c.PostgresProxyAddr,
-		MySQLProxyAddr:    c.MySQLProxyAddr,
-		MongoProxyAddr:    c.MongoProxyAddr,
-		ForwardedPorts:    c.LocalForwardPorts.String(),
-		SiteName:          c.SiteName,
-		TLSRoutingEnabled: c.TLSRoutingEnabled,
-		AuthConnector:     c.AuthConnector,
-		MFAMode:           c.AuthenticatorAttachment.String(),
-		LoadAllCAs:        c.LoadAllCAs,
-		PrivateKeyPolicy:  c.PrivateKeyPolicy,
+		Username:            c.Username,
+		WebProxyAddr:        c.WebProxyAddr,
+		SSHProxyAddr:        c.SSHProxyAddr,
+		KubeProxyAddr:       c.KubeProxyAddr,
+		PostgresProxyAddr:   c.PostgresProxyAddr,
+		MySQLProxyAddr:      c.MySQLProxyAddr,
+		MongoProxyAddr:      c.MongoProxyAddr,
+		ForwardedPorts:      c.LocalForwardPorts.String(),
+		SiteName:            c.SiteName,
+		TLSRoutingEnabled:   c.TLSRoutingEnabled,
+		AuthConnector:       c.AuthConnector,
+		MFAMode:             c.AuthenticatorAttachment.String(),
+		LoadAllCAs:          c.LoadAllCAs,
+		PrivateKeyPolicy:    c.PrivateKeyPolicy,
+		AcknowledgedMOTD:    c.AcknowledgedMOTD,
+		KubeContextTemplate: c.KubeContextTemplate,
+		KubeNamespaces:      c.KubeNamespaces,
 	}
 
 	if err := c.ClientStore.SaveProfile(p, makeCurrent); err != nil {
@@ -670,6 +962,12 @@ type ParsedProxyHost struct {
 // the supplied string), ParseProxyHost() will supply default versions and flag
 // that a default value is being used in the returned `ParsedProxyHost`
 func ParseProxyHost(proxyHost string) (*ParsedProxyHost, error) {
+	// Accept proxy addresses pasted straight from the browser, e.g.
+	// "https://teleport.example.com:443/web/cluster".
+	if strings.Contains(proxyHost, "://") {
+		return parseProxyURL(proxyHost)
+	}
+
 	host, port, err := net.SplitHostPort(proxyHost)
 	if err != nil {
 		host = proxyHost
@@ -721,6 +1019,45 @@ func ParseProxyHost(proxyHost string) (*ParsedProxyHost, error) {
 	return result, nil
 }
 
+// parseProxyURL parses a proxy address given as a URL. Only https URLs are
+// accepted; any path, query or fragment is stripped. When the URL doesn't
+// specify a port, the web proxy port is detected the same way as for a bare
+// hostname.
+func parseProxyURL(proxyURL string) (*ParsedProxyHost, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, trace.BadParameter("failed to parse proxy address %q: %v", proxyURL, err)
+	}
+
+	switch u.Scheme {
+	case "https":
+	case "http":
+		return nil, trace.BadParameter("proxy address %q uses plain http: specify an https:// URL or a host:port address", proxyURL)
+	default:
+		return nil, trace.BadParameter("unsupported scheme %q in proxy address %q: specify an https:// URL or a host:port address", u.Scheme, proxyURL)
+	}
+	if u.Hostname() == "" {
+		return nil, trace.BadParameter("missing host in proxy address %q", proxyURL)
+	}
+
+	host := u.Hostname()
+	webPort := u.Port()
+	usingDefaultWebProxyPort := false
+	if webPort == "" {
+		// https URLs imply the standard HTTPS port rather than the
+		// teleport-specific default web port.
+		webPort = strconv.Itoa(apidefaults.StandardHTTPSPort)
+		usingDefaultWebProxyPort = true
+	}
+
+	return &ParsedProxyHost{
+		Host:                     host,
+		UsingDefaultWebProxyPort: usingDefaultWebProxyPort,
+		WebProxyAddr:             net.JoinHostPort(host, webPort),
+		SSHProxyAddr:             net.JoinHostPort(host, strconv.Itoa(defaults.SSHProxyListenPort)),
+	}, nil
+}
+
 // ParseProxyHost parses the proxyHost string and updates the config.
 //
 // Format of proxyHost string:
@@ -912,9 +1249,25 @@ type TeleportClient struct {
 	// occurred during the session.
 	eventsCh chan events.EventFields
 
+	// dataUsage accumulates per-session and per-target byte counts. See
+	// DataUsage.
+	dataUsage *dataUsageAccounting
+
+	// persistentProxyMu guards persistentProxy and persistentProxyDone.
+	persistentProxyMu sync.Mutex
+	// persistentProxy is the shared proxy connection handed out by
+	// ConnectToProxy when PersistentConnection is set.
+	persistentProxy *ProxyClient
+	// persistentProxyDone is closed when the shared proxy connection dies,
+	// signaling that it must be rebuilt.
+	persistentProxyDone chan struct{}
+
 	// Note: there's no mutex guarding this or localAgent, making
 	// TeleportClient NOT safe for concurrent use.
 	lastPing *webclient.PingResponse
+	// lastPingTime records when lastPing was obtained, for PingCacheTTL
+	// expiry.
+	lastPingTime time.Time
 
 	// dtAttemptLoginIgnorePing allows tests to override AttemptDeviceLogin's Ping
 	// response validation.
@@ -924,6 +1277,10 @@ type TeleportClient struct {
 	// authentication function.
 	// Defaults to [dtauthn.RunCeremony].
 	dtAuthnRunCeremony dtAuthnRunCeremonyFunc
+
+	// mfaCeremonies serializes MFA ceremonies across goroutines so parallel
+	// connects don't race hardware prompts. Safe to use at its zero value.
+	mfaCeremonies mfaCeremonyCoordinator
 }
 
 // ShellCreatedCallback can be supplied for every teleport client. It will
@@ -955,6 +1312,8 @@ func NewClient(c *Config) (tc *TeleportClient, err error) {
 			return nil, trace.Wrap(err)
 		}
 		log.Infof("no host login given. defaulting to %s", c.HostLogin)
+	} else {
+		c.ExplicitHostLogin = true
 	}
 	if c.KeyTTL == 0 {
 		c.KeyTTL = apidefaults.CertDuration
@@ -997,6 +1356,7 @@ func NewClient(c *Config) (tc *TeleportClient, err error) {
 	// into it. Delays in pulling messages off the global SSH request channel
 	// could lead to the connection hanging.
 	tc.eventsCh = make(chan events.EventFields, 1024)
+	tc.dataUsage = newDataUsageAccounting()
 
 	localAgentCfg := LocalAgentConfig{
 		ClientStore: tc.ClientStore,
@@ -1015,7 +1375,16 @@ func NewClient(c *Config) (tc *TeleportClient, err error) {
 		return nil, trace.Wrap(err)
 	}
 
-	if tc.HostKeyCallback == nil {
+	if tc.HostKeyCallbackChain != nil {
+		// Keep the default checks by folding the standalone callback (or the
+		// local cache check) into the chain.
+		if tc.HostKeyCallback != nil {
+			tc.HostKeyCallbackChain.Add("config", tc.HostKeyCallback)
+		} else {
+			tc.HostKeyCallbackChain.Add("known-hosts", tc.localAgent.HostKeyCallback)
+		}
+		tc.HostKeyCallback = tc.HostKeyCallbackChain.Callback
+	} else if tc.HostKeyCallback == nil {
 		tc.HostKeyCallback = tc.localAgent.HostKeyCallback
 	}
 
@@ -1155,9 +1524,31 @@ func (tc *TeleportClient) getTargetNodes(ctx context.Context, proxy *ProxyClient
 	}
 
 	// find the nodes matching the labels that were provided
-	nodes, err := proxy.FindNodesByFilters(ctx, *tc.DefaultResourceFilter())
-	if err != nil {
-		return nil, trace.Wrap(err)
+	var nodes []types.Server
+	var err error
+	if tc.MaxNodeMatches > 0 || tc.FailOnAmbiguousHost {
+		// Resolve with a server-side limit so huge clusters don't force the
+		// client to page through the entire node inventory. One extra result
+		// is requested to detect that the cap was exceeded.
+		maxMatches := tc.MaxNodeMatches
+		if tc.FailOnAmbiguousHost && (maxMatches == 0 || maxMatches > 1) {
+			maxMatches = 1
+		}
+		nodes, err = proxy.FindNodesByFiltersLimited(ctx, *tc.DefaultResourceFilter(), maxMatches+1)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if len(nodes) > maxMatches {
+			if maxMatches == 1 {
+				return nil, trace.BadParameter("multiple nodes match the specified filters, aborting due to ambiguous target")
+			}
+			return nil, trace.BadParameter("more than %d nodes match the specified filters, narrow the search or raise the limit", maxMatches)
+		}
+	} else {
+		nodes, err = proxy.FindNodesByFilters(ctx, *tc.DefaultResourceFilter())
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
 	}
 
 	retval := make([]string, 0, len(nodes))
@@ -1179,6 +1570,26 @@ func (tc *TeleportClient) ReissueUserCerts(ctx context.Context, cachePolicy Cert
 	)
 	defer span.End()
 
+	if err := tc.checkTrustedDevice(ctx); err != nil {
+		return trace.Wrap(err)
+	}
+
+	if tc.ReissueCoordinationDir != "" {
+		unlock, reused, err := tc.coordinateReissue(ctx, params)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer func() {
+			if err := unlock(); err != nil {
+				log.WithError(err).Warn("Failed to release reissue lock.")
+			}
+		}()
+		if reused {
+			tc.notifyCertExtensionsChanged()
+			return nil
+		}
+	}
+
 	proxyClient, err := tc.ConnectToProxy(ctx)
 	if err != nil {
 		return trace.Wrap(err)
@@ -1189,7 +1600,106 @@ func (tc *TeleportClient) ReissueUserCerts(ctx context.Context, cachePolicy Cert
 		err := proxyClient.ReissueUserCerts(ctx, cachePolicy, params)
 		return trace.Wrap(err)
 	})
-	return trace.Wrap(err)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	tc.notifyCertExtensionsChanged()
+	return nil
+}
+
+// notifyCertExtensionsChanged invokes the OnCertExtensionsChanged callback
+// with the extensions parsed from the current key, if the callback is set.
+func (tc *TeleportClient) notifyCertExtensionsChanged() {
+	if tc.OnCertExtensionsChanged == nil || tc.localAgent == nil {
+		return
+	}
+	key, err := tc.localAgent.GetCoreKey()
+	if err != nil {
+		log.WithError(err).Debug("Unable to load key for cert extensions notification.")
+		return
+	}
+	ext, err := key.CertExtensions()
+	if err != nil {
+		log.WithError(err).Debug("Unable to parse cert extensions.")
+		return
+	}
+	tc.OnCertExtensionsChanged(ext)
+}
+
+// SwitchCluster switches the client to another cluster reachable through the
+// same proxy, typically a leaf cluster. It verifies the cluster is connected
+// to the proxy, reissues user certificates routed to it while preserving any
+// active access requests, refreshes the trusted CAs and saves the updated
+// cluster selection in the profile, giving all consumers the `tsh login
+// <leaf>` semantics.
+func (tc *TeleportClient) SwitchCluster(ctx context.Context, clusterName string) error {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/SwitchCluster",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(attribute.String("cluster", clusterName)),
+	)
+	defer span.End()
+
+	if clusterName == "" {
+		return trace.BadParameter("missing cluster name")
+	}
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	// Verify the target cluster is connected to the proxy before touching
+	// any local state.
+	sites, err := proxyClient.GetSites(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	names := make([]string, 0, len(sites))
+	for _, site := range sites {
+		names = append(names, site.Name)
+	}
+	if !slices.Contains(names, clusterName) {
+		return trace.NotFound("cluster %q is not connected to proxy %v, connected clusters: %v",
+			clusterName, tc.WebProxyAddr, strings.Join(names, ", "))
+	}
+
+	// Preserve access requests active on the current certificates across
+	// the reissue.
+	params := ReissueParams{RouteToCluster: clusterName}
+	if tc.localAgent != nil {
+		if key, err := tc.localAgent.GetCoreKey(); err == nil {
+			if requests, err := key.ActiveRequests(); err == nil {
+				params.AccessRequests = requests.AccessRequests
+			}
+		}
+	}
+
+	err = RetryWithRelogin(ctx, tc, func() error {
+		return trace.Wrap(proxyClient.ReissueUserCerts(ctx, CertCacheKeep, params))
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	tc.SiteName = clusterName
+
+	// Refresh the trusted CAs so host and user CA changes in the target
+	// cluster's root are picked up.
+	if tc.localAgent != nil {
+		rootClusterName, err := tc.RootClusterName(ctx)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if err := tc.UpdateTrustedCA(ctx, rootClusterName); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	return trace.Wrap(tc.SaveProfile(true))
 }
 
 // IssueUserCertsWithMFA issues a single-use SSH or TLS certificate for
@@ -1209,6 +1719,10 @@ func (tc *TeleportClient) IssueUserCertsWithMFA(ctx context.Context, params Reis
 	)
 	defer span.End()
 
+	if err := tc.checkTrustedDevice(ctx); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	proxyClient, err := tc.ConnectToProxy(ctx)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -1387,6 +1901,11 @@ func (tc *TeleportClient) SSH(ctx context.Context, command []string, runLocally
 	if !tc.Config.ProxySpecified() {
 		return trace.BadParameter("proxy server is not specified")
 	}
+
+	if err := tc.checkTrustedDevice(ctx); err != nil {
+		return trace.Wrap(err)
+	}
+
 	proxyClient, err := tc.ConnectToProxy(ctx)
 	if err != nil {
 		return trace.Wrap(err)
@@ -1402,6 +1921,10 @@ func (tc *TeleportClient) SSH(ctx context.Context, command []string, runLocally
 		return trace.BadParameter("no target host specified")
 	}
 
+	if err := tc.checkNodesMaintenance(ctx, proxyClient, nodeAddrs); err != nil {
+		return trace.Wrap(err)
+	}
+
 	if len(nodeAddrs) > 1 {
 		return tc.runShellOrCommandOnMultipleNodes(ctx, nodeAddrs, proxyClient, command)
 	}
@@ -1414,6 +1937,11 @@ func (tc *TeleportClient) SSH(ctx context.Context, command []string, runLocally
 // ceremony is performed and another connection is attempted with the freshly minted
 // certificates. If it is not required, then the original Access Denied error from the node
 // is returned.
+//
+// Each connection attempt is individually bounded by the dial timeout of the
+// client's TimeoutPolicy, and when the MFA requirement is not known up front
+// the IsMFARequired query runs concurrently with the initial dial so the MFA
+// fallback does not pay for an extra sequential round trip.
 func (tc *TeleportClient) ConnectToNode(ctx context.Context, proxyClient *ProxyClient, nodeDetails NodeDetails, user string) (*NodeClient, error) {
 	node := nodeName(nodeDetails.Addr)
 	ctx, span := tc.Tracer.Start(
@@ -1430,9 +1958,11 @@ func (tc *TeleportClient) ConnectToNode(ctx context.Context, proxyClient *ProxyC
 	// attempt to use the existing credentials first
 	authMethods := proxyClient.authMethods
 
-	// if per-session mfa is required, perform the mfa ceremony to get
-	// new certificates and use them instead
-	if nodeDetails.MFACheck != nil && nodeDetails.MFACheck.Required {
+	// credentials acquired up front (e.g. batched single-use MFA
+	// certificates) take precedence over a per-node mfa ceremony
+	if len(nodeDetails.AuthMethods) > 0 {
+		authMethods = nodeDetails.AuthMethods
+	} else if nodeDetails.MFACheck != nil && nodeDetails.MFACheck.Required {
 		am, err := proxyClient.sessionSSHCertificate(ctx, nodeDetails)
 		if err != nil {
 			return nil, trace.Wrap(err)
@@ -1447,10 +1977,54 @@ func (tc *TeleportClient) ConnectToNode(ctx context.Context, proxyClient *ProxyC
 		return nil, trace.Wrap(err)
 	}
 
+	// dialNode performs a single connection attempt bounded by the dial
+	// timeout of the client's timeout policy, independent of the overall
+	// caller context.
+	dialNode := func(authMethods []ssh.AuthMethod) (*NodeClient, error) {
+		dialCtx, cancelTimeout, convertTimeout := tc.withTimeoutPolicy(ctx, dialTimeout, "ConnectToNode")
+		defer cancelTimeout()
+		nodeClient, err := proxyClient.ConnectToNode(dialCtx, nodeDetails, user, details, authMethods)
+		return nodeClient, convertTimeout(err)
+	}
+
+	// when it is not yet known whether per-session mfa is required, start the
+	// check concurrently with the initial dial so a denied connection can
+	// fall back to the mfa ceremony without an extra sequential round trip
+	type mfaCheckResult struct {
+		clt   auth.ClientI
+		check *proto.IsMFARequiredResponse
+		err   error
+	}
+	var checkCh chan mfaCheckResult
+	if nodeDetails.MFACheck == nil {
+		checkCtx, checkCancel := context.WithCancel(ctx)
+		// stop the check once the dial outcome has settled
+		defer checkCancel()
+
+		checkCh = make(chan mfaCheckResult, 1)
+		go func() {
+			clt, err := proxyClient.ConnectToCluster(checkCtx, nodeDetails.Cluster)
+			if err != nil {
+				checkCh <- mfaCheckResult{err: err}
+				return
+			}
+			check, err := clt.IsMFARequired(checkCtx, &proto.IsMFARequiredRequest{
+				Target: &proto.IsMFARequiredRequest_Node{
+					Node: &proto.NodeLogin{
+						Node:  node,
+						Login: proxyClient.hostLogin,
+					},
+				},
+			})
+			checkCh <- mfaCheckResult{clt: clt, check: check, err: err}
+		}()
+	}
+
 	// try connecting to the node
-	nodeClient, connectErr := proxyClient.ConnectToNode(ctx, nodeDetails, user, details, authMethods)
+	nodeClient, connectErr := dialNode(authMethods)
 	switch {
 	case connectErr == nil: // no error return client
+		tc.setupPortForwardReconnect(ctx, proxyClient, nodeDetails, user, nodeClient)
 		return nodeClient, nil
 	case nodeDetails.MFACheck != nil: // per-session mfa ceremony was already performed, return the results
 		return nodeClient, trace.Wrap(connectErr)
@@ -1458,24 +2032,14 @@ func (tc *TeleportClient) ConnectToNode(ctx context.Context, proxyClient *ProxyC
 		return nil, trace.Wrap(connectErr)
 	}
 
-	// access was denied, determine if it was because per-session mfa is required
-	clt, err := proxyClient.ConnectToCluster(ctx, nodeDetails.Cluster)
-	if err != nil {
-		// return the connection error instead of any errors from connecting to auth
-		return nil, trace.Wrap(connectErr)
-	}
-
-	check, err := clt.IsMFARequired(ctx, &proto.IsMFARequiredRequest{
-		Target: &proto.IsMFARequiredRequest_Node{
-			Node: &proto.NodeLogin{
-				Node:  node,
-				Login: proxyClient.hostLogin,
-			},
-		},
-	})
-	if err != nil {
+	// access was denied, determine if it was because per-session mfa is
+	// required using the result of the concurrent check
+	result := <-checkCh
+	if result.err != nil {
+		// return the connection error instead of any errors from the check
 		return nil, trace.Wrap(connectErr)
 	}
+	clt, check := result.clt, result.check
 
 	// per-session mfa isn't required, the user simply does not
 	// have access to the provided node
@@ -1506,7 +2070,10 @@ func (tc *TeleportClient) ConnectToNode(ctx context.Context, proxyClient *ProxyC
 		return nil, trace.Wrap(err)
 	}
 
-	nodeClient, err = proxyClient.ConnectToNode(ctx, nodeDetails, user, details, []ssh.AuthMethod{newAuthMethods})
+	nodeClient, err = dialNode([]ssh.AuthMethod{newAuthMethods})
+	if err == nil {
+		tc.setupPortForwardReconnect(ctx, proxyClient, nodeDetails, user, nodeClient)
+	}
 	return nodeClient, trace.Wrap(err)
 }
 
@@ -1555,9 +2122,9 @@ func (tc *TeleportClient) runShellOrCommandOnSingleNode(ctx context.Context, nod
 	// then disconnect.
 	if runLocally {
 		if len(tc.Config.LocalForwardPorts) == 0 {
-			fmt.Println("Executing command locally without connecting to any servers. This makes no sense.")
+			tc.Infof("Executing command locally without connecting to any servers. This makes no sense.")
 		}
-		return runLocalCommand(command)
+		return runLocalCommand(ctx, command, forwardedPortsEnvironment(tc.Config.LocalForwardPorts))
 	}
 
 	if len(command) > 0 {
@@ -1581,12 +2148,12 @@ func (tc *TeleportClient) runShellOrCommandOnMultipleNodes(ctx context.Context,
 
 	// There was a command provided, run a non-interactive session against each match
 	if len(command) > 0 {
-		fmt.Printf("\x1b[1mWARNING\x1b[0m: Multiple nodes matched label selector, running command on all.\n")
+		tc.Warningf("Multiple nodes matched label selector, running command on all.")
 		return tc.runCommandOnNodes(ctx, tc.SiteName, nodeAddrs, proxyClient, command)
 	}
 
 	// Issue "shell" request to the first matching node.
-	fmt.Printf("\x1b[1mWARNING\x1b[0m: Multiple nodes match the label selector, picking first: %q\n", nodeAddrs[0])
+	tc.Warningf("Multiple nodes match the label selector, picking first: %q", nodeAddrs[0])
 	nodeClient, err := tc.ConnectToNode(
 		ctx,
 		proxyClient,
@@ -1603,20 +2170,22 @@ func (tc *TeleportClient) runShellOrCommandOnMultipleNodes(ctx context.Context,
 
 func (tc *TeleportClient) startPortForwarding(ctx context.Context, nodeClient *NodeClient) error {
 	for _, fp := range tc.Config.LocalForwardPorts {
-		addr := net.JoinHostPort(fp.SrcIP, strconv.Itoa(fp.SrcPort))
-		socket, err := net.Listen("tcp", addr)
+		socket, err := tc.listenLocalPort(fp.SrcIP, fp.SrcPort)
 		if err != nil {
-			return trace.Errorf("Failed to bind to %v: %v.", addr, err)
+			return trace.Wrap(err)
+		}
+		remoteNetwork, remoteAddr := "tcp", net.JoinHostPort(fp.DestHost, strconv.Itoa(fp.DestPort))
+		if fp.DestSocket != "" {
+			remoteNetwork, remoteAddr = "unix", fp.DestSocket
 		}
-		go nodeClient.listenAndForward(ctx, socket, addr, net.JoinHostPort(fp.DestHost, strconv.Itoa(fp.DestPort)))
+		go nodeClient.listenAndForward(ctx, socket, socket.Addr().String(), remoteNetwork, remoteAddr)
 	}
 	for _, fp := range tc.Config.DynamicForwardedPorts {
-		addr := net.JoinHostPort(fp.SrcIP, strconv.Itoa(fp.SrcPort))
-		socket, err := net.Listen("tcp", addr)
+		socket, err := tc.listenLocalPort(fp.SrcIP, fp.SrcPort)
 		if err != nil {
-			return trace.Errorf("Failed to bind to %v: %v.", addr, err)
+			return trace.Wrap(err)
 		}
-		go nodeClient.dynamicListenAndForward(ctx, socket, addr)
+		go nodeClient.dynamicListenAndForward(ctx, socket, socket.Addr().String())
 	}
 	return nil
 }
@@ -1756,18 +2325,12 @@ func (tc *TeleportClient) Play(ctx context.Context, namespace, sessionID string)
 	}
 
 	// read the stream into a buffer:
-	for {
-		tmp, err := site.GetSessionChunk(namespace, *sid, len(stream), events.MaxChunkBytes)
-		if err != nil {
-			return trace.Wrap(err)
-		}
-		if len(tmp) == 0 {
-			break
-		}
-		stream = append(stream, tmp...)
+	stream, err = io.ReadAll(newSessionRecordingReader(site, namespace, *sid, 0, nil))
+	if err != nil {
+		return trace.Wrap(err)
 	}
 
-	return playSession(sessionEvents, stream)
+	return playSession(sessionEvents, stream, tc.PlaybackWatermark)
 }
 
 func (tc *TeleportClient) GetSessionEvents(ctx context.Context, namespace, sessionID string) ([]events.EventFields, error) {
@@ -1827,7 +2390,7 @@ func PlayFile(ctx context.Context, tarFile io.Reader, sid string) error {
 		return trace.Wrap(err)
 	}
 
-	return playSession(sessionEvents, stream)
+	return playSession(sessionEvents, stream, nil)
 }
 
 // ExecuteSCP executes SCP command. It executes scp.Command using
@@ -1990,7 +2553,9 @@ func getSCPDestination(target string, port int) (dest *scp.Destination, addr str
 }
 
 // TransferFiles copies files between the current machine and the
-// specified Node using the supplied config
+// specified Node using the supplied config. Common remote failures are
+// surfaced as typed errors (sftp.ErrPermissionDenied, sftp.ErrNoSuchFile,
+// sftp.ErrNoSpace) matchable with errors.Is.
 func (tc *TeleportClient) TransferFiles(ctx context.Context, hostLogin, nodeAddr string, cfg *sftp.Config) error {
 	ctx, span := tc.Tracer.Start(
 		ctx,
@@ -2015,6 +2580,10 @@ func (tc *TeleportClient) TransferFiles(ctx context.Context, hostLogin, nodeAddr
 	}
 	defer proxyClient.Close()
 
+	if err := tc.checkNodesMaintenance(ctx, proxyClient, []string{nodeAddr}); err != nil {
+		return trace.Wrap(err)
+	}
+
 	client, err := tc.ConnectToNode(
 		ctx,
 		proxyClient,
@@ -2041,16 +2610,30 @@ func (tc *TeleportClient) ListNodesWithFilters(ctx context.Context) ([]types.Ser
 	)
 	defer span.End()
 
+	ctx, cancelTimeout, convertTimeout := tc.withTimeoutPolicy(ctx, operationTimeout, "ListNodesWithFilters")
+	defer cancelTimeout()
+
 	// connect to the proxy and ask it to return a full list of servers
 	proxyClient, err := tc.ConnectToProxy(ctx)
 	if err != nil {
-		return nil, trace.Wrap(err)
+		// Only the default listing is cached, so filtered listings cannot be
+		// served from the cache.
+		if !tc.hasNodeFilters() {
+			if nodes, cacheErr := tc.cachedNodes(); cacheErr == nil {
+				log.WithError(err).Debug("Proxy is unreachable, serving nodes from the local resource cache.")
+				return nodes, nil
+			}
+		}
+		return nil, trace.Wrap(convertTimeout(err))
 	}
 	defer proxyClient.Close()
 
 	servers, err := proxyClient.FindNodesByFilters(ctx, *tc.DefaultResourceFilter())
 	if err != nil {
-		return nil, trace.Wrap(err)
+		return nil, trace.Wrap(convertTimeout(err))
+	}
+	if !tc.hasNodeFilters() {
+		tc.cacheNodes(servers)
 	}
 
 	return servers, nil
@@ -2164,13 +2747,25 @@ func (tc *TeleportClient) ListApps(ctx context.Context, customFilter *proto.List
 
 	servers, err := tc.ListAppServersWithFilters(ctx, customFilter)
 	if err != nil {
+		// Only the default listing is cached, so custom filters cannot be
+		// served from the cache.
+		if customFilter == nil {
+			if apps, cacheErr := tc.cachedApps(); cacheErr == nil {
+				log.WithError(err).Debug("Proxy is unreachable, serving apps from the local resource cache.")
+				return apps, nil
+			}
+		}
 		return nil, trace.Wrap(err)
 	}
 	var apps []types.Application
 	for _, server := range servers {
 		apps = append(apps, server.GetApp())
 	}
-	return types.DeduplicateApps(apps), nil
+	apps = types.DeduplicateApps(apps)
+	if customFilter == nil {
+		tc.cacheApps(apps)
+	}
+	return apps, nil
 }
 
 // ListAppsAllClusters returns all registered applications across all clusters.
@@ -2311,13 +2906,25 @@ func (tc *TeleportClient) ListDatabases(ctx context.Context, customFilter *proto
 
 	servers, err := tc.ListDatabaseServersWithFilters(ctx, customFilter)
 	if err != nil {
+		// Only the default listing is cached, so custom filters cannot be
+		// served from the cache.
+		if customFilter == nil {
+			if databases, cacheErr := tc.cachedDatabases(); cacheErr == nil {
+				log.WithError(err).Debug("Proxy is unreachable, serving databases from the local resource cache.")
+				return databases, nil
+			}
+		}
 		return nil, trace.Wrap(err)
 	}
 	var databases []types.Database
 	for _, server := range servers {
 		databases = append(databases, server.GetDatabase())
 	}
-	return types.DeduplicateDatabases(databases), nil
+	databases = types.DeduplicateDatabases(databases)
+	if customFilter == nil {
+		tc.cacheDatabases(databases)
+	}
+	return databases, nil
 }
 
 // ListDatabasesAllClusters returns all registered databases across all clusters.
@@ -2392,12 +2999,50 @@ func (tc *TeleportClient) ListKubernetesClustersWithFiltersAllClusters(ctx conte
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
+		if !requestHasFilters(req) {
+			tc.cacheKubeClusters(cluster.Name, kc)
+		}
 		kubeClusters[cluster.Name] = kc
 	}
 
 	return kubeClusters, nil
 }
 
+// ListKubeClusters returns the registered kube clusters in the current
+// teleport cluster, falling back to the local resource cache when the proxy
+// is unreachable.
+func (tc *TeleportClient) ListKubeClusters(ctx context.Context, req proto.ListResourcesRequest) ([]types.KubeCluster, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/ListKubeClusters",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	pc, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		// Only the default listing is cached, so filtered listings cannot be
+		// served from the cache.
+		if !requestHasFilters(req) {
+			if clusters, cacheErr := tc.cachedKubeClusters(tc.SiteName); cacheErr == nil {
+				log.WithError(err).Debug("Proxy is unreachable, serving kube clusters from the local resource cache.")
+				return clusters, nil
+			}
+		}
+		return nil, trace.Wrap(err)
+	}
+	defer pc.Close()
+
+	clusters, err := kubeutils.ListKubeClustersWithFilters(ctx, pc.CurrentCluster(), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if !requestHasFilters(req) {
+		tc.cacheKubeClusters(tc.SiteName, clusters)
+	}
+	return clusters, nil
+}
+
 // roleGetter retrieves roles for the current user
 type roleGetter interface {
 	GetRoles(ctx context.Context) ([]types.Role, error)
@@ -2436,7 +3081,56 @@ func commandLimit(ctx context.Context, getter roleGetter, mfaRequired bool) int
 	}
 }
 
+// NodeExecResult describes the outcome of running a command on a single node
+// during multi-node execution.
+type NodeExecResult struct {
+	// Host is the node the command ran on.
+	Host string
+	// ExitStatus is the exit code of the remote command: 0 on success, the
+	// remote status when one was reported, and 1 when the run failed without
+	// reporting a status (e.g. a connection error).
+	ExitStatus int
+	// Err is the error the run returned, if any.
+	Err error
+}
+
 // runCommandOnNodes executes a given bash command on a bunch of remote nodes.
+// The per-node outcomes are recorded in tc.ExecResults and the overall
+// tc.ExitStatus is set to the highest per-node exit status, so partial
+// failures produce a deterministic exit code.
+// batchSessionMFACerts performs the per-session MFA ceremonies for all of
+// the target nodes in an up-front batch, returning the resulting single-use
+// auth methods keyed by node address. The user is told what is about to
+// happen since one MFA prompt is issued per node; each certificate issuance
+// is audited individually by the auth server with the node it is scoped to.
+func (tc *TeleportClient) batchSessionMFACerts(ctx context.Context, proxyClient *ProxyClient, siteName string, nodeAddresses []string, mfaCheck *proto.IsMFARequiredResponse) (map[string][]ssh.AuthMethod, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/batchSessionMFACerts",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(attribute.Int("nodes", len(nodeAddresses))),
+	)
+	defer span.End()
+
+	fmt.Fprintf(tc.Stderr, "MFA is required; acquiring single-use certificates for %d nodes before running commands in parallel.\n", len(nodeAddresses))
+
+	methods := make(map[string][]ssh.AuthMethod, len(nodeAddresses))
+	for _, address := range nodeAddresses {
+		am, err := proxyClient.sessionSSHCertificate(ctx, NodeDetails{
+			Addr:      address,
+			Namespace: tc.Namespace,
+			Cluster:   siteName,
+			MFACheck:  mfaCheck,
+		})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		log.Debugf("Acquired single-use MFA-verified certificate for node %v.", nodeName(address))
+		methods[address] = am
+	}
+	return methods, nil
+}
+
 func (tc *TeleportClient) runCommandOnNodes(ctx context.Context, siteName string, nodeAddresses []string, proxyClient *ProxyClient, command []string) error {
 	ctx, span := tc.Tracer.Start(
 		ctx,
@@ -2466,8 +3160,44 @@ func (tc *TeleportClient) runCommandOnNodes(ctx context.Context, siteName string
 		return trace.Wrap(err)
 	}
 
+	// recordResult collects per-node outcomes from the executing goroutines.
+	var (
+		resultsMu sync.Mutex
+		results   []NodeExecResult
+	)
+	recordResult := func(address string, err error) {
+		status := exitStatusFromError(err)
+		if status < 0 {
+			// the error carries no exit information (e.g. a connection
+			// failure), report a generic failure
+			status = 1
+		}
+		resultsMu.Lock()
+		results = append(results, NodeExecResult{
+			Host:       nodeName(address),
+			ExitStatus: status,
+			Err:        err,
+		})
+		resultsMu.Unlock()
+	}
+
+	// With batching enabled, the per-node MFA ceremonies run in an up-front
+	// phase, so each goroutine holds its own single-use certificate and the
+	// commands no longer need to be serialized.
+	limit := commandLimit(ctx, clt, mfaRequiredCheck.Required)
+	var nodeAuthMethods map[string][]ssh.AuthMethod
+	if mfaRequiredCheck.Required && tc.Config.BatchMFA {
+		nodeAuthMethods, err = tc.batchSessionMFACerts(ctx, proxyClient, siteName, nodeAddresses, mfaRequiredCheck)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		limit = commandLimit(ctx, clt, false)
+	}
+
+	output := newMultiNodeOutput(tc.Config.MultiNodeOutput, tc.Stdout, tc.Stderr)
+
 	g, gctx := errgroup.WithContext(ctx)
-	g.SetLimit(commandLimit(ctx, clt, mfaRequiredCheck.Required))
+	g.SetLimit(limit)
 	for _, address := range nodeAddresses {
 		address := address
 		g.Go(func() error {
@@ -2483,30 +3213,65 @@ func (tc *TeleportClient) runCommandOnNodes(ctx context.Context, siteName string
 				ctx,
 				proxyClient,
 				NodeDetails{
-					Addr:      address,
-					Namespace: tc.Namespace,
-					Cluster:   siteName,
-					MFACheck:  mfaRequiredCheck,
+					Addr:        address,
+					Namespace:   tc.Namespace,
+					Cluster:     siteName,
+					MFACheck:    mfaRequiredCheck,
+					AuthMethods: nodeAuthMethods[address],
 				},
 				tc.Config.HostLogin,
 			)
 			if err != nil {
 				fmt.Fprintln(tc.Stderr, err)
+				recordResult(address, err)
 				return trace.Wrap(err)
 			}
 			defer nodeClient.Close()
 
-			fmt.Printf("Running command on %v:\n", nodeName(address))
+			// In the prefixed and buffered modes the host is already part
+			// of the output, so the banner would only add noise.
+			if tc.Config.MultiNodeOutput == MultiNodeOutputInterleaved {
+				tc.Infof("Running command on %v:", nodeName(address))
+			}
 
-			return trace.Wrap(tc.runCommand(ctx, nodeClient, command))
+			stdout, stderr, flush := output.writersFor(nodeName(address))
+			err = tc.execCommand(ctx, nodeClient, command, stdout, stderr)
+			flush()
+			recordResult(address, err)
+			return trace.Wrap(err)
 		})
 	}
 
-	return trace.Wrap(g.Wait())
+	err = g.Wait()
+	output.emit()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Host < results[j].Host })
+	tc.ExecResults = results
+	tc.ExitStatus = 0
+	for _, result := range results {
+		if result.ExitStatus > tc.ExitStatus {
+			tc.ExitStatus = result.ExitStatus
+		}
+	}
+
+	return trace.Wrap(err)
 }
 
-// runCommand executes a given bash command on an established NodeClient.
+// runCommand executes a given bash command on an established NodeClient and
+// records the remote exit status in tc.ExitStatus.
 func (tc *TeleportClient) runCommand(ctx context.Context, nodeClient *NodeClient, command []string) error {
+	err := tc.execCommand(ctx, nodeClient, command, tc.Stdout, tc.Stderr)
+	if status := exitStatusFromError(err); status > 0 {
+		tc.ExitStatus = status
+	}
+	return trace.Wrap(err)
+}
+
+// execCommand executes a given bash command on an established NodeClient,
+// writing the remote output to the given writers. It does not mutate any
+// client state, so it is safe to call from the concurrent goroutines of
+// multi-node execution.
+func (tc *TeleportClient) execCommand(ctx context.Context, nodeClient *NodeClient, command []string, stdout, stderr io.Writer) error {
 	ctx, span := tc.Tracer.Start(
 		ctx,
 		"teleportClient/runCommand",
@@ -2514,29 +3279,34 @@ func (tc *TeleportClient) runCommand(ctx context.Context, nodeClient *NodeClient
 	)
 	defer span.End()
 
-	nodeSession, err := newSession(ctx, nodeClient, nil, tc.newSessionEnv(), tc.Stdin, tc.Stdout, tc.Stderr, tc.EnableEscapeSequences)
+	nodeSession, err := newSession(ctx, nodeClient, nil, tc.newSessionEnv(), tc.Stdin, stdout, stderr, tc.EnableEscapeSequences)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 	defer nodeSession.Close()
-	if err := nodeSession.runCommand(ctx, types.SessionPeerMode, command, tc.OnShellCreated, tc.Config.Interactive); err != nil {
-		originErr := trace.Unwrap(err)
-		exitErr, ok := originErr.(*ssh.ExitError)
-		if ok {
-			tc.ExitStatus = exitErr.ExitStatus()
-		} else {
-			// if an error occurs, but no exit status is passed back, GoSSH returns
-			// a generic error like this. in this case the error message is printed
-			// to stderr by the remote process so we have to quietly return 1:
-			if strings.Contains(originErr.Error(), "exited without exit status") {
-				tc.ExitStatus = 1
-			}
-		}
 
-		return trace.Wrap(err)
-	}
+	return trace.Wrap(nodeSession.runCommand(ctx, types.SessionPeerMode, command, tc.OnShellCreated, tc.Config.Interactive))
+}
 
-	return nil
+// exitStatusFromError extracts the remote exit code from a command execution
+// error. It returns 0 for nil, the remote status for *ssh.ExitError, 1 when
+// the remote process exited without reporting a status, and -1 when the
+// error carries no exit information (e.g. a connection failure).
+func exitStatusFromError(err error) int {
+	if err == nil {
+		return 0
+	}
+	originErr := trace.Unwrap(err)
+	if exitErr, ok := originErr.(*ssh.ExitError); ok {
+		return exitErr.ExitStatus()
+	}
+	// if an error occurs, but no exit status is passed back, GoSSH returns
+	// a generic error like this. in this case the error message is printed
+	// to stderr by the remote process so we have to quietly return 1:
+	if strings.Contains(originErr.Error(), "exited without exit status") {
+		return 1
+	}
+	return -1
 }
 
 func (tc *TeleportClient) newSessionEnv() map[string]string {
@@ -2574,7 +3344,13 @@ func (tc *TeleportClient) runShell(ctx context.Context, nodeClient *NodeClient,
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	if err = nodeSession.runShell(ctx, mode, beforeStart, tc.OnShellCreated); err != nil {
+	// A custom shell only applies when starting a new session; joining
+	// attaches to whatever the session is already running.
+	var shellCmd string
+	if sessToJoin == nil {
+		shellCmd = tc.RemoteShell
+	}
+	if err = nodeSession.runShell(ctx, mode, shellCmd, beforeStart, tc.OnShellCreated); err != nil {
 		switch e := trace.Unwrap(err).(type) {
 		case *ssh.ExitError:
 			tc.ExitStatus = e.ExitStatus()
@@ -2651,6 +3427,15 @@ func formatConnectToProxyErr(err error) error {
 // successful. If the passed in context is canceled, this function will return
 // a trace.ConnectionProblem right away.
 func (tc *TeleportClient) ConnectToProxy(ctx context.Context) (*ProxyClient, error) {
+	if tc.PersistentConnection {
+		return tc.persistentProxyClient(ctx)
+	}
+	return tc.newProxyClient(ctx)
+}
+
+// newProxyClient dials the proxy and returns a fresh ProxyClient, bypassing
+// the persistent connection cache.
+func (tc *TeleportClient) newProxyClient(ctx context.Context) (*ProxyClient, error) {
 	ctx, span := tc.Tracer.Start(
 		ctx,
 		"teleportClient/ConnectToProxy",
@@ -2661,31 +3446,43 @@ func (tc *TeleportClient) ConnectToProxy(ctx context.Context) (*ProxyClient, err
 	)
 	defer span.End()
 
-	var err error
-	var proxyClient *ProxyClient
-
-	// Use connectContext and the cancel function to signal when a response is
-	// returned from connectToProxy.
-	connectContext, cancel := context.WithCancel(ctx)
-	go func() {
-		defer cancel()
-		proxyClient, err = tc.connectToProxy(ctx)
-	}()
+	ctx, cancelTimeout, convertTimeout := tc.withTimeoutPolicy(ctx, dialTimeout, "ConnectToProxy")
+	defer cancelTimeout()
 
-	select {
-	// ConnectToProxy returned a result, return that back to the caller.
-	case <-connectContext.Done():
-		return proxyClient, trace.Wrap(formatConnectToProxyErr(err))
-	// The passed in context timed out. This is often due to the network being
-	// down and the user hitting Ctrl-C.
-	case <-ctx.Done():
-		return nil, trace.ConnectionProblem(ctx.Err(), "connection canceled")
+	var proxyClient *ProxyClient
+	err := tc.withRetry(ctx, func(ctx context.Context) error {
+		// Use connectContext and the cancel function to signal when a response
+		// is returned from connectToProxy.
+		connectContext, cancel := context.WithCancel(ctx)
+		var connectErr error
+		go func() {
+			defer cancel()
+			proxyClient, connectErr = tc.connectToProxy(ctx)
+		}()
+
+		select {
+		// connectToProxy returned a result, return that back to the caller.
+		case <-connectContext.Done():
+			return trace.Wrap(convertTimeout(formatConnectToProxyErr(connectErr)))
+		// The passed in context timed out. This is often due to the network
+		// being down and the user hitting Ctrl-C.
+		case <-ctx.Done():
+			return trace.ConnectionProblem(convertTimeout(ctx.Err()), "connection canceled")
+		}
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
 	}
+	return proxyClient, nil
 }
 
 // connectToProxy will dial to the proxy server and return a ProxyClient when
 // successful.
 func (tc *TeleportClient) connectToProxy(ctx context.Context) (*ProxyClient, error) {
+	if err := tc.injectFault(ctx, FaultPointDial); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	sshProxyAddr := tc.Config.SSHProxyAddr
 
 	hostKeyCallback := tc.HostKeyCallback
@@ -2787,6 +3584,10 @@ func (tc *TeleportClient) connectToProxy(ctx context.Context) (*ProxyClient, err
 //  3. Dial sshProxyAddr with raw SSH Dialer where sshProxyAddress is proxy ssh address or JumpHost address if
 //     JumpHost address was provided.
 func makeProxySSHClient(ctx context.Context, tc *TeleportClient, sshConfig *ssh.ClientConfig) (*tracessh.Client, error) {
+	if err := tc.injectFault(ctx, FaultPointSSHHandshake); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	// Use TLS Routing dialer only if proxy support TLS Routing and JumpHost was not set.
 	if tc.Config.TLSRoutingEnabled && len(tc.JumpHosts) == 0 {
 		log.Infof("Connecting to proxy=%v login=%q using TLS Routing", tc.Config.WebProxyAddr, sshConfig.User)
@@ -3022,6 +3823,9 @@ func (tc *TeleportClient) Login(ctx context.Context) (*Key, error) {
 
 	// Ping the endpoint to see if it's up and find the type of authentication
 	// supported, also show the message of the day if available.
+	if err := tc.reportLoginProgress(ctx, LoginStagePing); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	pr, err := tc.PingAndShowMOTD(ctx)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -3307,6 +4111,9 @@ func (tc *TeleportClient) pwdlessLogin(ctx context.Context, priv *keys.PrivateKe
 		return nil, trace.Wrap(err)
 	}
 
+	if err := tc.reportLoginProgress(ctx, LoginStageWaitingMFATouch); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	response, err := SSHAgentPasswordlessLogin(ctx, SSHLoginPasswordless{
 		SSHLogin:                sshLogin,
 		User:                    user,
@@ -3366,6 +4173,9 @@ func (tc *TeleportClient) directLogin(ctx context.Context, secondFactorType cons
 	}
 
 	// Ask the CA (via proxy) to sign our public key:
+	if err := tc.reportLoginProgress(ctx, LoginStageIssuingCerts); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	response, err := SSHAgentLogin(ctx, SSHLoginDirect{
 		SSHLogin: sshLogin,
 		User:     tc.Username,
@@ -3388,6 +4198,9 @@ func (tc *TeleportClient) mfaLocalLogin(ctx context.Context, priv *keys.PrivateK
 		return nil, trace.Wrap(err)
 	}
 
+	if err := tc.reportLoginProgress(ctx, LoginStageWaitingMFATouch); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	response, err := SSHAgentMFALogin(ctx, SSHLoginMFA{
 		SSHLogin:                sshLogin,
 		User:                    tc.Username,
@@ -3416,12 +4229,16 @@ func (tc *TeleportClient) ssoLogin(ctx context.Context, priv *keys.PrivateKey, c
 	}
 
 	// ask the CA (via proxy) to sign our public key:
+	if err := tc.reportLoginProgress(ctx, LoginStageSSORedirectOpened); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	response, err := SSHAgentSSOLogin(ctx, SSHLoginSSO{
-		SSHLogin:    sshLogin,
-		ConnectorID: connectorID,
-		Protocol:    protocol,
-		BindAddr:    tc.BindAddr,
-		Browser:     tc.Browser,
+		SSHLogin:       sshLogin,
+		ConnectorID:    connectorID,
+		Protocol:       protocol,
+		BindAddr:       tc.BindAddr,
+		CallbackServer: tc.SSOCallbackServer,
+		Browser:        tc.Browser,
 	}, nil)
 	return response, trace.Wrap(err)
 }
@@ -3441,6 +4258,10 @@ func (tc *TeleportClient) ActivateKey(ctx context.Context, key *Key) error {
 		return nil
 	}
 
+	if err := tc.reportLoginProgress(ctx, LoginStageSavingProfile); err != nil {
+		return trace.Wrap(err)
+	}
+
 	// save the cert to the local storage (~/.tsh usually):
 	if err := tc.localAgent.AddKey(key); err != nil {
 		return trace.Wrap(err)
@@ -3467,8 +4288,9 @@ func (tc *TeleportClient) ActivateKey(ctx context.Context, key *Key) error {
 }
 
 // Ping makes a ping request to the proxy, and updates tc based on the
-// response. The successful ping response is cached, multiple calls to Ping
-// will return the original response and skip the round-trip.
+// response. The successful ping response is cached for PingCacheTTL (for the
+// client lifetime when zero); calls within that window return the original
+// response and skip the round-trip. Use InvalidatePing to force a refresh.
 //
 // Ping can be called for its side-effect of applying the proxy-provided
 // settings (such as various listening addresses).
@@ -3480,22 +4302,39 @@ func (tc *TeleportClient) Ping(ctx context.Context) (*webclient.PingResponse, er
 	)
 	defer span.End()
 
-	// If, at some point, there's a need to bypass this caching, consider
-	// adding a bool argument. At the time of writing this we always want to
-	// cache.
-	if tc.lastPing != nil {
+	if tc.lastPing != nil &&
+		(tc.PingCacheTTL == 0 || time.Since(tc.lastPingTime) < tc.PingCacheTTL) {
 		return tc.lastPing, nil
 	}
-	pr, err := webclient.Ping(&webclient.Config{
-		Context:       ctx,
-		ProxyAddr:     tc.WebProxyAddr,
-		Insecure:      tc.InsecureSkipVerify,
-		Pool:          loopbackPool(tc.WebProxyAddr),
-		ConnectorName: tc.AuthConnector,
-		ExtraHeaders:  tc.ExtraProxyHeaders,
+
+	if err := tc.injectFault(ctx, FaultPointPing); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	// Verify the proxy against the configured CA pins (if any) before the
+	// first round-trip.
+	if err := tc.verifyCAPins(ctx); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	ctx, cancelTimeout, convertTimeout := tc.withTimeoutPolicy(ctx, rpcTimeout, "Ping")
+	defer cancelTimeout()
+
+	var pr *webclient.PingResponse
+	err := tc.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		pr, err = webclient.Ping(&webclient.Config{
+			Context:       ctx,
+			ProxyAddr:     tc.WebProxyAddr,
+			Insecure:      tc.InsecureSkipVerify,
+			Pool:          loopbackPool(tc.WebProxyAddr),
+			ConnectorName: tc.AuthConnector,
+			ExtraHeaders:  tc.ExtraProxyHeaders,
+		})
+		return trace.Wrap(err)
 	})
 	if err != nil {
-		return nil, trace.Wrap(err)
+		return nil, trace.Wrap(convertTimeout(err))
 	}
 
 	// If version checking was requested and the server advertises a minimum version.
@@ -3518,16 +4357,24 @@ func (tc *TeleportClient) Ping(ctx context.Context) (*webclient.PingResponse, er
 	tc.applyAuthSettings(pr.Auth)
 
 	tc.lastPing = pr
+	tc.lastPingTime = time.Now()
 
 	return pr, nil
 }
 
-// ShowMOTD fetches the cluster MotD, displays it (if any) and waits for
-// confirmation from the user.
-func (tc *TeleportClient) ShowMOTD(ctx context.Context) error {
+// InvalidatePing drops the cached Ping response, forcing the next Ping call
+// to make a fresh round-trip to the proxy.
+func (tc *TeleportClient) InvalidatePing() {
+	tc.lastPing = nil
+	tc.lastPingTime = time.Time{}
+}
+
+// GetMOTD fetches the cluster MotD without displaying it or prompting for
+// acknowledgment, so callers such as GUIs can render the message natively.
+func (tc *TeleportClient) GetMOTD(ctx context.Context) (*webclient.MotD, error) {
 	ctx, span := tc.Tracer.Start(
 		ctx,
-		"teleportClient/ShowMOTD",
+		"teleportClient/GetMOTD",
 		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
 	)
 	defer span.End()
@@ -3540,11 +4387,32 @@ func (tc *TeleportClient) ShowMOTD(ctx context.Context) error {
 			Pool:         loopbackPool(tc.WebProxyAddr),
 			ExtraHeaders: tc.ExtraProxyHeaders,
 		})
+	return motd, trace.Wrap(err)
+}
+
+// ShowMOTD fetches the cluster MotD, displays it (if any) and waits for
+// confirmation from the user. When RememberMOTDAck is set, an unchanged
+// message that was acknowledged before is not shown again, and new
+// acknowledgments are recorded for SaveProfile to persist.
+func (tc *TeleportClient) ShowMOTD(ctx context.Context) error {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/ShowMOTD",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	motd, err := tc.GetMOTD(ctx)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
 	if motd.Text != "" {
+		hash := motdHash(motd.Text)
+		if tc.RememberMOTDAck && tc.AcknowledgedMOTD == hash {
+			return nil
+		}
+
 		fmt.Fprintf(tc.Config.Stderr, "%s\nPress [ENTER] to continue.\n", motd.Text)
 		// We're re-using the password reader for user acknowledgment for
 		// aesthetic purposes, because we want to hide any garbage the
@@ -3555,11 +4423,22 @@ func (tc *TeleportClient) ShowMOTD(ctx context.Context) error {
 		if err != nil {
 			return trace.Wrap(err)
 		}
+
+		if tc.RememberMOTDAck {
+			tc.AcknowledgedMOTD = hash
+		}
 	}
 
 	return nil
 }
 
+// motdHash returns the hash of a message of the day used as the
+// acknowledgment key in the profile.
+func motdHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
 // GetTrustedCA returns a list of host certificate authorities
 // trusted by the cluster client is authenticated with.
 func (tc *TeleportClient) GetTrustedCA(ctx context.Context, clusterName string) ([]types.CertAuthority, error) {
@@ -3782,6 +4661,12 @@ func (tc *TeleportClient) applyAuthSettings(authSettings webclient.Authenticatio
 	if authSettings.PrivateKeyPolicy != "" && authSettings.PrivateKeyPolicy.VerifyPolicy(tc.PrivateKeyPolicy) != nil {
 		tc.PrivateKeyPolicy = authSettings.PrivateKeyPolicy
 	}
+
+	// Adopt the cluster's recording encryption key so locally captured
+	// session recordings are sealed to it.
+	if authSettings.RecordingEncryptionPublicKey != "" {
+		tc.RecordingEncryptionKey = authSettings.RecordingEncryptionPublicKey
+	}
 }
 
 // AddTrustedCA adds a new CA as trusted CA for this client, used in tests
@@ -4040,8 +4925,10 @@ func ParseSearchKeywords(spec string, customDelimiter rune) []string {
 }
 
 // Executes the given command on the client machine (localhost). If no command is given,
-// executes shell
-func runLocalCommand(command []string) error {
+// executes shell. The command inherits the environment variables in env on
+// top of the current process environment and is killed when ctx is canceled,
+// so its lifetime is bound to the tunnels it was started for.
+func runLocalCommand(ctx context.Context, command []string, env []string) error {
 	if len(command) == 0 {
 		user, err := user.Current()
 		if err != nil {
@@ -4053,13 +4940,42 @@ func runLocalCommand(command []string) error {
 		}
 		command = []string{shell}
 	}
-	cmd := exec.Command(command[0], command[1:]...)
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	if len(env) != 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	return cmd.Run()
 }
 
+// forwardedPortsEnvironment builds environment variables describing the
+// established local port forwards, suitable for passing to a local command
+// that uses the tunnels (e.g. a psql or test-runner wrapper):
+//
+//	TELEPORT_FORWARD_COUNT=2
+//	TELEPORT_FORWARD_0_LOCAL=127.0.0.1:5432
+//	TELEPORT_FORWARD_0_REMOTE=db.internal:5432
+//	...
+func forwardedPortsEnvironment(ports ForwardedPorts) []string {
+	if len(ports) == 0 {
+		return nil
+	}
+	env := []string{fmt.Sprintf("TELEPORT_FORWARD_COUNT=%d", len(ports))}
+	for i, p := range ports {
+		remote := p.DestSocket
+		if remote == "" {
+			remote = net.JoinHostPort(p.DestHost, strconv.Itoa(p.DestPort))
+		}
+		env = append(env,
+			fmt.Sprintf("TELEPORT_FORWARD_%d_LOCAL=%s", i, net.JoinHostPort(p.SrcIP, strconv.Itoa(p.SrcPort))),
+			fmt.Sprintf("TELEPORT_FORWARD_%d_REMOTE=%s", i, remote),
+		)
+	}
+	return env
+}
+
 // String returns the same string spec which can be parsed by ParsePortForwardSpec.
 func (fp ForwardedPorts) String() (retval []string) {
 	for _, p := range fp {
@@ -4068,24 +4984,69 @@ func (fp ForwardedPorts) String() (retval []string) {
 	return retval
 }
 
-// ParsePortForwardSpec parses parameter to -L flag, i.e. strings like "[ip]:80:remote.host:3000"
+// splitForwardSpec splits a port forwarding spec on colons, keeping
+// bracketed IPv6 addresses like "[::1]" intact. Brackets are stripped from
+// the returned parts.
+func splitForwardSpec(str string) []string {
+	var parts []string
+	var part strings.Builder
+	inBrackets := false
+	for _, r := range str {
+		switch {
+		case r == '[':
+			inBrackets = true
+		case r == ']':
+			inBrackets = false
+		case r == ':' && !inBrackets:
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteRune(r)
+		}
+	}
+	return append(parts, part.String())
+}
+
+// ParsePortForwardSpec parses parameter to -L flag, i.e. strings like
+// "[ip]:80:remote.host:3000". IPv6 addresses must be bracketed, e.g.
+// "80:[::1]:3000". A destination starting with "/" is treated as the path of
+// a Unix domain socket on the remote host, e.g. "80:/var/run/docker.sock".
 // The opposite of this function (spec generation) is ForwardedPorts.String()
 func ParsePortForwardSpec(spec []string) (ports ForwardedPorts, err error) {
 	if len(spec) == 0 {
 		return ports, nil
 	}
-	const errTemplate = "invalid port forwarding spec '%s': expected format `80:remote.host:80`"
+	const errTemplate = "invalid port forwarding spec '%s': expected format `80:remote.host:80` or `80:/path/to/remote.sock`"
 	ports = make([]ForwardedPort, len(spec))
 
 	for i, str := range spec {
-		parts := strings.Split(str, ":")
+		parts := splitForwardSpec(str)
+		p := &ports[i]
+
+		// A destination starting with "/" is a Unix domain socket on the
+		// remote host; the spec has one part fewer than the TCP form.
+		if strings.HasPrefix(parts[len(parts)-1], "/") {
+			if len(parts) < 2 || len(parts) > 3 {
+				return nil, trace.BadParameter(errTemplate, str)
+			}
+			if len(parts) == 2 {
+				parts = append([]string{"127.0.0.1"}, parts...)
+			}
+			p.SrcIP = parts[0]
+			p.SrcPort, err = strconv.Atoi(parts[1])
+			if err != nil {
+				return nil, trace.BadParameter(errTemplate, str)
+			}
+			p.DestSocket = parts[2]
+			continue
+		}
+
 		if len(parts) < 3 || len(parts) > 4 {
 			return nil, trace.BadParameter(errTemplate, str)
 		}
 		if len(parts) == 3 {
 			parts = append([]string{"127.0.0.1"}, parts...)
 		}
-		p := &ports[i]
 		p.SrcIP = parts[0]
 		p.SrcPort, err = strconv.Atoi(parts[1])
 		if err != nil {
@@ -4159,7 +5120,7 @@ func isFIPS() bool {
 }
 
 // playSession plays session in the terminal
-func playSession(sessionEvents []events.EventFields, stream []byte) error {
+func playSession(sessionEvents []events.EventFields, stream []byte, watermark *PlaybackWatermark) error {
 	term, err := terminal.New(nil, nil, nil)
 	if err != nil {
 		return trace.Wrap(err)
@@ -4175,7 +5136,7 @@ func playSession(sessionEvents []events.EventFields, stream []byte) error {
 		}
 	}
 
-	player := newSessionPlayer(sessionEvents, stream, term)
+	player := newSessionPlayer(sessionEvents, stream, term, watermark)
 	errorCh := make(chan error)
 	// keys:
 	const (
@@ -4261,6 +5222,22 @@ func getDesktopEventWebURL(proxyHost string, cluster string, sid *session.ID, ev
 	return fmt.Sprintf("https://%s/web/cluster/%s/session/%s?recordingType=desktop&durationMs=%d", proxyHost, cluster, sid, duration/time.Millisecond)
 }
 
+// getSessionEventWebURL returns the web UI URL users can access to
+// watch an SSH or Kubernetes session recording in the browser. Both
+// recording types share the same web player, so no recordingType
+// parameter is needed.
+func getSessionEventWebURL(proxyHost string, cluster string, sid *session.ID) string {
+	return fmt.Sprintf("https://%s/web/cluster/%s/session/%s", proxyHost, cluster, sid)
+}
+
+// SessionRecordingWebURL returns the web UI URL for playing back the
+// recording of the given session in the browser, so CLI output and
+// notifications can deep-link reviewers to it. The cluster is the name of
+// the cluster the session was recorded on, which may be a leaf cluster.
+func (tc *TeleportClient) SessionRecordingWebURL(cluster string, sid session.ID) string {
+	return getSessionEventWebURL(tc.WebProxyHost(), cluster, &sid)
+}
+
 // SearchSessionEvents allows searching for session events with a full pagination support.
 func (tc *TeleportClient) SearchSessionEvents(ctx context.Context, fromUTC, toUTC time.Time, pageSize int, order types.EventOrder, max int) ([]apievents.AuditEvent, error) {
 	ctx, span := tc.Tracer.Start(
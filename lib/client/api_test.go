@@ -160,6 +160,41 @@ func TestParseProxyHostString(t *testing.T) {
 			input:     "example.org:100,200,300,400",
 			expectErr: true,
 			expect:    ParsedProxyHost{},
+		}, {
+			name:      "https URL without port",
+			input:     "https://example.org",
+			expectErr: false,
+			expect: ParsedProxyHost{
+				Host:                     "example.org",
+				UsingDefaultWebProxyPort: true,
+				WebProxyAddr:             "example.org:443",
+				SSHProxyAddr:             "example.org:3023",
+			},
+		}, {
+			name:      "https URL with port and path",
+			input:     "https://example.org:3080/web/cluster",
+			expectErr: false,
+			expect: ParsedProxyHost{
+				Host:                     "example.org",
+				UsingDefaultWebProxyPort: false,
+				WebProxyAddr:             "example.org:3080",
+				SSHProxyAddr:             "example.org:3023",
+			},
+		}, {
+			name:      "http URL rejected",
+			input:     "http://example.org",
+			expectErr: true,
+			expect:    ParsedProxyHost{},
+		}, {
+			name:      "unsupported scheme rejected",
+			input:     "ftp://example.org",
+			expectErr: true,
+			expect:    ParsedProxyHost{},
+		}, {
+			name:      "URL without host rejected",
+			input:     "https://",
+			expectErr: true,
+			expect:    ParsedProxyHost{},
 		},
 	}
 
@@ -276,11 +311,56 @@ func TestPortsParsing(t *testing.T) {
 	require.Equal(t, spec[0], clone[0])
 	require.Equal(t, spec[1], clone[1])
 
+	// IPv6 destinations and Unix domain socket destinations:
+	spec = []string{
+		"80:[::1]:3000",
+		"[2001:db8::1]:443:deep.host:1443",
+		"8080:/var/run/docker.sock",
+		"10.0.10.1:8081:/run/systemd/private",
+	}
+	ports, err = ParsePortForwardSpec(spec)
+	require.NoError(t, err)
+	require.Empty(t, cmp.Diff(ports, ForwardedPorts{
+		{
+			SrcIP:    "127.0.0.1",
+			SrcPort:  80,
+			DestHost: "::1",
+			DestPort: 3000,
+		},
+		{
+			SrcIP:    "2001:db8::1",
+			SrcPort:  443,
+			DestHost: "deep.host",
+			DestPort: 1443,
+		},
+		{
+			SrcIP:      "127.0.0.1",
+			SrcPort:    8080,
+			DestSocket: "/var/run/docker.sock",
+		},
+		{
+			SrcIP:      "10.0.10.1",
+			SrcPort:    8081,
+			DestSocket: "/run/systemd/private",
+		},
+	}))
+
+	// these round-trip as well:
+	clone = ports.String()
+	require.Equal(t, spec[0], clone[0])
+	require.Equal(t, spec[2], clone[2])
+
 	// parse invalid spec:
 	spec = []string{"foo", "bar"}
 	ports, err = ParsePortForwardSpec(spec)
 	require.Empty(t, ports)
 	require.True(t, trace.IsBadParameter(err), "expected bad parameter, got %v", err)
+
+	// socket destination with too many parts:
+	spec = []string{"extra:10.0.10.1:8081:/run/systemd/private"}
+	ports, err = ParsePortForwardSpec(spec)
+	require.Empty(t, ports)
+	require.True(t, trace.IsBadParameter(err), "expected bad parameter, got %v", err)
 }
 
 func TestDynamicPortsParsing(t *testing.T) {
@@ -965,3 +1045,31 @@ func TestCommandLimit(t *testing.T) {
 		})
 	}
 }
+
+func TestForwardedPortsEnvironment(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, forwardedPortsEnvironment(nil))
+
+	env := forwardedPortsEnvironment(ForwardedPorts{
+		{SrcIP: "127.0.0.1", SrcPort: 5432, DestHost: "db.internal", DestPort: 5432},
+		{SrcIP: "0.0.0.0", SrcPort: 8080, DestHost: "web.internal", DestPort: 80},
+	})
+	require.Equal(t, []string{
+		"TELEPORT_FORWARD_COUNT=2",
+		"TELEPORT_FORWARD_0_LOCAL=127.0.0.1:5432",
+		"TELEPORT_FORWARD_0_REMOTE=db.internal:5432",
+		"TELEPORT_FORWARD_1_LOCAL=0.0.0.0:8080",
+		"TELEPORT_FORWARD_1_REMOTE=web.internal:80",
+	}, env)
+}
+
+func TestSessionRecordingWebURL(t *testing.T) {
+	t.Parallel()
+
+	tc := &TeleportClient{Config: Config{WebProxyAddr: "proxy.example.com:3080"}}
+	sid := session.ID("5ba70f31-64fa-4767-b879-31cbd8808063")
+	require.Equal(t,
+		"https://proxy.example.com/web/cluster/leaf/session/5ba70f31-64fa-4767-b879-31cbd8808063",
+		tc.SessionRecordingWebURL("leaf", sid))
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/auth"
+)
+
+// PrefetchCAs fetches the TLS certificate authorities of the given clusters
+// and pins them in the client store, so kube, database, and app tunnels can
+// still be established while the auth server is briefly unreachable, as long
+// as the user's certificates remain valid. When no clusters are given, the
+// CAs of every cluster reachable through the proxy are fetched.
+func (tc *TeleportClient) PrefetchCAs(ctx context.Context, clusters []string) error {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/PrefetchCAs",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(attribute.StringSlice("clusters", clusters)),
+	)
+	defer span.End()
+
+	if tc.localAgent == nil {
+		return trace.BadParameter("TeleportClient.PrefetchCAs called on a client without localAgent")
+	}
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	if len(clusters) == 0 {
+		sites, err := proxyClient.GetSites(ctx)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		for _, site := range sites {
+			clusters = append(clusters, site.Name)
+		}
+	}
+
+	var authorities []types.CertAuthority
+	for _, cluster := range clusters {
+		cas, err := tc.fetchClusterCAs(ctx, proxyClient, cluster)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		authorities = append(authorities, cas...)
+	}
+
+	// Pin the CA pool and known hosts for everything fetched; existing
+	// entries for the same clusters are updated in place.
+	return trace.Wrap(tc.localAgent.SaveTrustedCerts(auth.AuthoritiesToTrustedCerts(authorities)))
+}
+
+// fetchClusterCAs returns the host and database certificate authorities of a
+// single cluster.
+func (tc *TeleportClient) fetchClusterCAs(ctx context.Context, proxyClient *ProxyClient, cluster string) ([]types.CertAuthority, error) {
+	clt, err := proxyClient.ConnectToCluster(ctx, cluster)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer clt.Close()
+
+	var authorities []types.CertAuthority
+	// The host CA backs kube and app tunnels; databases are served with
+	// certificates from the dedicated database CA.
+	for _, caType := range []types.CertAuthType{types.HostCA, types.DatabaseCA} {
+		cas, err := clt.GetCertAuthorities(ctx, caType, false)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		authorities = append(authorities, cas...)
+	}
+	return authorities, nil
+}
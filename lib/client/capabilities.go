@@ -0,0 +1,76 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/gravitational/teleport/api/constants"
+)
+
+// ProxyCapabilities is a typed summary of the features advertised by the
+// proxy, derived from the Ping response. It saves callers from
+// re-implementing scattered checks against raw webclient responses.
+type ProxyCapabilities struct {
+	// TLSRoutingEnabled is true when all proxy services are multiplexed on a
+	// single TLS listener (ALPN SNI routing).
+	TLSRoutingEnabled bool
+	// KubeListenerEnabled is true when the proxy serves a kubernetes
+	// listener (either dedicated or via TLS routing).
+	KubeListenerEnabled bool
+	// PerProtocolDatabaseListeners is true when the proxy exposes dedicated
+	// per-protocol database listeners (Postgres, MySQL, Mongo).
+	PerProtocolDatabaseListeners bool
+	// PasswordlessEnabled is true when passwordless logins are allowed.
+	PasswordlessEnabled bool
+	// DeviceTrustEnabled is true when clients should attempt device
+	// authentication.
+	DeviceTrustEnabled bool
+	// SecondFactorEnabled is true when some form of second factor is
+	// required or optional for logins.
+	SecondFactorEnabled bool
+}
+
+// Capabilities returns the feature set advertised by the proxy. The result is
+// derived from the (cached) Ping response; see Ping for cache semantics.
+func (tc *TeleportClient) Capabilities(ctx context.Context) (*ProxyCapabilities, error) {
+	ctx, span := tc.Tracer.Start(ctx,
+		"teleportClient/Capabilities",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	pr, err := tc.Ping(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return &ProxyCapabilities{
+		TLSRoutingEnabled: pr.Proxy.TLSRoutingEnabled,
+		KubeListenerEnabled: pr.Proxy.Kube.Enabled &&
+			(pr.Proxy.TLSRoutingEnabled || pr.Proxy.Kube.ListenAddr != ""),
+		PerProtocolDatabaseListeners: pr.Proxy.DB.PostgresListenAddr != "" ||
+			pr.Proxy.DB.MySQLListenAddr != "" ||
+			pr.Proxy.DB.MongoListenAddr != "",
+		PasswordlessEnabled: pr.Auth.AllowPasswordless,
+		DeviceTrustEnabled:  !pr.Auth.DeviceTrustDisabled,
+		SecondFactorEnabled: pr.Auth.SecondFactor != constants.SecondFactorOff,
+	}, nil
+}
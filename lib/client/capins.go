@@ -0,0 +1,69 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/utils"
+)
+
+// verifyCAPins dials the proxy's TLS listener and verifies that the
+// presented certificate chain contains a certificate matching one of the
+// configured SPKI pins. This is done over a deliberately insecure
+// connection: even if the connection is intercepted, a man in the middle
+// cannot present a chain matching the pins.
+//
+// Unlike the node join pinning (where every CA certificate must match a
+// pin), any certificate in the served chain may match, so users can pin
+// either the cluster CA or the proxy certificate itself.
+func (tc *TeleportClient) verifyCAPins(ctx context.Context) error {
+	if len(tc.CAPins) == 0 {
+		return nil
+	}
+	// Validate the format of the pins before dialing.
+	if err := utils.CheckSPKI(tc.CAPins, nil); err != nil {
+		return trace.Wrap(err)
+	}
+
+	dialer := &tls.Dialer{Config: &tls.Config{
+		InsecureSkipVerify: true,
+	}}
+	conn, err := dialer.DialContext(ctx, "tcp", tc.WebProxyAddr)
+	if err != nil {
+		return trace.Wrap(err, "failed to dial proxy %v to verify CA pins", tc.WebProxyAddr)
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	for _, cert := range state.PeerCertificates {
+		for _, pin := range tc.CAPins {
+			if subtle.ConstantTimeCompare([]byte(utils.CalculateSPKI(cert)), []byte(pin)) == 1 {
+				return nil
+			}
+		}
+	}
+
+	return trace.AccessDenied(
+		"proxy %v presented a certificate chain that matches none of the configured CA pins; "+
+			"this could indicate a man-in-the-middle attack or an out-of-date pin",
+		tc.WebProxyAddr)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport"
+	"github.com/gravitational/teleport/api/types/wrappers"
+	"github.com/gravitational/teleport/lib/tlsca"
+)
+
+// DeviceCertExtensions are the trusted device extensions encoded into the
+// user's SSH certificate.
+type DeviceCertExtensions struct {
+	// DeviceID is the trusted device identifier.
+	DeviceID string
+	// AssetTag is the device inventory identifier.
+	AssetTag string
+	// CredentialID is the identifier for the credential used by the device
+	// to authenticate itself.
+	CredentialID string
+}
+
+// CertExtensions are the Teleport-specific extensions parsed out of the
+// profile's certificates, so consumers don't have to re-implement SSH and
+// TLS certificate parsing.
+type CertExtensions struct {
+	// Roles is the list of Teleport roles assigned to the user.
+	Roles []string
+	// Traits hold claim data used to populate a role at runtime.
+	Traits wrappers.Traits
+	// Logins are the allowed Linux accounts (SSH certificate principals).
+	Logins []string
+	// KubeUsers are the allowed kubernetes users.
+	KubeUsers []string
+	// KubeGroups are the allowed kubernetes groups.
+	KubeGroups []string
+	// Device holds the trusted device extensions, if any.
+	Device DeviceCertExtensions
+}
+
+// CertExtensionsChangedFunc is called after certificates are reissued with
+// the extensions parsed from the new certificates.
+type CertExtensionsChangedFunc func(ext *CertExtensions)
+
+// CertExtensions parses the Teleport-specific extensions out of the key's
+// SSH and TLS certificates into typed fields.
+func (k *Key) CertExtensions() (*CertExtensions, error) {
+	sshCert, err := k.SSHCert()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	roles, err := k.CertRoles()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var traits wrappers.Traits
+	if rawTraits, ok := sshCert.Extensions[teleport.CertExtensionTeleportTraits]; ok {
+		if err := wrappers.UnmarshalTraits([]byte(rawTraits), &traits); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
+	tlsCert, err := k.TeleportTLSCertificate()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	tlsID, err := tlsca.FromSubject(tlsCert.Subject, time.Time{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return &CertExtensions{
+		Roles:      roles,
+		Traits:     traits,
+		Logins:     sshCert.ValidPrincipals,
+		KubeUsers:  tlsID.KubernetesUsers,
+		KubeGroups: tlsID.KubernetesGroups,
+		Device: DeviceCertExtensions{
+			DeviceID:     sshCert.Extensions[teleport.CertExtensionDeviceID],
+			AssetTag:     sshCert.Extensions[teleport.CertExtensionDeviceAssetTag],
+			CredentialID: sshCert.Extensions[teleport.CertExtensionDeviceCredentialID],
+		},
+	}, nil
+}
+
+// CertExtensions returns the profile's certificate extensions as typed
+// fields.
+func (p *ProfileStatus) CertExtensions() *CertExtensions {
+	return &CertExtensions{
+		Roles:      p.Roles,
+		Traits:     p.Traits,
+		Logins:     p.Logins,
+		KubeUsers:  p.KubeUsers,
+		KubeGroups: p.KubeGroups,
+		Device:     p.DeviceExtensions,
+	}
+}
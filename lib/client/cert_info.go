@@ -0,0 +1,134 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/tlsca"
+)
+
+// Certificate kinds reported by CertificateInfos.
+const (
+	// CertificateKindUser is the user TLS certificate.
+	CertificateKindUser = "user"
+	// CertificateKindApp is an app access certificate.
+	CertificateKindApp = "app"
+	// CertificateKindDatabase is a database access certificate.
+	CertificateKindDatabase = "db"
+	// CertificateKindKubernetes is a Kubernetes access certificate.
+	CertificateKindKubernetes = "kube"
+)
+
+// CertificateInfo is structured metadata about a single issued TLS
+// certificate, so status views and expiry monitors don't need to parse PEM
+// by hand.
+type CertificateInfo struct {
+	// Kind is one of the CertificateKind* constants.
+	Kind string `json:"kind"`
+	// Name is the app, database or Kubernetes cluster the certificate was
+	// issued for; empty for the user certificate.
+	Name string `json:"name,omitempty"`
+	// TeleportCluster is the Teleport cluster that issued the certificate.
+	TeleportCluster string `json:"teleport_cluster"`
+	// Username is the Teleport user the certificate was issued to.
+	Username string `json:"username"`
+	// NotBefore is the start of the certificate's validity window.
+	NotBefore time.Time `json:"not_before"`
+	// NotAfter is the end of the certificate's validity window.
+	NotAfter time.Time `json:"not_after"`
+	// RouteToApp is the application routing metadata, if any.
+	RouteToApp tlsca.RouteToApp `json:"route_to_app,omitempty"`
+	// RouteToDatabase is the database routing metadata, if any.
+	RouteToDatabase tlsca.RouteToDatabase `json:"route_to_database,omitempty"`
+	// KubernetesCluster is the target Kubernetes cluster, if any.
+	KubernetesCluster string `json:"kubernetes_cluster,omitempty"`
+	// PrivateKeyPolicy is the private key policy the certificate was issued
+	// under (e.g. hardware key requirements).
+	PrivateKeyPolicy string `json:"private_key_policy,omitempty"`
+	// DeviceVerified indicates the certificate carries trusted device
+	// extensions.
+	DeviceVerified bool `json:"device_verified"`
+}
+
+// certificateInfo parses a PEM-encoded certificate into structured metadata.
+func certificateInfo(kind, name string, certPEM []byte) (*CertificateInfo, error) {
+	cert, err := tlsca.ParseCertificatePEM(certPEM)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	identity, err := tlsca.FromSubject(cert.Subject, cert.NotAfter)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &CertificateInfo{
+		Kind:              kind,
+		Name:              name,
+		TeleportCluster:   identity.TeleportCluster,
+		Username:          identity.Username,
+		NotBefore:         cert.NotBefore,
+		NotAfter:          cert.NotAfter,
+		RouteToApp:        identity.RouteToApp,
+		RouteToDatabase:   identity.RouteToDatabase,
+		KubernetesCluster: identity.KubernetesCluster,
+		PrivateKeyPolicy:  string(identity.PrivateKeyPolicy),
+		DeviceVerified:    identity.DeviceExtensions.DeviceID != "",
+	}, nil
+}
+
+// CertificateInfos returns structured metadata about every TLS certificate
+// held by the key: the user certificate and any per-app, per-database and
+// per-Kubernetes-cluster certificates. Results are sorted by kind and name
+// for stable output.
+func (k *Key) CertificateInfos() ([]CertificateInfo, error) {
+	var infos []CertificateInfo
+
+	if len(k.TLSCert) > 0 {
+		info, err := certificateInfo(CertificateKindUser, "", k.TLSCert)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		infos = append(infos, *info)
+	}
+	for _, certs := range []struct {
+		kind  string
+		byKey map[string][]byte
+	}{
+		{CertificateKindApp, k.AppTLSCerts},
+		{CertificateKindDatabase, k.DBTLSCerts},
+		{CertificateKindKubernetes, k.KubeTLSCerts},
+	} {
+		for name, certPEM := range certs.byKey {
+			info, err := certificateInfo(certs.kind, name, certPEM)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			infos = append(infos, *info)
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Kind != infos[j].Kind {
+			return infos[i].Kind < infos[j].Kind
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
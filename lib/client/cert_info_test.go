@@ -0,0 +1,95 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/auth/testauthority"
+	"github.com/gravitational/teleport/lib/tlsca"
+)
+
+func TestCertificateInfos(t *testing.T) {
+	t.Parallel()
+
+	ca, _, err := newSelfSignedCA(CAPriv, "root-cluster")
+	require.NoError(t, err)
+	priv, err := testauthority.New().GeneratePrivateKey()
+	require.NoError(t, err)
+
+	clock := clockwork.NewRealClock()
+	makeCert := func(identity tlsca.Identity) []byte {
+		identity.TeleportCluster = "root-cluster"
+		subject, err := identity.Subject()
+		require.NoError(t, err)
+		cert, err := ca.GenerateCertificate(tlsca.CertificateRequest{
+			Clock:     clock,
+			PublicKey: priv.Public(),
+			Subject:   subject,
+			NotAfter:  clock.Now().UTC().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		return cert
+	}
+
+	key := NewKey(priv)
+	key.TLSCert = makeCert(tlsca.Identity{
+		Username:         "alice",
+		Groups:           []string{"access"},
+		DeviceExtensions: tlsca.DeviceExtensions{DeviceID: "device-1"},
+	})
+	key.DBTLSCerts["postgres"] = makeCert(tlsca.Identity{
+		Username: "alice",
+		Groups:   []string{"access"},
+		RouteToDatabase: tlsca.RouteToDatabase{
+			ServiceName: "postgres",
+			Protocol:    "postgres",
+			Username:    "reader",
+		},
+	})
+	key.KubeTLSCerts["kube1"] = makeCert(tlsca.Identity{
+		Username:          "alice",
+		Groups:            []string{"access"},
+		KubernetesCluster: "kube1",
+	})
+
+	infos, err := key.CertificateInfos()
+	require.NoError(t, err)
+	require.Len(t, infos, 3)
+
+	// Sorted by kind: db, kube, user.
+	require.Equal(t, CertificateKindDatabase, infos[0].Kind)
+	require.Equal(t, "postgres", infos[0].Name)
+	require.Equal(t, "postgres", infos[0].RouteToDatabase.ServiceName)
+	require.Equal(t, "reader", infos[0].RouteToDatabase.Username)
+	require.False(t, infos[0].DeviceVerified)
+
+	require.Equal(t, CertificateKindKubernetes, infos[1].Kind)
+	require.Equal(t, "kube1", infos[1].Name)
+	require.Equal(t, "kube1", infos[1].KubernetesCluster)
+
+	require.Equal(t, CertificateKindUser, infos[2].Kind)
+	require.Empty(t, infos[2].Name)
+	require.Equal(t, "alice", infos[2].Username)
+	require.Equal(t, "root-cluster", infos[2].TeleportCluster)
+	require.True(t, infos[2].DeviceVerified)
+	require.False(t, infos[2].NotAfter.IsZero())
+}
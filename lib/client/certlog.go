@@ -0,0 +1,197 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/tlsca"
+)
+
+// certLogFilename is the name of the issued certificate log inside the
+// client's profile directory (~/.tsh by default).
+const certLogFilename = "cert_log"
+
+// CertLogEntry records the metadata of a single user certificate at the time
+// the client received it. Entries are appended to a local log so users and
+// security teams can later reconstruct which credentials a machine held,
+// even after the certificates themselves expired or were deleted.
+type CertLogEntry struct {
+	// RecordedAt is when the client stored the certificate.
+	RecordedAt time.Time `json:"recorded_at"`
+	// SerialNumber is the decimal serial number of the TLS certificate.
+	SerialNumber string `json:"serial_number"`
+	// ProxyHost is the proxy the certificate was obtained through.
+	ProxyHost string `json:"proxy_host"`
+	// Cluster is the Teleport cluster that issued the certificate.
+	Cluster string `json:"cluster"`
+	// Username is the Teleport user the certificate was issued to.
+	Username string `json:"username"`
+	// Roles are the Teleport roles encoded in the certificate.
+	Roles []string `json:"roles,omitempty"`
+	// AccessRequests are the access request IDs applied to the certificate.
+	AccessRequests []string `json:"access_requests,omitempty"`
+	// PrivateKeyPolicy is the private key policy the certificate was issued
+	// under.
+	PrivateKeyPolicy string `json:"private_key_policy,omitempty"`
+	// NotBefore is the start of the certificate's validity window.
+	NotBefore time.Time `json:"not_before"`
+	// NotAfter is the end of the certificate's validity window.
+	NotAfter time.Time `json:"not_after"`
+}
+
+// certLogEntryFromKey builds a log entry from the key's user TLS certificate.
+// Returns nil for keys without one (e.g. database-only keys).
+func certLogEntryFromKey(key *Key, now time.Time) (*CertLogEntry, error) {
+	if len(key.TLSCert) == 0 {
+		return nil, nil
+	}
+	cert, err := tlsca.ParseCertificatePEM(key.TLSCert)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	identity, err := tlsca.FromSubject(cert.Subject, cert.NotAfter)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &CertLogEntry{
+		RecordedAt:       now.UTC(),
+		SerialNumber:     cert.SerialNumber.String(),
+		ProxyHost:        key.ProxyHost,
+		Cluster:          identity.TeleportCluster,
+		Username:         identity.Username,
+		Roles:            identity.Groups,
+		AccessRequests:   identity.ActiveRequests,
+		PrivateKeyPolicy: string(identity.PrivateKeyPolicy),
+		NotBefore:        cert.NotBefore,
+		NotAfter:         cert.NotAfter,
+	}, nil
+}
+
+// certLogPath returns the path of the issued certificate log, or an empty
+// string when the store has no on-disk key directory (in-memory stores don't
+// keep a log).
+func (s *Store) certLogPath() string {
+	fsKeyStore, ok := s.KeyStore.(*FSKeyStore)
+	if !ok {
+		return ""
+	}
+	return filepath.Join(fsKeyStore.KeyDir, certLogFilename)
+}
+
+// recordIssuedCert appends the metadata of the key's user certificate to the
+// local issued certificate log. The log is append-only: entries are never
+// rewritten or removed when keys are deleted.
+func (s *Store) recordIssuedCert(key *Key) error {
+	path := s.certLogPath()
+	if path == "" {
+		return nil
+	}
+	entry, err := certLogEntryFromKey(key, time.Now())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if entry == nil {
+		return nil
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return nil
+}
+
+// CertLogFilter selects a subset of the issued certificate log. Zero fields
+// match everything.
+type CertLogFilter struct {
+	// Since only matches certificates recorded at or after this time.
+	Since time.Time
+	// Until only matches certificates recorded before this time.
+	Until time.Time
+	// Cluster only matches certificates issued by this cluster.
+	Cluster string
+	// Username only matches certificates issued to this user.
+	Username string
+}
+
+// matches reports whether the entry passes the filter.
+func (f CertLogFilter) matches(entry CertLogEntry) bool {
+	if !f.Since.IsZero() && entry.RecordedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.RecordedAt.Before(f.Until) {
+		return false
+	}
+	if f.Cluster != "" && entry.Cluster != f.Cluster {
+		return false
+	}
+	if f.Username != "" && entry.Username != f.Username {
+		return false
+	}
+	return true
+}
+
+// QueryCertLog returns the entries of the local issued certificate log that
+// match the filter, in the order they were recorded. A store that keeps no
+// log (in-memory) or whose log was never written returns no entries.
+func (s *Store) QueryCertLog(filter CertLogFilter) ([]CertLogEntry, error) {
+	path := s.certLogPath()
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+
+	var entries []CertLogEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry CertLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// Skip lines damaged by an interrupted write, the rest of the
+			// log is still usable.
+			s.log.WithError(err).Debug("Skipping malformed certificate log entry.")
+			continue
+		}
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertLog(t *testing.T) {
+	t.Parallel()
+	a := newTestAuthority(t)
+
+	clientStore := newTestFSClientStore(t)
+
+	idx := KeyIndex{
+		ProxyHost:   "proxy.example.com",
+		ClusterName: "root",
+		Username:    "test-user",
+	}
+	otherIdx := KeyIndex{
+		ProxyHost:   "proxy.example.com",
+		ClusterName: "root",
+		Username:    "other-user",
+	}
+
+	// The log starts out empty.
+	entries, err := clientStore.QueryCertLog(CertLogFilter{})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	// Every added key leaves a log entry, including replaced keys.
+	require.NoError(t, clientStore.AddKey(a.makeSignedKey(t, idx, false)))
+	require.NoError(t, clientStore.AddKey(a.makeSignedKey(t, otherIdx, false)))
+	require.NoError(t, clientStore.AddKey(a.makeSignedKey(t, idx, false)))
+
+	entries, err = clientStore.QueryCertLog(CertLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	entry := entries[0]
+	require.Equal(t, "proxy.example.com", entry.ProxyHost)
+	require.Equal(t, "test-user", entry.Username)
+	require.Equal(t, []string{"groups"}, entry.Roles)
+	require.NotEmpty(t, entry.SerialNumber)
+	require.False(t, entry.RecordedAt.IsZero())
+	require.True(t, entry.NotAfter.After(entry.NotBefore))
+
+	// Filter by username.
+	entries, err = clientStore.QueryCertLog(CertLogFilter{Username: "other-user"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "other-user", entries[0].Username)
+
+	// Filter by time window.
+	entries, err = clientStore.QueryCertLog(CertLogFilter{Until: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+	entries, err = clientStore.QueryCertLog(CertLogFilter{Since: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	// Deleting keys leaves the log intact.
+	require.NoError(t, clientStore.DeleteKeys())
+	entries, err = clientStore.QueryCertLog(CertLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	// In-memory stores keep no log.
+	memStore := NewMemClientStore()
+	require.NoError(t, memStore.AddKey(a.makeSignedKey(t, idx, false)))
+	entries, err = memStore.QueryCertLog(CertLogFilter{})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
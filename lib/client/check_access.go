@@ -0,0 +1,98 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/services"
+)
+
+// CheckAccessParams narrows a pre-flight access check to a specific way of
+// using the resource. All fields are optional; empty fields leave the
+// corresponding dimension unchecked.
+type CheckAccessParams struct {
+	// Login is the SSH login to check against a node.
+	Login string
+	// DatabaseUser is the database user to check against a database.
+	DatabaseUser string
+	// KubeGroup is the Kubernetes group to check against a kubernetes
+	// cluster.
+	KubeGroup string
+}
+
+// CheckAccess evaluates whether the current user can access the given
+// resource, fetching the user's roles and evaluating them client-side. The
+// returned explanation reports which role allowed or denied access, powering
+// "why can't I see this resource" debugging. Per-session MFA and device
+// trust restrictions still apply at connection time and are not reflected
+// in the result.
+func (tc *TeleportClient) CheckAccess(ctx context.Context, resource services.AccessCheckable, params CheckAccessParams) (*services.AccessExplanation, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/CheckAccess",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+	clt := proxyClient.CurrentCluster()
+
+	user, err := clt.GetCurrentUser(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	roleSet, err := services.FetchRoles(user.GetRoles(), clt, user.GetTraits())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	matchers, err := accessCheckMatchers(resource, params)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	explanation, err := roleSet.ExplainAccess(resource, matchers...)
+	return explanation, trace.Wrap(err)
+}
+
+// accessCheckMatchers builds the role matchers implied by the check params
+// for the given resource.
+func accessCheckMatchers(resource services.AccessCheckable, params CheckAccessParams) ([]services.RoleMatcher, error) {
+	var matchers []services.RoleMatcher
+	if params.Login != "" {
+		matchers = append(matchers, services.NewLoginMatcher(params.Login))
+	}
+	if params.DatabaseUser != "" {
+		db, ok := resource.(types.Database)
+		if !ok {
+			return nil, trace.BadParameter("database user can only be checked against a database, got %v", resource.GetKind())
+		}
+		matchers = append(matchers, services.NewDatabaseUserMatcher(db, params.DatabaseUser))
+	}
+	if params.KubeGroup != "" {
+		matchers = append(matchers, services.NewKubeGroupMatcher(params.KubeGroup))
+	}
+	return matchers, nil
+}
@@ -29,6 +29,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gravitational/trace"
@@ -77,6 +79,12 @@ type ProxyClient struct {
 	// the proxy is connected to. This should be reused for the duration
 	// of the ProxyClient to ensure the auth server is only dialed once.
 	currentCluster auth.ClientI
+
+	// persistent is set when this client is shared across TeleportClient
+	// method calls; Close becomes a no-op so one caller's deferred Close
+	// does not tear the connection down under another. See
+	// TeleportClient.ClosePersistentConnection.
+	persistent bool
 }
 
 // NodeClient implements ssh client to a ssh node (teleport or any regular ssh node)
@@ -88,6 +96,25 @@ type NodeClient struct {
 	TC          *TeleportClient
 	OnMFA       func()
 	FIPSEnabled bool
+
+	// OnFileTransferDecision, if set, is called when a moderator approves
+	// or denies a file transfer request in a moderated session.
+	OnFileTransferDecision func(requestID string, approved bool)
+
+	// reconnector, if set, re-establishes the node connection backing
+	// forwarded ports when the node restarts. See setupPortForwardReconnect.
+	reconnector *portForwardReconnector
+
+	// fileTransferMu guards fileTransferWaiters.
+	fileTransferMu sync.Mutex
+	// fileTransferWaiters holds channels waiting for file transfer
+	// decisions, keyed by request ID.
+	fileTransferWaiters map[string]chan bool
+
+	// remoteEnvMu guards remoteEnv.
+	remoteEnvMu sync.Mutex
+	// remoteEnv caches the detected platform of the connected node.
+	remoteEnv *RemoteEnvironment
 }
 
 // ClusterName returns the name of the cluster the proxy is a member of.
@@ -95,6 +122,40 @@ func (proxy *ProxyClient) ClusterName() string {
 	return proxy.siteName
 }
 
+// GetAuthMethods returns the SSH auth methods the proxy connection was
+// established with. The returned slice is a copy, so callers can reorder or
+// extend it without affecting the proxy connection.
+func (proxy *ProxyClient) GetAuthMethods() []ssh.AuthMethod {
+	methods := make([]ssh.AuthMethod, len(proxy.authMethods))
+	copy(methods, proxy.authMethods)
+	return methods
+}
+
+// GetSessionSSHConfig returns a fresh ssh.ClientConfig populated with the
+// principal, auth methods and host key callback negotiated for the proxy
+// connection. Integrators can use it to establish sibling SSH connections
+// without replicating connectToProxy.
+func (proxy *ProxyClient) GetSessionSSHConfig() *ssh.ClientConfig {
+	return &ssh.ClientConfig{
+		User:            proxy.proxyPrincipal,
+		Auth:            proxy.GetAuthMethods(),
+		HostKeyCallback: proxy.hostKeyCallback,
+	}
+}
+
+// CloneSSHConfig returns the session's SSH config with the user replaced by
+// the given host login, matching the config the client uses when connecting
+// to nodes through this proxy. An empty login falls back to the login the
+// proxy client was created with.
+func (proxy *ProxyClient) CloneSSHConfig(hostLogin string) *ssh.ClientConfig {
+	config := proxy.GetSessionSSHConfig()
+	if hostLogin == "" {
+		hostLogin = proxy.hostLogin
+	}
+	config.User = hostLogin
+	return config
+}
+
 // GetSites returns list of the "sites" (AKA teleport clusters) connected to the proxy
 // Each site is returned as an instance of its auth server
 func (proxy *ProxyClient) GetSites(ctx context.Context) ([]types.Site, error) {
@@ -275,6 +336,10 @@ func (proxy *ProxyClient) ReissueUserCerts(ctx context.Context, cachePolicy Cert
 	)
 	defer span.End()
 
+	if err := proxy.teleportClient.injectFault(ctx, FaultPointReissue); err != nil {
+		return trace.Wrap(err)
+	}
+
 	key, err := proxy.reissueUserCerts(ctx, cachePolicy, params)
 	if err != nil {
 		return trace.Wrap(err)
@@ -394,6 +459,10 @@ func (proxy *ProxyClient) IssueUserCertsWithMFA(ctx context.Context, params Reis
 	)
 	defer span.End()
 
+	if err := proxy.teleportClient.injectFault(ctx, FaultPointReissue); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	if params.RouteToCluster == "" {
 		params.RouteToCluster = proxy.siteName
 	}
@@ -769,7 +838,7 @@ func (proxy *ProxyClient) FindNodesByFiltersForCluster(ctx context.Context, req
 		return nil, trace.Wrap(err)
 	}
 
-	resources, err := client.GetResourcesWithFilters(ctx, site, req)
+	resources, err := proxy.getResourcesWithResume(ctx, site, req)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -782,6 +851,46 @@ func (proxy *ProxyClient) FindNodesByFiltersForCluster(ctx context.Context, req
 	return servers, nil
 }
 
+// FindNodesByFiltersLimited is like FindNodesByFilters but fetches a single
+// page with the provided server-side limit instead of paging through the full
+// result set. It is meant for target resolution in very large clusters where
+// only a bounded number of matches is acceptable anyway.
+func (proxy *ProxyClient) FindNodesByFiltersLimited(ctx context.Context, req proto.ListResourcesRequest, limit int) ([]types.Server, error) {
+	ctx, span := proxy.Tracer.Start(
+		ctx,
+		"proxyClient/FindNodesByFiltersLimited",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(
+			attribute.String("resource", req.ResourceType),
+			attribute.Int("limit", limit),
+			attribute.String("predicate", req.PredicateExpression),
+			attribute.StringSlice("keywords", req.SearchKeywords),
+		),
+	)
+	defer span.End()
+
+	if limit <= 0 {
+		return nil, trace.BadParameter("limit must be positive")
+	}
+	req.ResourceType = types.KindNode
+	req.Limit = int32(limit)
+
+	site := proxy.CurrentCluster()
+	defer site.Close()
+
+	resp, err := site.ListResources(ctx, req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	servers, err := types.ResourcesWithLabels(resp.Resources).AsServers()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return servers, nil
+}
+
 // FindAppServersByFilters returns a list of application servers in the current cluster which have filters matched.
 func (proxy *ProxyClient) FindAppServersByFilters(ctx context.Context, req proto.ListResourcesRequest) ([]types.AppServer, error) {
 	ctx, span := proxy.Tracer.Start(
@@ -823,7 +932,7 @@ func (proxy *ProxyClient) FindAppServersByFiltersForCluster(ctx context.Context,
 		return nil, trace.Wrap(err)
 	}
 
-	resources, err := client.GetResourcesWithFilters(ctx, authClient, req)
+	resources, err := proxy.getResourcesWithResume(ctx, authClient, req)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -984,7 +1093,7 @@ func (proxy *ProxyClient) FindDatabaseServersByFiltersForCluster(ctx context.Con
 		return nil, trace.Wrap(err)
 	}
 
-	resources, err := client.GetResourcesWithFilters(ctx, authClient, req)
+	resources, err := proxy.getResourcesWithResume(ctx, authClient, req)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -1056,11 +1165,16 @@ func (proxy *ProxyClient) ListResources(ctx context.Context, namespace, resource
 
 	authClient := proxy.CurrentCluster()
 
-	resp, err := authClient.ListResources(ctx, proto.ListResourcesRequest{
-		Namespace:    namespace,
-		ResourceType: resource,
-		StartKey:     startKey,
-		Limit:        int32(limit),
+	var resp *types.ListResourcesResponse
+	err := proxy.teleportClient.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = authClient.ListResources(ctx, proto.ListResourcesRequest{
+			Namespace:    namespace,
+			ResourceType: resource,
+			StartKey:     startKey,
+			Limit:        int32(limit),
+		})
+		return trace.Wrap(err)
 	})
 	if err != nil {
 		return nil, "", trace.Wrap(err)
@@ -1087,6 +1201,58 @@ func (proxy *ProxyClient) CurrentCluster() auth.ClientI {
 	return sharedAuthClient{ClientI: proxy.currentCluster}
 }
 
+// RefreshCredentials reloads SSH signers and TLS certificates from the local
+// key store and rebuilds the cluster auth client with them. Long-running
+// daemons should call this after certificates are reissued (e.g. on rotation)
+// so subsequent calls through this ProxyClient use the fresh credentials
+// instead of failing with an expired certificate.
+//
+// RefreshCredentials must not be called concurrently with other methods of
+// this ProxyClient.
+func (proxy *ProxyClient) RefreshCredentials(ctx context.Context) error {
+	ctx, span := proxy.Tracer.Start(
+		ctx,
+		"proxyClient/RefreshCredentials",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	agent := proxy.localAgent()
+	if agent == nil {
+		return trace.BadParameter("no local agent to reload credentials from")
+	}
+
+	// Reload SSH signers so new SSH connections established through this
+	// ProxyClient authenticate with the refreshed certificates.
+	signers, err := agent.Signers()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(signers) == 0 {
+		return trace.NotFound("no SSH credentials found in the local key store, log in first")
+	}
+	proxy.authMethods = []ssh.AuthMethod{ssh.PublicKeys(signers...)}
+
+	// Rebuild the local cluster auth client; ConnectToCluster loads TLS
+	// credentials from the key store on every dial, so the new client picks
+	// up the refreshed certificates. The old client is closed only after the
+	// replacement was established.
+	previous := proxy.currentCluster
+	proxy.currentCluster = nil
+	clt, err := proxy.ConnectToCluster(ctx, proxy.siteName)
+	if err != nil {
+		proxy.currentCluster = previous
+		return trace.Wrap(err)
+	}
+	proxy.currentCluster = clt
+	if previous != nil {
+		if err := previous.Close(); err != nil {
+			log.WithError(err).Debug("Failed closing previous auth client after credential refresh.")
+		}
+	}
+	return nil
+}
+
 // ConnectToRootCluster connects to the auth server of the root cluster
 // via proxy. It returns connected and authenticated auth server client
 func (proxy *ProxyClient) ConnectToRootCluster(ctx context.Context) (auth.ClientI, error) {
@@ -1411,6 +1577,12 @@ type NodeDetails struct {
 	// MFACheck is optional parameter passed if MFA check was already done.
 	// It can be nil.
 	MFACheck *proto.IsMFARequiredResponse
+
+	// AuthMethods, if set, are used to authenticate to the node instead of
+	// the client's default credentials, e.g. single-use MFA-verified
+	// certificates acquired in a batch pre-phase. When set, no per-node MFA
+	// ceremony is performed.
+	AuthMethods []ssh.AuthMethod
 }
 
 // String returns a user-friendly name
@@ -1542,6 +1714,9 @@ func (proxy *ProxyClient) ConnectToNode(ctx context.Context, nodeAddress NodeDet
 		// read the stderr output from the failed SSH session and append
 		// it to the end of our own message:
 		serverErrorMsg, _ := io.ReadAll(proxyErr)
+		if lockErr, ok := lockInForceError(string(serverErrorMsg)); ok {
+			return nil, trace.Wrap(lockErr)
+		}
 		return nil, trace.ConnectionProblem(err, "failed connecting to node %v. %s",
 			nodeName(nodeAddress.Addr), serverErrorMsg)
 	}
@@ -1680,10 +1855,10 @@ func (c *NodeClient) RunInteractiveShell(ctx context.Context, mode types.Session
 
 	nodeSession, err := newSession(ctx, c, sessToJoin, env, c.TC.Stdin, c.TC.Stdout, c.TC.Stderr, c.TC.EnableEscapeSequences)
 	if err != nil {
-		return trace.Wrap(err)
+		return trace.Wrap(parseLockInForceError(err))
 	}
 
-	if err = nodeSession.runShell(ctx, mode, nil, c.TC.OnShellCreated); err != nil {
+	if err = nodeSession.runShell(ctx, mode, "", nil, c.TC.OnShellCreated); err != nil {
 		switch e := trace.Unwrap(err).(type) {
 		case *ssh.ExitError:
 			c.TC.ExitStatus = e.ExitStatus()
@@ -1736,6 +1911,16 @@ func (c *NodeClient) handleGlobalRequests(ctx context.Context, requestCh <-chan
 					log.Warnf("Unable to send event %v: %v.", string(r.Payload), err)
 					continue
 				}
+			case sshutils.FileTransferDecisionRequest:
+				if err := c.handleFileTransferDecision(r.Payload); err != nil {
+					log.Warnf("Unable to parse file transfer decision: %v.", err)
+					continue
+				}
+
+				if err := r.Reply(true, nil); err != nil {
+					log.Warnf("Unable to reply to %v request.", r.Type)
+					continue
+				}
 			default:
 				// This handles keep-alive messages and matches the behavior of OpenSSH.
 				err := r.Reply(false, nil)
@@ -1790,8 +1975,17 @@ func newClientConn(
 	}
 }
 
-// Close closes the proxy and auth clients
+// Close closes the proxy and auth clients. It is a no-op for a persistent
+// client, which is torn down via TeleportClient.ClosePersistentConnection.
 func (proxy *ProxyClient) Close() error {
+	if proxy.persistent {
+		return nil
+	}
+	return proxy.close()
+}
+
+// close closes the proxy and auth clients regardless of persistence.
+func (proxy *ProxyClient) close() error {
 	return trace.NewAggregate(proxy.Client.Close(), proxy.currentCluster.Close())
 }
 
@@ -1895,25 +2089,60 @@ func (c *NodeClient) TransferFiles(ctx context.Context, cfg *sftp.Config) error
 	)
 	defer span.End()
 
-	return trace.Wrap(cfg.TransferFiles(ctx, c.Client.Client))
+	// Count the transferred bytes through the progress stream, preserving
+	// any progress reporting the caller configured.
+	usage := &usageStream{}
+	inner := cfg.ProgressStream
+	cfg.ProgressStream = func(fileInfo os.FileInfo) io.ReadWriter {
+		var stream io.ReadWriter
+		if inner != nil {
+			stream = inner(fileInfo)
+		}
+		if m := c.TC.Metrics; m != nil {
+			stream = m.transferStream(stream)
+		}
+		usage.stream = stream
+		return usage
+	}
+
+	err := cfg.TransferFiles(ctx, c.Client.Client)
+	c.TC.Metrics.recordSFTPOperation(err)
+	c.TC.recordDataUsage(DataUsageSFTP, c.Client.RemoteAddr().String(), usage.sent.Load(), usage.received.Load())
+	return trace.Wrap(err)
 }
 
 type netDialer interface {
 	Dial(string, string) (net.Conn, error)
 }
 
-func proxyConnection(ctx context.Context, conn net.Conn, remoteAddr string, dialer netDialer) error {
+func proxyConnection(ctx context.Context, conn net.Conn, remoteNetwork, remoteAddr string, dialer netDialer, tc *TeleportClient) error {
 	defer conn.Close()
 	defer log.Debugf("Finished proxy from %v to %v.", conn.RemoteAddr(), remoteAddr)
 
-	var (
-		remoteConn net.Conn
-		err        error
-	)
+	var metrics *ClientMetrics
+	if tc != nil {
+		metrics = tc.Metrics
+	}
+	metrics.forwardStarted()
+	defer metrics.forwardDone()
+
+	var sent, received atomic.Uint64
+	defer func() {
+		tc.recordDataUsage(DataUsageForward, remoteAddr, sent.Load(), received.Load())
+	}()
+
+	// In client-side DNS resolution mode, resolve the destination hostname
+	// locally instead of passing it through to the node.
+	remoteAddr, err := tc.resolveForwardAddrForConfig(ctx, remoteAddr)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	var remoteConn net.Conn
 
 	log.Debugf("Attempting to connect proxy from %v to %v.", conn.RemoteAddr(), remoteAddr)
 	for attempt := 1; attempt <= 5; attempt++ {
-		remoteConn, err = dialer.Dial("tcp", remoteAddr)
+		remoteConn, err = dialer.Dial(remoteNetwork, remoteAddr)
 		if err != nil {
 			log.Debugf("Proxy connection attempt %v: %v.", attempt, err)
 
@@ -1943,14 +2172,18 @@ func proxyConnection(ctx context.Context, conn net.Conn, remoteAddr string, dial
 		defer conn.Close()
 		defer remoteConn.Close()
 
-		_, err := io.Copy(conn, remoteConn)
+		n, err := io.Copy(conn, remoteConn)
+		metrics.addForwardedBytes(n)
+		received.Add(uint64(n))
 		errCh <- err
 	}()
 	go func() {
 		defer conn.Close()
 		defer remoteConn.Close()
 
-		_, err := io.Copy(remoteConn, conn)
+		n, err := io.Copy(remoteConn, conn)
+		metrics.addForwardedBytes(n)
+		sent.Add(uint64(n))
 		errCh <- err
 	}()
 
@@ -1997,7 +2230,67 @@ func acceptWithContext(ctx context.Context, l net.Listener) (net.Conn, error) {
 
 // listenAndForward listens on a given socket and forwards all incoming
 // commands to the remote address through the SSH tunnel.
-func (c *NodeClient) listenAndForward(ctx context.Context, ln net.Listener, localAddr string, remoteAddr string) {
+// DialThroughNode opens a direct-tcpip channel from the node to the given
+// address and returns it as a net.Conn. It lets library users build custom
+// protocols on top of node-side connectivity without spinning up a local
+// forwarding listener first. The connection counts towards the client's port
+// forwarding metrics, if configured.
+func (c *NodeClient) DialThroughNode(ctx context.Context, network, addr string) (net.Conn, error) {
+	ctx, span := c.Tracer.Start(
+		ctx,
+		"nodeClient/DialThroughNode",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(
+			attribute.String("network", network),
+			attribute.String("addr", addr),
+		),
+	)
+	defer span.End()
+
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, trace.BadParameter("unsupported network %q: only TCP destinations can be dialed through a node", network)
+	}
+
+	conn, err := c.Client.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	if m := c.TC.Metrics; m != nil {
+		m.forwardStarted()
+		conn = &meteredConn{Conn: conn, metrics: m}
+	}
+	return conn, nil
+}
+
+// meteredConn reports the bytes moved over a dialed connection to the
+// client's port forwarding metrics, and the connection's end on Close.
+type meteredConn struct {
+	net.Conn
+	metrics   *ClientMetrics
+	closeOnce sync.Once
+}
+
+func (c *meteredConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.metrics.addForwardedBytes(int64(n))
+	return n, err
+}
+
+func (c *meteredConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.metrics.addForwardedBytes(int64(n))
+	return n, err
+}
+
+func (c *meteredConn) Close() error {
+	c.closeOnce.Do(c.metrics.forwardDone)
+	return c.Conn.Close()
+}
+
+func (c *NodeClient) listenAndForward(ctx context.Context, ln net.Listener, localAddr string, remoteNetwork, remoteAddr string) {
 	defer ln.Close()
 
 	log := log.WithField("localAddr", localAddr).WithField("remoteAddr", remoteAddr)
@@ -2017,7 +2310,7 @@ func (c *NodeClient) listenAndForward(ctx context.Context, ln net.Listener, loca
 		// Proxy the connection to the remote address.
 		go func() {
 			// `err` must be a fresh variable, hence `:=` instead of `=`.
-			if err := proxyConnection(ctx, conn, remoteAddr, c.Client); err != nil {
+			if err := proxyConnection(ctx, conn, remoteNetwork, remoteAddr, c.portForwardDialer(), c.TC); err != nil {
 				log.WithError(err).Warnf("Failed to proxy connection.")
 			}
 		}()
@@ -2026,8 +2319,9 @@ func (c *NodeClient) listenAndForward(ctx context.Context, ln net.Listener, loca
 	log.WithError(ctx.Err()).Infof("Shutting down port forwarding.")
 }
 
-// dynamicListenAndForward listens for connections, performs a SOCKS5
-// handshake, and then proxies the connection to the requested address.
+// dynamicListenAndForward listens for connections, performs a SOCKS
+// handshake (SOCKS4, SOCKS4a, and SOCKS5 are supported), and then proxies
+// the connection to the requested address.
 func (c *NodeClient) dynamicListenAndForward(ctx context.Context, ln net.Listener, localAddr string) {
 	defer ln.Close()
 
@@ -2037,7 +2331,7 @@ func (c *NodeClient) dynamicListenAndForward(ctx context.Context, ln net.Listene
 
 	for ctx.Err() == nil {
 		// Accept connection from the client. Here the client is typically
-		// something like a web browser or other SOCKS5 aware application.
+		// something like a web browser or other SOCKS aware application.
 		conn, err := acceptWithContext(ctx, ln)
 		if err != nil {
 			if ctx.Err() == nil {
@@ -2061,7 +2355,7 @@ func (c *NodeClient) dynamicListenAndForward(ctx context.Context, ln net.Listene
 		// Proxy the connection to the remote address.
 		go func() {
 			// `err` must be a fresh variable, hence `:=` instead of `=`.
-			if err := proxyConnection(ctx, conn, remoteAddr, c.Client); err != nil {
+			if err := proxyConnection(ctx, conn, "tcp", remoteAddr, c.portForwardDialer(), c.TC); err != nil {
 				log.WithError(err).Warnf("Failed to proxy connection.")
 				if err = conn.Close(); err != nil {
 					log.WithError(err).Errorf("Error closing failed proxy connection.")
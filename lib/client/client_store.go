@@ -71,6 +71,11 @@ func (s *Store) AddKey(key *Key) error {
 	if err := s.TrustedCertsStore.SaveTrustedCerts(key.ProxyHost, key.TrustedCerts); err != nil {
 		return trace.Wrap(err)
 	}
+	// Recording the certificate in the local issued certificate log is best
+	// effort and must not fail the login.
+	if err := s.recordIssuedCert(key); err != nil {
+		s.log.WithError(err).Warn("Failed to record certificate in the local certificate log.")
+	}
 	return nil
 }
 
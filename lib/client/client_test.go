@@ -98,7 +98,7 @@ func TestProxyConnection(t *testing.T) {
 	localSrv := newTestListener(t, func(con net.Conn) {
 		defer con.Close()
 
-		proxyErrCh <- proxyConnection(context.Background(), con, remoteSrv.Addr().String(), new(net.Dialer))
+		proxyErrCh <- proxyConnection(context.Background(), con, "tcp", remoteSrv.Addr().String(), new(net.Dialer), nil)
 	})
 	defer localSrv.Close()
 
@@ -178,7 +178,7 @@ func TestListenAndForwardCancel(t *testing.T) {
 		{
 			name: "listenAndForward",
 			testFun: func(client *NodeClient, ctx context.Context, listener *wrappedListener) {
-				client.listenAndForward(ctx, listener, "localAddr", "remoteAddr")
+				client.listenAndForward(ctx, listener, "localAddr", "tcp", "remoteAddr")
 			},
 		},
 		{
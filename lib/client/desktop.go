@@ -0,0 +1,118 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/session"
+)
+
+// ListWindowsDesktops returns the Windows desktops registered in the current
+// cluster that match the given filter. An empty filter matches all desktops
+// visible to the user.
+func (tc *TeleportClient) ListWindowsDesktops(ctx context.Context, filter types.WindowsDesktopFilter) ([]types.WindowsDesktop, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/ListWindowsDesktops",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	desktops, err := proxyClient.CurrentCluster().GetWindowsDesktops(ctx, filter)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return desktops, nil
+}
+
+// DesktopSessionFilter matches active desktop sessions client-side.
+type DesktopSessionFilter struct {
+	// DesktopName matches sessions on the named desktop. Empty matches all.
+	DesktopName string
+	// Login matches sessions started with the given Windows login. Empty
+	// matches all.
+	Login string
+}
+
+// Match returns true if the given session tracker passes the filter.
+func (f *DesktopSessionFilter) Match(tracker types.SessionTracker) bool {
+	if tracker.GetSessionKind() != types.WindowsDesktopSessionKind {
+		return false
+	}
+	if f.DesktopName != "" && tracker.GetDesktopName() != f.DesktopName {
+		return false
+	}
+	if f.Login != "" && tracker.GetLogin() != f.Login {
+		return false
+	}
+	return true
+}
+
+// ListActiveDesktopSessions returns the active Windows desktop sessions in
+// the current cluster that match the given filter.
+func (tc *TeleportClient) ListActiveDesktopSessions(ctx context.Context, filter DesktopSessionFilter) ([]types.SessionTracker, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/ListActiveDesktopSessions",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	trackers, err := proxyClient.CurrentCluster().GetActiveSessionTrackers(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var sessions []types.SessionTracker
+	for _, tracker := range trackers {
+		if filter.Match(tracker) {
+			sessions = append(sessions, tracker)
+		}
+	}
+	return sessions, nil
+}
+
+// DesktopConnectWebURL returns the web UI URL that opens a connection to the
+// named desktop as the given Windows login. Desktop rendering only happens
+// in the browser, so this is the deep link CLI output and bots can offer.
+func (tc *TeleportClient) DesktopConnectWebURL(cluster, desktopName, login string) string {
+	return fmt.Sprintf("https://%s/web/cluster/%s/desktops/%s/%s", tc.WebProxyHost(), cluster, desktopName, login)
+}
+
+// DesktopPlaybackWebURL returns the web UI URL for playing back the
+// recording of the given desktop session in the browser.
+func (tc *TeleportClient) DesktopPlaybackWebURL(cluster string, sid session.ID) string {
+	return fmt.Sprintf("https://%s/web/cluster/%s/session/%s?recordingType=desktop", tc.WebProxyHost(), cluster, sid)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/session"
+)
+
+func desktopTrackerForTest(t *testing.T, kind types.SessionKind, desktopName, login string) types.SessionTracker {
+	t.Helper()
+	tracker, err := types.NewSessionTracker(types.SessionTrackerSpecV1{
+		SessionID:   "00000000-0000-0000-0000-000000000001",
+		Kind:        string(kind),
+		DesktopName: desktopName,
+		Login:       login,
+	})
+	require.NoError(t, err)
+	return tracker
+}
+
+func TestDesktopSessionFilter(t *testing.T) {
+	t.Parallel()
+
+	desktop := desktopTrackerForTest(t, types.WindowsDesktopSessionKind, "win-1", "Administrator")
+	ssh := desktopTrackerForTest(t, types.SSHSessionKind, "", "root")
+
+	// Non-desktop sessions never match.
+	require.False(t, (&DesktopSessionFilter{}).Match(ssh))
+
+	// An empty filter matches any desktop session.
+	require.True(t, (&DesktopSessionFilter{}).Match(desktop))
+
+	// Desktop name and login are matched exactly.
+	require.True(t, (&DesktopSessionFilter{DesktopName: "win-1"}).Match(desktop))
+	require.False(t, (&DesktopSessionFilter{DesktopName: "win-2"}).Match(desktop))
+	require.True(t, (&DesktopSessionFilter{Login: "Administrator"}).Match(desktop))
+	require.False(t, (&DesktopSessionFilter{Login: "guest"}).Match(desktop))
+}
+
+func TestDesktopWebURLs(t *testing.T) {
+	t.Parallel()
+
+	tc := &TeleportClient{Config: Config{WebProxyAddr: "proxy.example.com:3080"}}
+	require.Equal(t,
+		"https://proxy.example.com/web/cluster/root/desktops/win-1/Administrator",
+		tc.DesktopConnectWebURL("root", "win-1", "Administrator"))
+
+	sid := session.ID("5ba70f31-64fa-4767-b879-31cbd8808063")
+	require.Equal(t,
+		"https://proxy.example.com/web/cluster/root/session/5ba70f31-64fa-4767-b879-31cbd8808063?recordingType=desktop",
+		tc.DesktopPlaybackWebURL("root", sid))
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/pem"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	devicepb "github.com/gravitational/teleport/api/gen/proto/go/teleport/devicetrust/v1"
+)
+
+// keyHasTrustedDevice reports whether the key's certificates carry trusted
+// device extensions, i.e. whether they were augmented by a successful device
+// authentication.
+func keyHasTrustedDevice(key *Key) (bool, error) {
+	ext, err := key.CertExtensions()
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	return ext.Device.DeviceID != "", nil
+}
+
+// checkTrustedDevice enforces the client-side device trust requirement: when
+// RequireTrustedDevice is set, the current credentials must carry trusted
+// device extensions before the client connects anywhere. Credentials without
+// them trigger one up-front device authentication attempt (via DeviceLogin);
+// if that fails, the operation is refused with a remediation message instead
+// of proceeding with an untrusted device. This is stricter than the cluster's
+// own device trust mode, which may permit untrusted devices.
+func (tc *TeleportClient) checkTrustedDevice(ctx context.Context) error {
+	if !tc.RequireTrustedDevice {
+		return nil
+	}
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/checkTrustedDevice",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	if tc.localAgent == nil {
+		return trace.BadParameter("cannot require a trusted device without a local agent")
+	}
+	key, err := tc.localAgent.GetKey(tc.SiteName, WithSSHCerts{})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	trusted, err := keyHasTrustedDevice(key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if trusted {
+		return nil
+	}
+
+	// The credentials don't carry device extensions yet, e.g. because the
+	// device was enrolled after login. Attempt device authentication up
+	// front; unlike AttemptDeviceLogin a failure here is fatal.
+	newCerts, err := tc.DeviceLogin(ctx, &devicepb.UserCertificates{
+		SshAuthorizedKey: key.Cert,
+	})
+	if err != nil {
+		log.WithError(err).Debug("Device Trust: up-front device authentication failed")
+		return trace.AccessDenied(
+			"a trusted device is required but this device isn't enrolled or failed to authenticate; " +
+				"enroll the device with `tsh device enroll`, then run `tsh login` again")
+	}
+
+	log.Debug("Device Trust: acquired augmented user certificates")
+	cp := *key
+	cp.Cert = newCerts.SshAuthorizedKey
+	cp.TLSCert = pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: newCerts.X509Der,
+	})
+	return trace.Wrap(tc.ActivateKey(ctx, &cp))
+}
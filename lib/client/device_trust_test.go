@@ -0,0 +1,73 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/gravitational/teleport/lib/services"
+)
+
+func TestKeyHasTrustedDevice(t *testing.T) {
+	t.Parallel()
+	a := newTestAuthority(t)
+
+	idx := KeyIndex{
+		ProxyHost:   "proxy.example.com",
+		ClusterName: "root",
+		Username:    "test-user",
+	}
+	key := a.makeSignedKey(t, idx, false)
+
+	// Plain login certificates carry no device extensions.
+	trusted, err := keyHasTrustedDevice(key)
+	require.NoError(t, err)
+	require.False(t, trusted)
+
+	// Re-sign the SSH certificate with device extensions, as device
+	// authentication would.
+	caSigner, err := ssh.ParsePrivateKey(CAPriv)
+	require.NoError(t, err)
+	cert, err := a.keygen.GenerateUserCert(services.UserCertParams{
+		CASigner:      caSigner,
+		PublicUserKey: ssh.MarshalAuthorizedKey(key.SSHPublicKey()),
+		Username:      idx.Username,
+		AllowedLogins: []string{idx.Username},
+		TTL:           time.Minute,
+		DeviceID:      "device-1",
+	})
+	require.NoError(t, err)
+	key.Cert = cert
+
+	trusted, err = keyHasTrustedDevice(key)
+	require.NoError(t, err)
+	require.True(t, trusted)
+}
+
+func TestCheckTrustedDeviceDisabled(t *testing.T) {
+	t.Parallel()
+
+	// The check is a no-op unless RequireTrustedDevice is set, even without
+	// a local agent.
+	tc := &TeleportClient{Config: Config{}}
+	require.NoError(t, tc.checkTrustedDevice(context.Background()))
+}
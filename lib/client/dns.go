@@ -0,0 +1,97 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net"
+
+	"github.com/gravitational/trace"
+)
+
+// DNSResolutionMode controls where the destination hostname of a forwarded
+// connection is resolved: on the node the connection is dialed from, or on
+// the client before the dial. Node-side resolution (the default) lets
+// internal-only names resolve through the node's resolver; client-side
+// resolution matches the behavior of tools that resolve before connecting.
+type DNSResolutionMode string
+
+const (
+	// DNSResolutionNode passes hostnames through to the node unresolved, so
+	// they resolve with the node's resolver. This is the default and the only
+	// mode in which names that exist solely in the node's network (split
+	// DNS, cluster-internal names) are reachable.
+	DNSResolutionNode DNSResolutionMode = "node"
+	// DNSResolutionClient resolves hostnames with the client's resolver
+	// before dialing through the node.
+	DNSResolutionClient DNSResolutionMode = "client"
+)
+
+// ParseDNSResolutionMode parses the user-facing name of a DNS resolution
+// mode. An empty string selects node-side resolution.
+func ParseDNSResolutionMode(s string) (DNSResolutionMode, error) {
+	switch mode := DNSResolutionMode(s); mode {
+	case DNSResolutionNode, DNSResolutionClient:
+		return mode, nil
+	case "":
+		return DNSResolutionNode, nil
+	default:
+		return DNSResolutionNode, trace.BadParameter(
+			"invalid DNS resolution mode %q, expected %q or %q", s, DNSResolutionNode, DNSResolutionClient)
+	}
+}
+
+// hostResolver is the subset of net.Resolver used to resolve forwarded
+// destinations, split out so tests can substitute a fake.
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// resolveForwardAddr resolves the host part of addr with the given resolver,
+// returning an address with the first resolved IP substituted for the
+// hostname. Addresses that already contain an IP are returned unchanged.
+func resolveForwardAddr(ctx context.Context, resolver hostResolver, addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	if net.ParseIP(host) != nil {
+		return addr, nil
+	}
+	ips, err := resolver.LookupHost(ctx, host)
+	if err != nil {
+		return "", trace.Wrap(err, "failed to resolve %v on the client", host)
+	}
+	if len(ips) == 0 {
+		return "", trace.NotFound("no addresses found for %v", host)
+	}
+	return net.JoinHostPort(ips[0], port), nil
+}
+
+// resolveForwardAddrForConfig applies the client's DNS resolution mode to a
+// forwarded destination: in node mode the address passes through unchanged,
+// in client mode the hostname is resolved locally first.
+func (tc *TeleportClient) resolveForwardAddrForConfig(ctx context.Context, addr string) (string, error) {
+	if tc == nil || tc.PortForwardDNS != DNSResolutionClient {
+		return addr, nil
+	}
+	resolved, err := resolveForwardAddr(ctx, net.DefaultResolver, addr)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver resolves hostnames from a static map.
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, trace.NotFound("no such host %v", host)
+	}
+	return ips, nil
+}
+
+func TestResolveForwardAddr(t *testing.T) {
+	t.Parallel()
+
+	resolver := fakeResolver{
+		"db.internal": {"10.0.0.5", "10.0.0.6"},
+		"ipv6.only":   {"fd00::1"},
+	}
+
+	tests := []struct {
+		name      string
+		addr      string
+		want      string
+		assertErr require.ErrorAssertionFunc
+	}{
+		{
+			name:      "hostname resolved to first address",
+			addr:      "db.internal:5432",
+			want:      "10.0.0.5:5432",
+			assertErr: require.NoError,
+		},
+		{
+			name:      "IPv6 result is bracketed",
+			addr:      "ipv6.only:443",
+			want:      "[fd00::1]:443",
+			assertErr: require.NoError,
+		},
+		{
+			name:      "IP address passes through unchanged",
+			addr:      "192.0.2.1:80",
+			want:      "192.0.2.1:80",
+			assertErr: require.NoError,
+		},
+		{
+			name: "unknown host fails",
+			addr: "missing.internal:80",
+			assertErr: func(t require.TestingT, err error, _ ...interface{}) {
+				require.True(t, trace.IsNotFound(err))
+			},
+		},
+		{
+			name:      "missing port fails",
+			addr:      "db.internal",
+			assertErr: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resolved, err := resolveForwardAddr(context.Background(), resolver, tt.addr)
+			tt.assertErr(t, err)
+			require.Equal(t, tt.want, resolved)
+		})
+	}
+}
+
+func TestParseDNSResolutionMode(t *testing.T) {
+	t.Parallel()
+
+	mode, err := ParseDNSResolutionMode("")
+	require.NoError(t, err)
+	require.Equal(t, DNSResolutionNode, mode)
+
+	mode, err = ParseDNSResolutionMode("client")
+	require.NoError(t, err)
+	require.Equal(t, DNSResolutionClient, mode)
+
+	mode, err = ParseDNSResolutionMode("node")
+	require.NoError(t, err)
+	require.Equal(t, DNSResolutionNode, mode)
+
+	_, err = ParseDNSResolutionMode("remote")
+	require.True(t, trace.IsBadParameter(err))
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"os"
+
+	"github.com/gravitational/trace"
+
+	wancli "github.com/gravitational/teleport/lib/auth/webauthncli"
+)
+
+// Environment variables recognized by Config.ApplyEnvironment. They match
+// the variables tsh honors, so binaries embedding lib/client behave the same
+// as tsh in containerized environments.
+const (
+	// ProxyEnvVar overrides the proxy address.
+	ProxyEnvVar = "TELEPORT_PROXY"
+	// UserEnvVar overrides the Teleport username.
+	UserEnvVar = "TELEPORT_USER"
+	// ClusterEnvVar overrides the cluster to connect to.
+	ClusterEnvVar = "TELEPORT_CLUSTER"
+	// SiteEnvVar is the deprecated spelling of ClusterEnvVar, using the older
+	// "site" terminology. ClusterEnvVar takes precedence when both are set.
+	SiteEnvVar = "TELEPORT_SITE"
+	// LoginEnvVar overrides the remote host login.
+	LoginEnvVar = "TELEPORT_LOGIN"
+	// AuthConnectorEnvVar overrides the authentication connector to use.
+	AuthConnectorEnvVar = "TELEPORT_AUTH"
+	// IdentityFileEnvVar points at an identity file to use instead of the
+	// client store.
+	IdentityFileEnvVar = "TELEPORT_IDENTITY_FILE"
+	// AddKeysToAgentEnvVar overrides how keys are handed to the SSH agent.
+	AddKeysToAgentEnvVar = "TELEPORT_ADD_KEYS_TO_AGENT"
+	// MFAModeEnvVar overrides the preferred MFA mode. Valid values are
+	// "auto", "platform", "cross-platform" and "otp".
+	MFAModeEnvVar = "TELEPORT_MFA_MODE"
+)
+
+// ApplyEnvironment overlays the TELEPORT_* environment variables recognized
+// by tsh onto the configuration. Values already set in the Config win, to
+// keep the usual flag-over-environment precedence, so call this after
+// explicit configuration is applied.
+func (c *Config) ApplyEnvironment() error {
+	return c.applyEnvironment(os.Getenv)
+}
+
+// applyEnvironment implements ApplyEnvironment with an injectable lookup
+// function for tests.
+func (c *Config) applyEnvironment(getEnv func(string) string) error {
+	if proxy := getEnv(ProxyEnvVar); proxy != "" && c.WebProxyAddr == "" {
+		if err := c.ParseProxyHost(proxy); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if user := getEnv(UserEnvVar); user != "" && c.Username == "" {
+		c.Username = user
+	}
+	if c.SiteName == "" {
+		if cluster := getEnv(ClusterEnvVar); cluster != "" {
+			c.SiteName = cluster
+		} else if site := getEnv(SiteEnvVar); site != "" {
+			c.SiteName = site
+		}
+	}
+	if login := getEnv(LoginEnvVar); login != "" && c.HostLogin == "" {
+		c.HostLogin = login
+		c.ExplicitHostLogin = true
+	}
+	if connector := getEnv(AuthConnectorEnvVar); connector != "" && c.AuthConnector == "" {
+		c.AuthConnector = connector
+	}
+	if identity := getEnv(IdentityFileEnvVar); identity != "" && c.IdentityFile == "" {
+		c.IdentityFile = identity
+	}
+	if keysOption := getEnv(AddKeysToAgentEnvVar); keysOption != "" && c.AddKeysToAgent == "" {
+		if err := ValidateAgentKeyOption(keysOption); err != nil {
+			return trace.Wrap(err)
+		}
+		c.AddKeysToAgent = keysOption
+	}
+	if mfaMode := getEnv(MFAModeEnvVar); mfaMode != "" {
+		if mfaMode == "otp" {
+			c.PreferOTP = true
+		} else {
+			attachment, err := parseMFAMode(mfaMode)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			if c.AuthenticatorAttachment == wancli.AttachmentAuto {
+				c.AuthenticatorAttachment = attachment
+			}
+		}
+	}
+	return nil
+}
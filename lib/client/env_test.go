@@ -0,0 +1,111 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+
+	wancli "github.com/gravitational/teleport/lib/auth/webauthncli"
+)
+
+func TestApplyEnvironment(t *testing.T) {
+	t.Parallel()
+
+	envLookup := func(env map[string]string) func(string) string {
+		return func(key string) string { return env[key] }
+	}
+
+	t.Run("overlays unset fields", func(t *testing.T) {
+		t.Parallel()
+		var cfg Config
+		err := cfg.applyEnvironment(envLookup(map[string]string{
+			ProxyEnvVar:          "proxy.example.com:3080",
+			UserEnvVar:           "alice",
+			ClusterEnvVar:        "leaf",
+			LoginEnvVar:          "root",
+			AuthConnectorEnvVar:  "github",
+			IdentityFileEnvVar:   "/tmp/id",
+			AddKeysToAgentEnvVar: AddKeysToAgentNo,
+			MFAModeEnvVar:        "platform",
+		}))
+		require.NoError(t, err)
+		require.Equal(t, "proxy.example.com:3080", cfg.WebProxyAddr)
+		require.Equal(t, "alice", cfg.Username)
+		require.Equal(t, "leaf", cfg.SiteName)
+		require.Equal(t, "root", cfg.HostLogin)
+		require.True(t, cfg.ExplicitHostLogin)
+		require.Equal(t, "github", cfg.AuthConnector)
+		require.Equal(t, "/tmp/id", cfg.IdentityFile)
+		require.Equal(t, AddKeysToAgentNo, cfg.AddKeysToAgent)
+		require.Equal(t, wancli.AttachmentPlatform, cfg.AuthenticatorAttachment)
+	})
+
+	t.Run("explicit configuration wins", func(t *testing.T) {
+		t.Parallel()
+		cfg := Config{
+			Username: "bob",
+			SiteName: "root",
+		}
+		err := cfg.applyEnvironment(envLookup(map[string]string{
+			UserEnvVar:    "alice",
+			ClusterEnvVar: "leaf",
+		}))
+		require.NoError(t, err)
+		require.Equal(t, "bob", cfg.Username)
+		require.Equal(t, "root", cfg.SiteName)
+	})
+
+	t.Run("cluster is preferred over deprecated site", func(t *testing.T) {
+		t.Parallel()
+		var cfg Config
+		err := cfg.applyEnvironment(envLookup(map[string]string{
+			ClusterEnvVar: "leaf",
+			SiteEnvVar:    "old-leaf",
+		}))
+		require.NoError(t, err)
+		require.Equal(t, "leaf", cfg.SiteName)
+	})
+
+	t.Run("otp mode sets the OTP preference", func(t *testing.T) {
+		t.Parallel()
+		var cfg Config
+		err := cfg.applyEnvironment(envLookup(map[string]string{
+			MFAModeEnvVar: "otp",
+		}))
+		require.NoError(t, err)
+		require.True(t, cfg.PreferOTP)
+		require.Equal(t, wancli.AttachmentAuto, cfg.AuthenticatorAttachment)
+	})
+
+	t.Run("invalid values are rejected", func(t *testing.T) {
+		t.Parallel()
+		var cfg Config
+		err := cfg.applyEnvironment(envLookup(map[string]string{
+			AddKeysToAgentEnvVar: "sometimes",
+		}))
+		require.True(t, trace.IsBadParameter(err))
+
+		cfg = Config{}
+		err = cfg.applyEnvironment(envLookup(map[string]string{
+			MFAModeEnvVar: "telepathy",
+		}))
+		require.True(t, trace.IsBadParameter(err))
+	})
+}
@@ -29,7 +29,10 @@ const (
 
 	// Note: on a raw terminal, "\r\n" is needed to move a cursor to the start
 	// of next line.
-	helpText = "\r\ntsh escape characters:\r\n  ~? - display a list of escape characters\r\n  ~. - disconnect\r\n"
+	helpText = "\r\ntsh escape characters:\r\n  ~? - display a list of escape characters\r\n  ~. - disconnect\r\n  ~p - toggle escape sequence pass-through\r\n"
+
+	passThroughOnText  = "\r\nescape sequence pass-through enabled, '~.' is forwarded to the remote end\r\n"
+	passThroughOffText = "\r\nescape sequence pass-through disabled\r\n"
 )
 
 var (
@@ -52,11 +55,16 @@ type Reader struct {
 	onDisconnect func(error)
 	bufferLimit  int
 
-	// cond protects buf and err and also announces to blocked readers that
-	// more data is available.
+	// cond protects buf, err and passThrough and also announces to blocked
+	// readers that more data is available.
 	cond sync.Cond
 	buf  []byte
 	err  error
+	// passThrough forwards the disconnect sequence "~." to the remote end
+	// literally instead of intercepting it, for TUI apps that need the
+	// input. "~?" and "~p" remain intercepted so the user can view help and
+	// toggle back.
+	passThrough bool
 }
 
 // NewReader creates a new Reader to catch escape sequences from 'in'.
@@ -150,9 +158,22 @@ func (r *Reader) runReads() {
 				newLine = false
 			case '.':
 				if escape {
-					// Complete disconnect sequence.
-					r.setErr(ErrDisconnect)
-					return
+					if !r.PassThrough() {
+						// Complete disconnect sequence.
+						r.setErr(ErrDisconnect)
+						return
+					}
+					// Pass-through: forward the previously suppressed '~'
+					// and the '.' to the remote end.
+					writeBuf = append(writeBuf, '~')
+				}
+				newLine, escape = false, false
+			case 'p':
+				if escape {
+					// Complete pass-through toggle sequence.
+					r.togglePassThrough()
+					newLine, escape = false, false
+					continue inner
 				}
 				newLine = false
 			default:
@@ -218,3 +239,31 @@ func (r *Reader) setErr(err error) {
 func (r *Reader) printHelp() {
 	r.out.Write([]byte(helpText))
 }
+
+// SetPassThrough enables or disables escape sequence pass-through. When
+// enabled, the disconnect sequence "~." is forwarded literally instead of
+// intercepted; "~?" and "~p" remain intercepted.
+func (r *Reader) SetPassThrough(enabled bool) {
+	r.cond.L.Lock()
+	r.passThrough = enabled
+	r.cond.L.Unlock()
+}
+
+// PassThrough reports whether escape sequence pass-through is enabled.
+func (r *Reader) PassThrough() bool {
+	r.cond.L.Lock()
+	defer r.cond.L.Unlock()
+	return r.passThrough
+}
+
+func (r *Reader) togglePassThrough() {
+	r.cond.L.Lock()
+	r.passThrough = !r.passThrough
+	enabled := r.passThrough
+	r.cond.L.Unlock()
+	if enabled {
+		r.out.Write([]byte(passThroughOnText))
+	} else {
+		r.out.Write([]byte(passThroughOffText))
+	}
+}
@@ -198,3 +198,59 @@ func (r *mockReader) Read(buf []byte) (int, error) {
 	copy(buf, chunk)
 	return len(chunk), nil
 }
+
+func runPassThroughCase(t *testing.T, tc readerTestCase) {
+	in := &mockReader{chunks: tc.inChunks, finalErr: tc.inErr}
+	helpOut := new(bytes.Buffer)
+	out := new(bytes.Buffer)
+	var disconnectErr error
+
+	r := newUnstartedReader(in, helpOut, func(err error) {
+		disconnectErr = err
+	})
+	r.SetPassThrough(true)
+	go r.runReads()
+
+	_, err := io.Copy(out, r)
+	require.Equal(t, err, tc.wantReadErr)
+	require.Equal(t, disconnectErr, tc.wantDisconnectErr)
+	require.Equal(t, out.String(), tc.wantOut)
+	require.Equal(t, helpOut.String(), tc.wantHelp)
+}
+
+func TestEscapePassThrough(t *testing.T) {
+	t.Log("disconnect sequence is forwarded literally")
+	runPassThroughCase(t, readerTestCase{
+		inChunks: [][]byte{[]byte("hello\r~.world")},
+		wantOut:  "hello\r~.world",
+	})
+
+	t.Log("help sequence remains intercepted")
+	runPassThroughCase(t, readerTestCase{
+		inChunks: [][]byte{[]byte("hello\r~?world")},
+		wantOut:  "hello\rworld",
+		wantHelp: helpText,
+	})
+
+	t.Log("toggle sequence disables pass-through again")
+	runPassThroughCase(t, readerTestCase{
+		inChunks: [][]byte{
+			[]byte("hello\r"),
+			[]byte("~p\r"),
+			[]byte("~.world"),
+		},
+		wantOut:           "hello\r\r",
+		wantHelp:          passThroughOffText,
+		wantReadErr:       ErrDisconnect,
+		wantDisconnectErr: ErrDisconnect,
+	})
+}
+
+func TestEscapePassThroughToggle(t *testing.T) {
+	t.Log("toggle sequence enables pass-through at runtime")
+	runCase(t, readerTestCase{
+		inChunks: [][]byte{[]byte("hello\r~p\r~.world")},
+		wantOut:  "hello\r\r~.world",
+		wantHelp: passThroughOnText,
+	})
+}
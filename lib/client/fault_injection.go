@@ -0,0 +1,57 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import "context"
+
+// FaultPoint names a point in the client where faults can be injected.
+type FaultPoint string
+
+const (
+	// FaultPointDial is reached before dialing the proxy.
+	FaultPointDial FaultPoint = "dial"
+	// FaultPointSSHHandshake is reached before the SSH handshake with the
+	// proxy.
+	FaultPointSSHHandshake FaultPoint = "ssh-handshake"
+	// FaultPointMFA is reached before prompting for an MFA challenge.
+	FaultPointMFA FaultPoint = "mfa"
+	// FaultPointReissue is reached before reissuing user certificates.
+	FaultPointReissue FaultPoint = "reissue"
+	// FaultPointPing is reached before pinging the proxy.
+	FaultPointPing FaultPoint = "ping"
+)
+
+// FaultInjector is consulted at well-defined points in the client, allowing
+// tests and staging environments to deterministically inject faults into
+// tooling built on this library without real network manipulation.
+// Returning a non-nil error aborts the operation with that error; an
+// implementation may also sleep before returning nil to simulate slowness.
+//
+// Implementations must be safe for concurrent use.
+type FaultInjector interface {
+	// Fault is invoked when execution reaches the given injection point.
+	Fault(ctx context.Context, point FaultPoint) error
+}
+
+// injectFault consults the configured fault injector, if any, at the given
+// injection point.
+func (tc *TeleportClient) injectFault(ctx context.Context, point FaultPoint) error {
+	if tc.FaultInjector == nil {
+		return nil
+	}
+	return tc.FaultInjector.Fault(ctx, point)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/gravitational/teleport/api/client/proto"
+)
+
+// ClusterFeatures describes the features the cluster's auth server is
+// licensed to serve, so client tooling can hide affordances the cluster
+// cannot honor instead of failing at call time.
+type ClusterFeatures struct {
+	// Kubernetes reports whether Kubernetes access is available.
+	Kubernetes bool `json:"kubernetes"`
+	// Apps reports whether application access is available.
+	Apps bool `json:"apps"`
+	// Databases reports whether database access is available.
+	Databases bool `json:"databases"`
+	// Desktops reports whether Windows desktop access is available.
+	Desktops bool `json:"desktops"`
+	// OIDC reports whether OIDC connectors are available.
+	OIDC bool `json:"oidc"`
+	// SAML reports whether SAML connectors are available.
+	SAML bool `json:"saml"`
+	// AccessControls reports whether advanced access controls are available.
+	AccessControls bool `json:"access_controls"`
+	// AdvancedAccessWorkflows reports whether advanced access workflows,
+	// such as access requests and moderated sessions, are available.
+	AdvancedAccessWorkflows bool `json:"advanced_access_workflows"`
+	// Cloud reports whether the cluster is a Teleport Cloud tenant.
+	Cloud bool `json:"cloud"`
+	// HSM reports whether HSM-backed keys are supported.
+	HSM bool `json:"hsm"`
+	// RecoveryCodes reports whether account recovery codes are available.
+	RecoveryCodes bool `json:"recovery_codes"`
+}
+
+// SupportsAccessRequests returns true if the cluster can serve access
+// requests.
+func (f ClusterFeatures) SupportsAccessRequests() bool {
+	return f.AdvancedAccessWorkflows
+}
+
+// SupportsModeratedSessions returns true if the cluster can serve moderated
+// sessions.
+func (f ClusterFeatures) SupportsModeratedSessions() bool {
+	return f.AdvancedAccessWorkflows
+}
+
+// clusterFeaturesFromProto converts the auth server's feature set into a
+// ClusterFeatures struct. A nil feature set yields the zero value, meaning
+// no gated features are available.
+func clusterFeaturesFromProto(features *proto.Features) ClusterFeatures {
+	if features == nil {
+		return ClusterFeatures{}
+	}
+	return ClusterFeatures{
+		Kubernetes:              features.Kubernetes,
+		Apps:                    features.App,
+		Databases:               features.DB,
+		Desktops:                features.Desktop,
+		OIDC:                    features.OIDC,
+		SAML:                    features.SAML,
+		AccessControls:          features.AccessControls,
+		AdvancedAccessWorkflows: features.AdvancedAccessWorkflows,
+		Cloud:                   features.Cloud,
+		HSM:                     features.HSM,
+		RecoveryCodes:           features.RecoveryCodes,
+	}
+}
+
+// GetClusterFeatures returns the features the current cluster's auth server
+// supports.
+func (tc *TeleportClient) GetClusterFeatures(ctx context.Context) (ClusterFeatures, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/GetClusterFeatures",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return ClusterFeatures{}, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	resp, err := proxyClient.CurrentCluster().Ping(ctx)
+	if err != nil {
+		return ClusterFeatures{}, trace.Wrap(err)
+	}
+	return clusterFeaturesFromProto(resp.ServerFeatures), nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/client/proto"
+)
+
+func TestClusterFeaturesFromProto(t *testing.T) {
+	t.Parallel()
+
+	// A missing feature set means nothing gated is available.
+	features := clusterFeaturesFromProto(nil)
+	require.Equal(t, ClusterFeatures{}, features)
+	require.False(t, features.SupportsAccessRequests())
+	require.False(t, features.SupportsModeratedSessions())
+
+	features = clusterFeaturesFromProto(&proto.Features{
+		Kubernetes:              true,
+		App:                     true,
+		DB:                      true,
+		Desktop:                 true,
+		AdvancedAccessWorkflows: true,
+	})
+	require.Equal(t, ClusterFeatures{
+		Kubernetes:              true,
+		Apps:                    true,
+		Databases:               true,
+		Desktops:                true,
+		AdvancedAccessWorkflows: true,
+	}, features)
+	require.True(t, features.SupportsAccessRequests())
+	require.True(t, features.SupportsModeratedSessions())
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/gravitational/teleport/lib/sshutils"
+	"github.com/gravitational/teleport/lib/sshutils/sftp"
+)
+
+// RequestFileTransfer asks the session moderators to approve a file transfer
+// and blocks until a decision arrives or the context is done. It returns nil
+// when the transfer was approved and trace.AccessDenied when it was denied.
+// The OnFileTransferDecision callback, if set, is additionally invoked when
+// the decision arrives.
+func (c *NodeClient) RequestFileTransfer(ctx context.Context, req sshutils.FileTransferReq) error {
+	ctx, span := c.Tracer.Start(
+		ctx,
+		"nodeClient/RequestFileTransfer",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	if req.RequestID == "" {
+		req.RequestID = uuid.NewString()
+	}
+
+	decisionCh := c.registerFileTransferWaiter(req.RequestID)
+	defer c.removeFileTransferWaiter(req.RequestID)
+
+	ok, _, err := c.Client.SendRequest(ctx, sshutils.FileTransferRequest, true, ssh.Marshal(req))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !ok {
+		return trace.NotImplemented("server does not support file transfer requests")
+	}
+
+	select {
+	case approved := <-decisionCh:
+		if !approved {
+			return trace.AccessDenied("file transfer request %v was denied", req.RequestID)
+		}
+		return nil
+	case <-ctx.Done():
+		return trace.Wrap(ctx.Err())
+	}
+}
+
+// TransferFilesWithApproval requests approval for the transfer from the
+// session moderators and, once granted, runs the transfer automatically.
+func (c *NodeClient) TransferFilesWithApproval(ctx context.Context, cfg *sftp.Config, req sshutils.FileTransferReq) error {
+	if err := c.RequestFileTransfer(ctx, req); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(c.TransferFiles(ctx, cfg))
+}
+
+// registerFileTransferWaiter registers a channel that receives the moderator
+// decision for the given request ID.
+func (c *NodeClient) registerFileTransferWaiter(requestID string) chan bool {
+	c.fileTransferMu.Lock()
+	defer c.fileTransferMu.Unlock()
+
+	if c.fileTransferWaiters == nil {
+		c.fileTransferWaiters = make(map[string]chan bool)
+	}
+	// Buffered so a decision arriving after the waiter gave up does not
+	// block the global request handler.
+	ch := make(chan bool, 1)
+	c.fileTransferWaiters[requestID] = ch
+	return ch
+}
+
+// removeFileTransferWaiter unregisters the waiter for the given request ID.
+func (c *NodeClient) removeFileTransferWaiter(requestID string) {
+	c.fileTransferMu.Lock()
+	defer c.fileTransferMu.Unlock()
+	delete(c.fileTransferWaiters, requestID)
+}
+
+// handleFileTransferDecision parses a file transfer decision request and
+// delivers it to the matching waiter and the OnFileTransferDecision callback.
+func (c *NodeClient) handleFileTransferDecision(payload []byte) error {
+	var decision sshutils.FileTransferDecisionReq
+	if err := ssh.Unmarshal(payload, &decision); err != nil {
+		return trace.Wrap(err)
+	}
+
+	c.fileTransferMu.Lock()
+	ch, ok := c.fileTransferWaiters[decision.RequestID]
+	c.fileTransferMu.Unlock()
+	if ok {
+		ch <- decision.Approved
+	}
+
+	if c.OnFileTransferDecision != nil {
+		c.OnFileTransferDecision(decision.RequestID, decision.Approved)
+	}
+	return nil
+}
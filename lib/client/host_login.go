@@ -0,0 +1,122 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/exp/slices"
+)
+
+// HostLoginSelectorFunc lets callers resolve an ambiguous host login choice,
+// e.g. by prompting the user. It receives the sorted candidate logins and
+// returns the one to use.
+type HostLoginSelectorFunc func(ctx context.Context, logins []string) (string, error)
+
+// CertLogins returns the host logins the user's SSH certificate permits,
+// sorted, with teleport-internal principals filtered out.
+func (tc *TeleportClient) CertLogins() ([]string, error) {
+	key, err := tc.LocalAgent().GetCoreKey()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	principals, err := key.CertPrincipals()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var logins []string
+	for _, principal := range principals {
+		// Skip internal principals such as the session join principal,
+		// which are not real host logins.
+		if strings.HasPrefix(principal, "-teleport") {
+			continue
+		}
+		logins = append(logins, principal)
+	}
+	sort.Strings(logins)
+	return logins, nil
+}
+
+// SelectHostLogin picks the host login to use for a target node. The
+// certificate principals are intersected with the node's allowed logins when
+// known (pass nil if they are not), then:
+//
+//   - an explicitly configured HostLogin wins, so command line flags keep
+//     their meaning;
+//   - a single remaining candidate is picked outright;
+//   - the local username is preferred when it is among the candidates, to
+//     match the historical default;
+//   - otherwise the HostLoginSelector callback is consulted, and if none is
+//     set the ambiguity is returned as an error instead of guessing.
+func (tc *TeleportClient) SelectHostLogin(ctx context.Context, nodeLogins []string) (string, error) {
+	if tc.ExplicitHostLogin {
+		return tc.HostLogin, nil
+	}
+
+	certLogins, err := tc.CertLogins()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	localUser, err := Username()
+	if err != nil {
+		localUser = ""
+	}
+	login, err := selectHostLogin(ctx, certLogins, nodeLogins, localUser, tc.HostLoginSelector)
+	return login, trace.Wrap(err)
+}
+
+// selectHostLogin implements the host login decision for SelectHostLogin.
+func selectHostLogin(ctx context.Context, certLogins, nodeLogins []string, localUser string, selector HostLoginSelectorFunc) (string, error) {
+	candidates := certLogins
+	if len(nodeLogins) > 0 {
+		var intersection []string
+		for _, login := range candidates {
+			if slices.Contains(nodeLogins, login) {
+				intersection = append(intersection, login)
+			}
+		}
+		candidates = intersection
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", trace.BadParameter("none of your certificate's logins are allowed on the target node, ask your administrator for access")
+	case 1:
+		return candidates[0], nil
+	}
+
+	if localUser != "" && slices.Contains(candidates, localUser) {
+		return localUser, nil
+	}
+
+	if selector != nil {
+		login, err := selector(ctx, candidates)
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		if !slices.Contains(candidates, login) {
+			return "", trace.BadParameter("login %q is not among the allowed logins %v", login, candidates)
+		}
+		return login, nil
+	}
+
+	return "", trace.BadParameter("multiple logins are available (%v), specify one with --login", strings.Join(candidates, ", "))
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectHostLogin(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	pick := func(login string) HostLoginSelectorFunc {
+		return func(context.Context, []string) (string, error) {
+			return login, nil
+		}
+	}
+
+	tests := []struct {
+		name       string
+		certLogins []string
+		nodeLogins []string
+		localUser  string
+		selector   HostLoginSelectorFunc
+		wantLogin  string
+		wantErr    string
+	}{
+		{
+			name:       "single candidate picked outright",
+			certLogins: []string{"alice"},
+			wantLogin:  "alice",
+		},
+		{
+			name:       "intersection with node logins",
+			certLogins: []string{"alice", "root", "ubuntu"},
+			nodeLogins: []string{"ubuntu", "ec2-user"},
+			wantLogin:  "ubuntu",
+		},
+		{
+			name:       "local username preferred among candidates",
+			certLogins: []string{"alice", "root"},
+			localUser:  "alice",
+			wantLogin:  "alice",
+		},
+		{
+			name:       "selector resolves ambiguity",
+			certLogins: []string{"alice", "root"},
+			selector:   pick("root"),
+			wantLogin:  "root",
+		},
+		{
+			name:       "selector cannot pick an unlisted login",
+			certLogins: []string{"alice", "root"},
+			selector:   pick("admin"),
+			wantErr:    "not among the allowed logins",
+		},
+		{
+			name:       "empty intersection is an error",
+			certLogins: []string{"alice"},
+			nodeLogins: []string{"root"},
+			wantErr:    "none of your certificate's logins",
+		},
+		{
+			name:       "ambiguity without a selector is an error",
+			certLogins: []string{"alice", "root"},
+			wantErr:    "multiple logins are available",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			login, err := selectHostLogin(ctx, tt.certLogins, tt.nodeLogins, tt.localUser, tt.selector)
+			if tt.wantErr != "" {
+				require.True(t, trace.IsBadParameter(err))
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantLogin, login)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"net"
+	"sync"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+// HostKeyDecision records the outcome of a single callback in a
+// HostKeyCallbackChain evaluation.
+type HostKeyDecision struct {
+	// Callback is the name of the callback that produced the decision.
+	Callback string
+	// Host is the host address the key was presented for.
+	Host string
+	// Fingerprint is the SHA256 fingerprint of the presented host key.
+	Fingerprint string
+	// Accepted indicates whether the callback accepted the key.
+	Accepted bool
+	// Err is the rejection error, nil when the key was accepted.
+	Err error
+}
+
+// NamedHostKeyCallback pairs an ssh.HostKeyCallback with a name used in the
+// decision log.
+type NamedHostKeyCallback struct {
+	// Name identifies the callback in decision logs.
+	Name string
+	// Callback validates the presented host key.
+	Callback ssh.HostKeyCallback
+}
+
+// HostKeyCallbackChain composes multiple host key callbacks (e.g. a
+// known-hosts check, a CA check and a custom corporate validator) into a
+// single ssh.HostKeyCallback. The key is accepted as soon as any callback in
+// the chain accepts it; if every callback rejects it, the aggregated
+// rejection errors are returned. Every individual decision is logged and,
+// when OnDecision is set, reported to it, so integrators can audit which
+// validator accepted or rejected a key.
+type HostKeyCallbackChain struct {
+	// OnDecision, when set, receives every decision made by the chain.
+	OnDecision func(HostKeyDecision)
+
+	mu        sync.RWMutex
+	callbacks []NamedHostKeyCallback
+}
+
+// NewHostKeyCallbackChain returns a chain evaluating the given callbacks in
+// order.
+func NewHostKeyCallbackChain(callbacks ...NamedHostKeyCallback) *HostKeyCallbackChain {
+	return &HostKeyCallbackChain{callbacks: callbacks}
+}
+
+// Add appends a named callback to the chain.
+func (c *HostKeyCallbackChain) Add(name string, callback ssh.HostKeyCallback) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.callbacks = append(c.callbacks, NamedHostKeyCallback{Name: name, Callback: callback})
+}
+
+// Callback is the ssh.HostKeyCallback evaluating the chain.
+func (c *HostKeyCallbackChain) Callback(host string, remote net.Addr, key ssh.PublicKey) error {
+	c.mu.RLock()
+	callbacks := c.callbacks
+	c.mu.RUnlock()
+
+	if len(callbacks) == 0 {
+		return trace.BadParameter("host key verification chain for %v has no callbacks", host)
+	}
+
+	fingerprint := ssh.FingerprintSHA256(key)
+	var errs []error
+	for _, cb := range callbacks {
+		err := cb.Callback(host, remote, key)
+		c.recordDecision(HostKeyDecision{
+			Callback:    cb.Name,
+			Host:        host,
+			Fingerprint: fingerprint,
+			Accepted:    err == nil,
+			Err:         err,
+		})
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, trace.Wrap(err, "callback %q rejected host key", cb.Name))
+	}
+	return trace.NewAggregate(errs...)
+}
+
+func (c *HostKeyCallbackChain) recordDecision(decision HostKeyDecision) {
+	logger := log.WithFields(logrus.Fields{
+		"callback":    decision.Callback,
+		"host":        decision.Host,
+		"fingerprint": decision.Fingerprint,
+	})
+	if decision.Accepted {
+		logger.Debug("Host key accepted.")
+	} else {
+		logger.WithError(decision.Err).Debug("Host key rejected.")
+	}
+	if c.OnDecision != nil {
+		c.OnDecision(decision)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func hostKeyForTest(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return sshPub
+}
+
+func TestHostKeyCallbackChain(t *testing.T) {
+	t.Parallel()
+
+	key := hostKeyForTest(t)
+	accept := func(host string, remote net.Addr, key ssh.PublicKey) error { return nil }
+	reject := func(host string, remote net.Addr, key ssh.PublicKey) error {
+		return trace.AccessDenied("unknown host key")
+	}
+
+	t.Run("empty chain rejects", func(t *testing.T) {
+		chain := NewHostKeyCallbackChain()
+		err := chain.Callback("node.example.com:3022", nil, key)
+		require.True(t, trace.IsBadParameter(err))
+	})
+
+	t.Run("first accept wins", func(t *testing.T) {
+		secondCalled := false
+		chain := NewHostKeyCallbackChain(
+			NamedHostKeyCallback{Name: "first", Callback: accept},
+			NamedHostKeyCallback{Name: "second", Callback: func(host string, remote net.Addr, key ssh.PublicKey) error {
+				secondCalled = true
+				return nil
+			}},
+		)
+		require.NoError(t, chain.Callback("node.example.com:3022", nil, key))
+		require.False(t, secondCalled)
+	})
+
+	t.Run("falls through rejections", func(t *testing.T) {
+		chain := NewHostKeyCallbackChain(
+			NamedHostKeyCallback{Name: "known-hosts", Callback: reject},
+			NamedHostKeyCallback{Name: "corporate", Callback: accept},
+		)
+		require.NoError(t, chain.Callback("node.example.com:3022", nil, key))
+	})
+
+	t.Run("aggregates rejections and records decisions", func(t *testing.T) {
+		var decisions []HostKeyDecision
+		chain := NewHostKeyCallbackChain(
+			NamedHostKeyCallback{Name: "known-hosts", Callback: reject},
+			NamedHostKeyCallback{Name: "corporate", Callback: reject},
+		)
+		chain.OnDecision = func(d HostKeyDecision) { decisions = append(decisions, d) }
+
+		err := chain.Callback("node.example.com:3022", nil, key)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "known-hosts")
+		require.Contains(t, err.Error(), "corporate")
+
+		require.Len(t, decisions, 2)
+		for i, name := range []string{"known-hosts", "corporate"} {
+			require.Equal(t, name, decisions[i].Callback)
+			require.Equal(t, "node.example.com:3022", decisions[i].Host)
+			require.Equal(t, ssh.FingerprintSHA256(key), decisions[i].Fingerprint)
+			require.False(t, decisions[i].Accepted)
+			require.Error(t, decisions[i].Err)
+		}
+	})
+
+	t.Run("decision log records acceptance", func(t *testing.T) {
+		var decisions []HostKeyDecision
+		chain := NewHostKeyCallbackChain(NamedHostKeyCallback{Name: "ca", Callback: accept})
+		chain.OnDecision = func(d HostKeyDecision) { decisions = append(decisions, d) }
+
+		require.NoError(t, chain.Callback("node.example.com:3022", nil, key))
+		require.Len(t, decisions, 1)
+		require.True(t, decisions[0].Accepted)
+		require.NoError(t, decisions[0].Err)
+	})
+}
+
+func TestHostKeyCallbackChainAdd(t *testing.T) {
+	t.Parallel()
+
+	key := hostKeyForTest(t)
+	chain := NewHostKeyCallbackChain()
+	chain.Add("accept-all", func(host string, remote net.Addr, key ssh.PublicKey) error { return nil })
+	require.NoError(t, chain.Callback("node.example.com:3022", nil, key))
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/ssh"
+)
+
+// HostKeyAcceptance records a trust-on-first-use style host key acceptance:
+// a host key that was not known beforehand and that the user chose to trust.
+type HostKeyAcceptance struct {
+	// Host is the address the key was presented for.
+	Host string `json:"host"`
+	// Fingerprint is the SHA256 fingerprint of the accepted key.
+	Fingerprint string `json:"fingerprint"`
+	// ProxyHost is the proxy the client was connected through.
+	ProxyHost string `json:"proxy_host"`
+	// User is the Teleport user that accepted the key.
+	User string `json:"user"`
+	// Time is when the key was accepted.
+	Time time.Time `json:"time"`
+}
+
+// hostKeyReport accumulates host key acceptances for later security review.
+type hostKeyReport struct {
+	mu      sync.Mutex
+	entries []HostKeyAcceptance
+}
+
+func (r *hostKeyReport) add(entry HostKeyAcceptance) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+func (r *hostKeyReport) snapshot() []HostKeyAcceptance {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := make([]HostKeyAcceptance, len(r.entries))
+	copy(entries, r.entries)
+	return entries
+}
+
+// recordHostKeyAcceptance records that the user accepted a previously
+// unknown host key.
+func (a *LocalKeyAgent) recordHostKeyAcceptance(addr string, key ssh.PublicKey) {
+	a.hostKeyReport.add(HostKeyAcceptance{
+		Host:        addr,
+		Fingerprint: ssh.FingerprintSHA256(key),
+		ProxyHost:   a.proxyHost,
+		User:        a.username,
+		Time:        time.Now().UTC(),
+	})
+}
+
+// HostKeyAcceptances returns the host key acceptances recorded by this
+// agent, in acceptance order.
+func (a *LocalKeyAgent) HostKeyAcceptances() []HostKeyAcceptance {
+	return a.hostKeyReport.snapshot()
+}
+
+// HostKeyAcceptances returns the trust-on-first-use host key acceptances
+// recorded during this client's lifetime.
+func (tc *TeleportClient) HostKeyAcceptances() []HostKeyAcceptance {
+	if tc.localAgent == nil {
+		return nil
+	}
+	return tc.localAgent.HostKeyAcceptances()
+}
+
+// ExportHostKeyReport writes the recorded host key acceptances to w as
+// indented JSON for security review.
+func (tc *TeleportClient) ExportHostKeyReport(w io.Writer) error {
+	acceptances := tc.HostKeyAcceptances()
+	if acceptances == nil {
+		acceptances = []HostKeyAcceptance{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return trace.Wrap(encoder.Encode(acceptances))
+}
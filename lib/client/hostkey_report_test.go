@@ -0,0 +1,78 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestHostKeyReport(t *testing.T) {
+	t.Parallel()
+
+	key := hostKeyForTest(t)
+	agent := &LocalKeyAgent{
+		proxyHost:     "proxy.example.com",
+		username:      "alice",
+		hostKeyReport: &hostKeyReport{},
+	}
+
+	require.Empty(t, agent.HostKeyAcceptances())
+
+	agent.recordHostKeyAcceptance("node.example.com:3022", key)
+	acceptances := agent.HostKeyAcceptances()
+	require.Len(t, acceptances, 1)
+	require.Equal(t, "node.example.com:3022", acceptances[0].Host)
+	require.Equal(t, ssh.FingerprintSHA256(key), acceptances[0].Fingerprint)
+	require.Equal(t, "proxy.example.com", acceptances[0].ProxyHost)
+	require.Equal(t, "alice", acceptances[0].User)
+	require.False(t, acceptances[0].Time.IsZero())
+
+	// The snapshot is a copy; mutating it does not affect the report.
+	acceptances[0].Host = "mutated"
+	require.Equal(t, "node.example.com:3022", agent.HostKeyAcceptances()[0].Host)
+}
+
+func TestExportHostKeyReport(t *testing.T) {
+	t.Parallel()
+
+	tc := &TeleportClient{}
+
+	// Without a local agent the report is an empty JSON list.
+	var buf bytes.Buffer
+	require.NoError(t, tc.ExportHostKeyReport(&buf))
+	var exported []HostKeyAcceptance
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+	require.Empty(t, exported)
+
+	tc.localAgent = &LocalKeyAgent{
+		proxyHost:     "proxy.example.com",
+		username:      "alice",
+		hostKeyReport: &hostKeyReport{},
+	}
+	tc.localAgent.recordHostKeyAcceptance("node.example.com:3022", hostKeyForTest(t))
+
+	buf.Reset()
+	require.NoError(t, tc.ExportHostKeyReport(&buf))
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+	require.Len(t, exported, 1)
+	require.Equal(t, "node.example.com:3022", exported[0].Host)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package identityfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/identityfile"
+	"github.com/gravitational/teleport/api/utils/keypaths"
+	"github.com/gravitational/teleport/lib/config/openssh"
+	"github.com/gravitational/teleport/lib/sshutils"
+)
+
+// knownHostsFileName is the name of the known_hosts file written next to an
+// ssh_config snippet.
+const knownHostsFileName = "known_hosts"
+
+// WriteBundleConfig describes a ready-to-use credentials bundle: the
+// identity itself in any supported format, plus optional kubeconfig and
+// ssh_config snippets referencing it, like `tctl auth sign` produces.
+type WriteBundleConfig struct {
+	WriteConfig
+	// KubeconfigPath, when set, additionally writes a kubeconfig for the
+	// identity. KubeProxyAddr, KubeClusterName and KubeTLSServerName from
+	// the embedded WriteConfig fill in the kubeconfig entries.
+	KubeconfigPath string
+	// SSHConfigPath, when set, additionally writes an OpenSSH client config
+	// snippet at that path and a known_hosts file next to it. Requires
+	// FormatOpenSSH, which writes the key and certificate as the separate
+	// files the snippet references.
+	SSHConfigPath string
+	// ProxyHost is the proxy hostname referenced by the ssh_config snippet.
+	ProxyHost string
+	// ExecutablePath is the tsh binary referenced by the ssh_config
+	// ProxyCommand. Defaults to the current executable.
+	ExecutablePath string
+}
+
+// WriteBundle writes the credentials bundle described by cfg and returns
+// the names of the files successfully written.
+func WriteBundle(ctx context.Context, cfg WriteBundleConfig) (filesWritten []string, err error) {
+	filesWritten, err = Write(ctx, cfg.WriteConfig)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	if cfg.KubeconfigPath != "" {
+		kubeCfg := cfg.WriteConfig
+		kubeCfg.Format = FormatKubernetes
+		kubeCfg.OutputPath = cfg.KubeconfigPath
+		files, err := Write(ctx, kubeCfg)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		filesWritten = append(filesWritten, files...)
+	}
+
+	if cfg.SSHConfigPath != "" {
+		files, err := writeSSHConfigSnippet(ctx, cfg)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		filesWritten = append(filesWritten, files...)
+	}
+
+	return filesWritten, nil
+}
+
+// writeSSHConfigSnippet writes an OpenSSH client config snippet and the
+// known_hosts file it references.
+func writeSSHConfigSnippet(ctx context.Context, cfg WriteBundleConfig) ([]string, error) {
+	if cfg.Format != FormatOpenSSH {
+		return nil, trace.BadParameter("ssh_config output requires the %q identity format", FormatOpenSSH)
+	}
+	if cfg.ProxyHost == "" {
+		return nil, trace.BadParameter("ssh_config output requires ProxyHost")
+	}
+
+	writer := cfg.Writer
+	if writer == nil {
+		writer = &StandardConfigWriter{}
+	}
+	executablePath := cfg.ExecutablePath
+	if executablePath == "" {
+		var err error
+		executablePath, err = os.Executable()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
+	knownHostsPath := filepath.Join(filepath.Dir(cfg.SSHConfigPath), knownHostsFileName)
+	filesWritten := []string{cfg.SSHConfigPath, knownHostsPath}
+	if err := checkOverwrite(ctx, writer, cfg.OverwriteDestination, filesWritten...); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	// Marshal the SSH host CAs of every trusted cluster into known_hosts
+	// entries.
+	var knownHosts strings.Builder
+	var clusterNames []string
+	for _, ca := range cfg.Key.TrustedCerts {
+		clusterNames = append(clusterNames, ca.ClusterName)
+		for _, authorizedKey := range ca.AuthorizedKeys {
+			knownHost, err := sshutils.MarshalKnownHost(sshutils.KnownHost{
+				Hostname:      ca.ClusterName,
+				ProxyHost:     cfg.ProxyHost,
+				AuthorizedKey: authorizedKey,
+			})
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			knownHosts.WriteString(knownHost)
+		}
+	}
+	if len(clusterNames) == 0 {
+		return nil, trace.BadParameter("identity has no trusted certificate authorities, cannot build known_hosts")
+	}
+
+	var sshConfig strings.Builder
+	sshConf := openssh.NewSSHConfig(nil, nil)
+	if err := sshConf.GetSSHConfig(&sshConfig, &openssh.SSHConfigParameters{
+		AppName:             openssh.TshApp,
+		ClusterNames:        clusterNames,
+		KnownHostsPath:      knownHostsPath,
+		IdentityFilePath:    cfg.OutputPath,
+		CertificateFilePath: keypaths.IdentitySSHCertPath(cfg.OutputPath),
+		ProxyHost:           cfg.ProxyHost,
+		ExecutablePath:      executablePath,
+	}); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	if err := writer.WriteFile(knownHostsPath, []byte(knownHosts.String()), identityfile.FilePermissions); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := writer.WriteFile(cfg.SSHConfigPath, []byte(sshConfig.String()), identityfile.FilePermissions); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return filesWritten, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package identityfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBundle(t *testing.T) {
+	key := newClientKey(t)
+	outputDir := t.TempDir()
+
+	t.Run("openssh with ssh_config and kubeconfig", func(t *testing.T) {
+		cfg := WriteBundleConfig{
+			WriteConfig: WriteConfig{
+				OutputPath:    filepath.Join(outputDir, "openssh"),
+				Key:           key,
+				Format:        FormatOpenSSH,
+				KubeProxyAddr: "https://proxy.example.com:3026",
+			},
+			KubeconfigPath: filepath.Join(outputDir, "kubeconfig"),
+			SSHConfigPath:  filepath.Join(outputDir, "ssh_config"),
+			ProxyHost:      "proxy.example.com",
+			ExecutablePath: "/usr/local/bin/tsh",
+		}
+		files, err := WriteBundle(context.Background(), cfg)
+		require.NoError(t, err)
+		require.Contains(t, files, cfg.OutputPath)
+		require.Contains(t, files, cfg.KubeconfigPath)
+		require.Contains(t, files, cfg.SSHConfigPath)
+		require.Contains(t, files, filepath.Join(outputDir, knownHostsFileName))
+
+		sshConfig, err := os.ReadFile(cfg.SSHConfigPath)
+		require.NoError(t, err)
+		require.Contains(t, string(sshConfig), "proxy.example.com")
+		require.Contains(t, string(sshConfig), cfg.OutputPath)
+
+		knownHosts, err := os.ReadFile(filepath.Join(outputDir, knownHostsFileName))
+		require.NoError(t, err)
+		require.Contains(t, string(knownHosts), key.TrustedCerts[0].ClusterName)
+
+		kubeconfig, err := os.ReadFile(cfg.KubeconfigPath)
+		require.NoError(t, err)
+		require.Contains(t, string(kubeconfig), "proxy.example.com")
+	})
+
+	t.Run("ssh_config requires openssh format", func(t *testing.T) {
+		cfg := WriteBundleConfig{
+			WriteConfig: WriteConfig{
+				OutputPath: filepath.Join(outputDir, "identity"),
+				Key:        key,
+				Format:     FormatFile,
+			},
+			SSHConfigPath: filepath.Join(outputDir, "ssh_config2"),
+			ProxyHost:     "proxy.example.com",
+		}
+		_, err := WriteBundle(context.Background(), cfg)
+		require.Error(t, err)
+	})
+
+	t.Run("ssh_config requires proxy host", func(t *testing.T) {
+		cfg := WriteBundleConfig{
+			WriteConfig: WriteConfig{
+				OutputPath: filepath.Join(outputDir, "openssh2"),
+				Key:        key,
+				Format:     FormatOpenSSH,
+			},
+			SSHConfigPath: filepath.Join(outputDir, "ssh_config3"),
+		}
+		_, err := WriteBundle(context.Background(), cfg)
+		require.Error(t, err)
+	})
+}
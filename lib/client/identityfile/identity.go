@@ -26,6 +26,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -647,3 +648,92 @@ func NewClientStoreFromIdentityFile(identityFile, proxyAddr, clusterName string)
 
 	return clientStore, nil
 }
+
+// NewClientStoreFromIdentityFiles initializes a new in-memory client store
+// holding one key per cluster, loaded from the given map of cluster name to
+// identity file path. With keys stored per cluster, the client automatically
+// selects the matching identity for whichever cluster a connection is routed
+// to, which allows a single client to serve multiple clusters (e.g. bots
+// driving the client in SkipLocalAuth mode).
+//
+// All identities must belong to the same user, since the temporary profile
+// saved into the store carries a single username. The profile defaults to the
+// first cluster in lexical order; callers typically override the current
+// cluster via the client config.
+func NewClientStoreFromIdentityFiles(identityFiles map[string]string, proxyAddr string) (*client.Store, error) {
+	if len(identityFiles) == 0 {
+		return nil, trace.BadParameter("at least one identity file must be provided")
+	}
+	proxyHost, err := utils.Host(proxyAddr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	clusters := make([]string, 0, len(identityFiles))
+	for cluster := range identityFiles {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	clientStore := client.NewMemClientStore()
+	var defaultKey *client.Key
+	for _, cluster := range clusters {
+		key, err := KeyFromIdentityFile(identityFiles[cluster], proxyHost, cluster)
+		if err != nil {
+			return nil, trace.Wrap(err, "failed to load identity for cluster %q", cluster)
+		}
+		if defaultKey == nil {
+			defaultKey = key
+		} else if key.Username != defaultKey.Username {
+			return nil, trace.BadParameter(
+				"identity files must belong to the same user, got %q and %q",
+				defaultKey.Username, key.Username)
+		}
+		if err := clientStore.AddKey(key); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
+	profile := &profile.Profile{
+		WebProxyAddr:     proxyAddr,
+		SiteName:         defaultKey.ClusterName,
+		Username:         defaultKey.Username,
+		PrivateKeyPolicy: keys.GetPrivateKeyPolicy(defaultKey.PrivateKey),
+	}
+	if err := clientStore.SaveProfile(profile, true); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return clientStore, nil
+}
+
+// NewClientStoreFromIdentityDirectory is a convenience wrapper around
+// NewClientStoreFromIdentityFiles that loads every regular file in dir as an
+// identity file, using the file name with its extension stripped as the
+// cluster name (e.g. "leaf.example.com.pem" aims the identity at cluster
+// "leaf.example.com"). Hidden files and subdirectories are skipped.
+func NewClientStoreFromIdentityDirectory(dir, proxyAddr string) (*client.Store, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+
+	identityFiles := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		cluster := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if other, ok := identityFiles[cluster]; ok {
+			return nil, trace.BadParameter(
+				"identity files %q and %q both map to cluster %q",
+				other, entry.Name(), cluster)
+		}
+		identityFiles[cluster] = filepath.Join(dir, entry.Name())
+	}
+	if len(identityFiles) == 0 {
+		return nil, trace.NotFound("no identity files found in %v", dir)
+	}
+
+	return NewClientStoreFromIdentityFiles(identityFiles, proxyAddr)
+}
@@ -76,6 +76,10 @@ type LocalKeyAgent struct {
 	// loadAllCAs allows the agent to load all host CAs when checking a host
 	// signature.
 	loadAllCAs bool
+
+	// hostKeyReport records trust-on-first-use host key acceptances for
+	// later security review. See HostKeyAcceptances.
+	hostKeyReport *hostKeyReport
 }
 
 func agentIsPresent() bool {
@@ -128,6 +132,7 @@ func NewLocalAgent(conf LocalAgentConfig) (a *LocalKeyAgent, err error) {
 		insecure:      conf.Insecure,
 		siteName:      conf.Site,
 		loadAllCAs:    conf.LoadAllCAs,
+		hostKeyReport: &hostKeyReport{},
 	}
 
 	if shouldAddKeysToAgent(conf.KeysOption) {
@@ -445,6 +450,7 @@ func (a *LocalKeyAgent) checkHostKey(addr string, remote net.Addr, key ssh.Publi
 		a.log.Warnf("Failed to save the host key: %v.", err)
 		return trace.Wrap(err)
 	}
+	a.recordHostKeyAcceptance(addr, key)
 	return nil
 }
 
@@ -273,6 +273,10 @@ func (fs *FSKeyStore) DeleteKeys() error {
 		if file.IsDir() && file.Name() == tshAzureDirName {
 			continue
 		}
+		// The issued certificate log is append-only and survives logout.
+		if file.Name() == certLogFilename {
+			continue
+		}
 		if file.IsDir() {
 			err := os.RemoveAll(filepath.Join(fs.KeyDir, file.Name()))
 			if err != nil {
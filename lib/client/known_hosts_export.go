@@ -0,0 +1,121 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/exp/slices"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/sshutils"
+)
+
+// ExportKnownHostsRequest specifies which host CAs to export as known_hosts
+// entries.
+type ExportKnownHostsRequest struct {
+	// Clusters restricts the export to the given cluster names. An empty
+	// list exports the host CAs of every cluster known to the auth server,
+	// including trusted clusters.
+	Clusters []string
+	// ProxyHost, if set, is included in the host patterns of every entry so
+	// connections addressed at the proxy are also matched.
+	ProxyHost string
+}
+
+// ExportKnownHosts returns the cluster host CAs formatted as an OpenSSH
+// known_hosts file. Each CA key produces a single `@cert-authority` entry
+// scoped to the cluster's domain (e.g. `*.cluster-a,cluster-a`), letting
+// vanilla ssh trust Teleport nodes without manual CA copying.
+func ExportKnownHosts(ctx context.Context, client auth.ClientI, req ExportKnownHostsRequest) (string, error) {
+	cas, err := client.GetCertAuthorities(ctx, types.HostCA, false /* loadKeys */)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+
+	var sb strings.Builder
+	for _, ca := range cas {
+		if len(req.Clusters) > 0 && !slices.Contains(req.Clusters, ca.GetClusterName()) {
+			continue
+		}
+		for _, key := range ca.GetTrustedSSHKeyPairs() {
+			entry, err := sshutils.MarshalKnownHost(sshutils.KnownHost{
+				Hostname:      ca.GetClusterName(),
+				ProxyHost:     req.ProxyHost,
+				AuthorizedKey: key.PublicKey,
+			})
+			if err != nil {
+				return "", trace.Wrap(err)
+			}
+			sb.WriteString(entry)
+		}
+	}
+	return sb.String(), nil
+}
+
+// WatchKnownHosts exports the known_hosts file once and then re-exports it
+// whenever a host CA changes (e.g. during CA rotation), invoking fn with
+// each regenerated file. It blocks until the context is done or the watcher
+// fails.
+func WatchKnownHosts(ctx context.Context, client auth.ClientI, req ExportKnownHostsRequest, fn func(knownHosts string) error) error {
+	watcher, err := client.NewWatcher(ctx, types.Watch{
+		Kinds: []types.WatchKind{{
+			Kind: types.KindCertAuthority,
+			Filter: types.CertAuthorityFilter{
+				types.HostCA: types.Wildcard,
+			}.IntoMap(),
+		}},
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer watcher.Close()
+
+	// Wait for the watcher to initialize before the first export so CA
+	// changes racing the export are not missed.
+	select {
+	case event := <-watcher.Events():
+		if event.Type != types.OpInit {
+			return trace.BadParameter("expected init event, got %v", event.Type)
+		}
+	case <-watcher.Done():
+		return trace.Wrap(watcher.Error())
+	case <-ctx.Done():
+		return trace.Wrap(ctx.Err())
+	}
+
+	for {
+		knownHosts, err := ExportKnownHosts(ctx, client, req)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if err := fn(knownHosts); err != nil {
+			return trace.Wrap(err)
+		}
+
+		select {
+		case <-watcher.Events():
+		case <-watcher.Done():
+			return trace.Wrap(watcher.Error())
+		case <-ctx.Done():
+			return trace.Wrap(ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// KubeExecRequest describes an exec session into a pod routed through the
+// Teleport kubernetes proxy.
+type KubeExecRequest struct {
+	// TeleportCluster is the teleport cluster the kubernetes cluster is
+	// registered in. Empty means the client's current cluster.
+	TeleportCluster string
+	// KubeCluster is the name of the kubernetes cluster.
+	KubeCluster string
+	// Namespace is the pod's namespace, "default" if empty.
+	Namespace string
+	// Pod is the name of the pod to exec into.
+	Pod string
+	// Container is the container to exec into. Empty means the pod's
+	// default container.
+	Container string
+	// Command is the command to execute with its arguments.
+	Command []string
+	// Stdin, Stdout and Stderr are the raw streams attached to the remote
+	// process. Any of them may be nil to leave that stream unattached.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// TTY allocates a terminal for the remote process. When set, the server
+	// merges stderr into stdout.
+	TTY bool
+	// Resize delivers terminal resizes to the remote process. Optional;
+	// close the channel when no more resizes will be sent.
+	Resize <-chan remotecommand.TerminalSize
+}
+
+// CheckAndSetDefaults validates the request and fills in defaults.
+func (req *KubeExecRequest) CheckAndSetDefaults() error {
+	if req.KubeCluster == "" {
+		return trace.BadParameter("missing kubernetes cluster")
+	}
+	if req.Pod == "" {
+		return trace.BadParameter("missing pod name")
+	}
+	if len(req.Command) == 0 {
+		return trace.BadParameter("missing command")
+	}
+	if req.Namespace == "" {
+		req.Namespace = "default"
+	}
+	return nil
+}
+
+// KubeExec executes a command in a pod through the Teleport kubernetes
+// proxy, streaming stdin/stdout/stderr until the remote process exits or
+// the context is canceled. A kubernetes certificate for the target cluster
+// is issued if the local key store doesn't hold a valid one, prompting for
+// MFA when the cluster requires per-session MFA.
+func (tc *TeleportClient) KubeExec(ctx context.Context, req KubeExecRequest) error {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/KubeExec",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	if err := req.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if req.TeleportCluster == "" {
+		req.TeleportCluster = tc.SiteName
+	}
+
+	key, err := tc.kubeKeyFor(ctx, KubeClusterLogin{
+		TeleportCluster: req.TeleportCluster,
+		KubeCluster:     req.KubeCluster,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	restConfig, err := tc.kubeRESTConfig(key, req.KubeCluster)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	u, err := url.Parse(tc.KubeClusterAddr())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	u.Path = fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/exec", req.Namespace, req.Pod)
+	query := u.Query()
+	for _, arg := range req.Command {
+		query.Add("command", arg)
+	}
+	if req.Container != "" {
+		query.Set("container", req.Container)
+	}
+	if req.Stdin != nil {
+		query.Set("stdin", "true")
+	}
+	if req.Stdout != nil {
+		query.Set("stdout", "true")
+	}
+	if req.Stderr != nil {
+		query.Set("stderr", "true")
+	}
+	if req.TTY {
+		query.Set("tty", "true")
+	}
+	u.RawQuery = query.Encode()
+
+	executor, err := remotecommand.NewSPDYExecutor(restConfig, http.MethodPost, u)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	var sizeQueue remotecommand.TerminalSizeQueue
+	if req.Resize != nil {
+		sizeQueue = &channelSizeQueue{ctx: ctx, resizeC: req.Resize}
+	}
+
+	return trace.Wrap(executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:             req.Stdin,
+		Stdout:            req.Stdout,
+		Stderr:            req.Stderr,
+		Tty:               req.TTY,
+		TerminalSizeQueue: sizeQueue,
+	}))
+}
+
+// kubeKeyFor returns a key holding a valid kubernetes certificate for the
+// given cluster, issuing one (with MFA if required) when the local key
+// store doesn't already hold it.
+func (tc *TeleportClient) kubeKeyFor(ctx context.Context, login KubeClusterLogin) (*Key, error) {
+	if tc.hasValidKubeCert(login) {
+		key, err := tc.localAgent.GetKey(login.TeleportCluster, WithKubeCerts{})
+		if err == nil {
+			return key, nil
+		}
+		log.WithError(err).Debug("Failed to load cached kubernetes certificate, reissuing.")
+	}
+
+	key, err := tc.IssueUserCertsWithMFA(ctx, ReissueParams{
+		RouteToCluster:    login.TeleportCluster,
+		KubernetesCluster: login.KubeCluster,
+	}, nil /*applyOpts*/)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// Cache the certificate for subsequent calls; the in-memory key is
+	// usable either way.
+	if err := tc.localAgent.AddKey(key); err != nil {
+		log.WithError(err).Warn("Failed to cache kubernetes certificate.")
+	}
+	return key, nil
+}
+
+// kubeRESTConfig builds a kubernetes client config routing through the
+// Teleport kubernetes proxy with the key's certificate for the given
+// kubernetes cluster.
+func (tc *TeleportClient) kubeRESTConfig(key *Key, kubeCluster string) (*restclient.Config, error) {
+	certPEM, ok := key.KubeTLSCerts[kubeCluster]
+	if !ok {
+		return nil, trace.NotFound("TLS certificate for kubernetes cluster %q not found", kubeCluster)
+	}
+	keyPEM, err := key.PrivateKey.RSAPrivateKeyPEM()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cas, err := key.RootClusterCAs()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	restConfig := &restclient.Config{
+		Host: tc.KubeClusterAddr(),
+		TLSClientConfig: restclient.TLSClientConfig{
+			CertData: certPEM,
+			KeyData:  keyPEM,
+			CAData:   bytes.Join(cas, []byte("\n")),
+			Insecure: tc.InsecureSkipVerify,
+		},
+	}
+	if tc.TLSRoutingEnabled {
+		k8host, _ := tc.KubeProxyHostPort()
+		restConfig.TLSClientConfig.ServerName = GetKubeTLSServerName(k8host)
+	}
+	return restConfig, nil
+}
+
+// channelSizeQueue adapts a resize channel to the remotecommand
+// TerminalSizeQueue interface.
+type channelSizeQueue struct {
+	ctx     context.Context
+	resizeC <-chan remotecommand.TerminalSize
+}
+
+// Next returns the next terminal size, or nil when the channel is closed
+// or the context is done.
+func (q *channelSizeQueue) Next() *remotecommand.TerminalSize {
+	select {
+	case size, ok := <-q.resizeC:
+		if !ok {
+			return nil
+		}
+		return &size
+	case <-q.ctx.Done():
+		return nil
+	}
+}
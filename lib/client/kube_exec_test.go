@@ -0,0 +1,117 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+func TestKubeExecRequestCheckAndSetDefaults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		req       KubeExecRequest
+		assertErr require.ErrorAssertionFunc
+		namespace string
+	}{
+		{
+			name: "valid with defaults",
+			req: KubeExecRequest{
+				KubeCluster: "kube",
+				Pod:         "pod",
+				Command:     []string{"ls"},
+			},
+			assertErr: require.NoError,
+			namespace: "default",
+		},
+		{
+			name: "namespace preserved",
+			req: KubeExecRequest{
+				KubeCluster: "kube",
+				Namespace:   "kube-system",
+				Pod:         "pod",
+				Command:     []string{"ls"},
+			},
+			assertErr: require.NoError,
+			namespace: "kube-system",
+		},
+		{
+			name: "missing kube cluster",
+			req: KubeExecRequest{
+				Pod:     "pod",
+				Command: []string{"ls"},
+			},
+			assertErr: require.Error,
+		},
+		{
+			name: "missing pod",
+			req: KubeExecRequest{
+				KubeCluster: "kube",
+				Command:     []string{"ls"},
+			},
+			assertErr: require.Error,
+		},
+		{
+			name: "missing command",
+			req: KubeExecRequest{
+				KubeCluster: "kube",
+				Pod:         "pod",
+			},
+			assertErr: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.CheckAndSetDefaults()
+			tt.assertErr(t, err)
+			if err == nil {
+				require.Equal(t, tt.namespace, tt.req.Namespace)
+			}
+		})
+	}
+}
+
+func TestChannelSizeQueue(t *testing.T) {
+	t.Parallel()
+
+	resizeC := make(chan remotecommand.TerminalSize, 2)
+	resizeC <- remotecommand.TerminalSize{Width: 80, Height: 24}
+	resizeC <- remotecommand.TerminalSize{Width: 100, Height: 30}
+	close(resizeC)
+
+	queue := &channelSizeQueue{ctx: context.Background(), resizeC: resizeC}
+	size := queue.Next()
+	require.NotNil(t, size)
+	require.Equal(t, remotecommand.TerminalSize{Width: 80, Height: 24}, *size)
+	size = queue.Next()
+	require.NotNil(t, size)
+	require.Equal(t, remotecommand.TerminalSize{Width: 100, Height: 30}, *size)
+	// Closed channel reports no more sizes.
+	require.Nil(t, queue.Next())
+
+	// A canceled context unblocks the queue.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	queue = &channelSizeQueue{ctx: ctx, resizeC: make(chan remotecommand.TerminalSize)}
+	require.Nil(t, queue.Next())
+}
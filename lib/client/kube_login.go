@@ -0,0 +1,149 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// kubeLoginParallelism bounds how many kubernetes certificates are issued
+// concurrently by LoginToKubeClusters.
+const kubeLoginParallelism = 4
+
+// KubeClusterLogin identifies a kubernetes cluster to log into, optionally
+// routed through a leaf cluster.
+type KubeClusterLogin struct {
+	// TeleportCluster is the teleport cluster the kubernetes cluster is
+	// registered in. Empty means the client's current cluster.
+	TeleportCluster string
+	// KubeCluster is the name of the kubernetes cluster.
+	KubeCluster string
+}
+
+// KubeLoginResult reports the outcome of logging into a single kubernetes
+// cluster.
+type KubeLoginResult struct {
+	// Login identifies the kubernetes cluster.
+	Login KubeClusterLogin
+	// Err is set when the login failed; failures for one cluster do not
+	// abort the remaining logins.
+	Err error
+}
+
+// LoginToKubeClusters issues TLS certificates for the given kubernetes
+// clusters and caches them in the local key store, skipping clusters whose
+// cached certificates are still valid. Certificates are issued with bounded
+// parallelism, and onProgress (optional) is invoked as each cluster
+// completes, in completion order. Per-cluster failures are reported in the
+// results rather than aborting the whole pass.
+//
+// Clusters gated by per-session MFA cannot be logged into in bulk; they
+// report an error in their result.
+func (tc *TeleportClient) LoginToKubeClusters(ctx context.Context, logins []KubeClusterLogin, onProgress func(KubeLoginResult)) ([]KubeLoginResult, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/LoginToKubeClusters",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	if len(logins) == 0 {
+		return nil, trace.BadParameter("no kubernetes clusters to log into")
+	}
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	type issued struct {
+		result KubeLoginResult
+		key    *Key
+	}
+	resultCh := make(chan issued)
+	sem := make(chan struct{}, kubeLoginParallelism)
+
+	var launched int
+	results := make([]KubeLoginResult, 0, len(logins))
+	for _, login := range logins {
+		if login.TeleportCluster == "" {
+			login.TeleportCluster = tc.SiteName
+		}
+
+		// Skip the round-trip if a valid certificate is already cached.
+		if tc.hasValidKubeCert(login) {
+			result := KubeLoginResult{Login: login}
+			if onProgress != nil {
+				onProgress(result)
+			}
+			results = append(results, result)
+			continue
+		}
+
+		login := login
+		launched++
+		go func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			key, err := proxyClient.IssueUserCertsWithMFA(ctx, ReissueParams{
+				RouteToCluster:    login.TeleportCluster,
+				KubernetesCluster: login.KubeCluster,
+			}, nil /*promptMFAChallenge*/)
+			resultCh <- issued{
+				result: KubeLoginResult{Login: login, Err: trace.Wrap(err)},
+				key:    key,
+			}
+		}()
+	}
+
+	// Collect completions, caching new certificates serially since the key
+	// store is not safe for concurrent use.
+	for i := 0; i < launched; i++ {
+		res := <-resultCh
+		if res.result.Err == nil && res.key != nil {
+			if err := tc.localAgent.AddKey(res.key); err != nil {
+				res.result.Err = trace.Wrap(err)
+			}
+		}
+		if onProgress != nil {
+			onProgress(res.result)
+		}
+		results = append(results, res.result)
+	}
+
+	return results, nil
+}
+
+// hasValidKubeCert returns true when the local key store already holds a
+// kubernetes certificate for the given cluster that isn't about to expire.
+func (tc *TeleportClient) hasValidKubeCert(login KubeClusterLogin) bool {
+	key, err := tc.localAgent.GetKey(login.TeleportCluster, WithKubeCerts{})
+	if err != nil {
+		return false
+	}
+	crt, err := key.KubeTLSCertificate(login.KubeCluster)
+	if err != nil || crt == nil {
+		return false
+	}
+	return time.Until(crt.NotAfter) > time.Minute
+}
@@ -112,7 +112,7 @@ func NewKubeSession(ctx context.Context, tc *TeleportClient, meta types.SessionT
 		return nil, trace.Wrap(err)
 	}
 
-	s.pipeInOut(stdout, tc.EnableEscapeSequences, mode)
+	s.pipeInOut(stdout, tc.EnableEscapeSequences, tc.EscapePassThrough, mode)
 	return s, nil
 }
 
@@ -175,7 +175,7 @@ func (s *KubeSession) handleMFA(ctx context.Context, tc *TeleportClient, mode ty
 }
 
 // pipeInOut starts background tasks that copy input to and from the terminal.
-func (s *KubeSession) pipeInOut(stdout io.Writer, enableEscapeSequences bool, mode types.SessionParticipantMode) {
+func (s *KubeSession) pipeInOut(stdout io.Writer, enableEscapeSequences, escapePassThrough bool, mode types.SessionParticipantMode) {
 	go func() {
 		defer s.cancel()
 		_, err := io.Copy(stdout, s.stream)
@@ -189,7 +189,7 @@ func (s *KubeSession) pipeInOut(stdout io.Writer, enableEscapeSequences bool, mo
 
 		switch mode {
 		case types.SessionPeerMode:
-			handlePeerControls(s.term, enableEscapeSequences, s.stream)
+			handlePeerControls(s.term, enableEscapeSequences, escapePassThrough, s.stream)
 		default:
 			handleNonPeerControls(mode, s.term, func() {
 				err := s.stream.ForceTerminate()
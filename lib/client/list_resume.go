@@ -0,0 +1,88 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/client"
+	"github.com/gravitational/teleport/api/client/proto"
+	apidefaults "github.com/gravitational/teleport/api/defaults"
+	"github.com/gravitational/teleport/api/types"
+)
+
+// getResourcesWithResume pages through a resource listing like
+// client.GetResourcesWithFilters, but keeps the page cursor across transient
+// failures so a connection dropped mid-pagination (e.g. a proxy restart)
+// resumes from the last completed page instead of failing outright. Listing
+// is idempotent, so retried pages are safe; results are deduplicated by kind
+// and name in case a resumed page overlaps the previous one.
+func (proxy *ProxyClient) getResourcesWithResume(ctx context.Context, clt client.ListResourcesClient, req proto.ListResourcesRequest) ([]types.ResourceWithLabels, error) {
+	var (
+		resources []types.ResourceWithLabels
+		seen      = make(map[string]struct{})
+		startKey  = req.StartKey
+		chunkSize = int32(apidefaults.DefaultChunkSize)
+	)
+
+	for {
+		var resp *types.ListResourcesResponse
+		err := proxy.teleportClient.withRetry(ctx, func(ctx context.Context) error {
+			var err error
+			resp, err = clt.ListResources(ctx, proto.ListResourcesRequest{
+				Namespace:           req.Namespace,
+				ResourceType:        req.ResourceType,
+				StartKey:            startKey,
+				Limit:               chunkSize,
+				Labels:              req.Labels,
+				SearchKeywords:      req.SearchKeywords,
+				PredicateExpression: req.PredicateExpression,
+				UseSearchAsRoles:    req.UseSearchAsRoles,
+			})
+			return trace.Wrap(err)
+		})
+		if err != nil {
+			if trace.IsLimitExceeded(err) {
+				// Cut chunkSize in half if gRPC max message size is exceeded.
+				chunkSize = chunkSize / 2
+				if chunkSize == 0 {
+					return nil, trace.Wrap(err, "resource is too large to retrieve")
+				}
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+
+		for _, resource := range resp.Resources {
+			key := resource.GetKind() + "/" + resource.GetName()
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			resources = append(resources, resource)
+		}
+
+		startKey = resp.NextKey
+		if startKey == "" || len(resp.Resources) == 0 {
+			break
+		}
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/types"
+)
+
+// fakeListResourcesClient serves canned listing pages keyed by start key and
+// can be told to fail a page a number of times before succeeding.
+type fakeListResourcesClient struct {
+	pages    map[string]*types.ListResourcesResponse
+	failures map[string]int
+	calls    []string
+}
+
+func (f *fakeListResourcesClient) ListResources(ctx context.Context, req proto.ListResourcesRequest) (*types.ListResourcesResponse, error) {
+	f.calls = append(f.calls, req.StartKey)
+	if f.failures[req.StartKey] > 0 {
+		f.failures[req.StartKey]--
+		return nil, trace.ConnectionProblem(nil, "proxy is restarting")
+	}
+	resp, ok := f.pages[req.StartKey]
+	if !ok {
+		return nil, trace.NotFound("no page at start key %q", req.StartKey)
+	}
+	return resp, nil
+}
+
+func TestGetResourcesWithResume(t *testing.T) {
+	t.Parallel()
+
+	nodeA := nodeForTest(t, "node-a", "10.0.0.1:3022", nil)
+	nodeB := nodeForTest(t, "node-b", "10.0.0.2:3022", nil)
+	nodeC := nodeForTest(t, "node-c", "10.0.0.3:3022", nil)
+
+	proxy := &ProxyClient{
+		teleportClient: &TeleportClient{
+			Config: Config{
+				ConnectionRetry: &ConnectionRetryPolicy{
+					MaxAttempts: 3,
+					BackoffBase: time.Millisecond,
+				},
+			},
+		},
+	}
+
+	// The second page fails once with a connection problem before succeeding
+	// and overlaps the first page by one entry, as it would after resuming
+	// from a possibly stale cursor.
+	clt := &fakeListResourcesClient{
+		pages: map[string]*types.ListResourcesResponse{
+			"": {
+				Resources: []types.ResourceWithLabels{nodeA, nodeB},
+				NextKey:   "node-b",
+			},
+			"node-b": {
+				Resources: []types.ResourceWithLabels{nodeB, nodeC},
+			},
+		},
+		failures: map[string]int{"node-b": 1},
+	}
+
+	resources, err := proxy.getResourcesWithResume(context.Background(), clt, proto.ListResourcesRequest{
+		ResourceType: types.KindNode,
+	})
+	require.NoError(t, err)
+
+	// Partial results survived the interruption and the overlapping entry
+	// was not duplicated.
+	var names []string
+	for _, r := range resources {
+		names = append(names, r.GetName())
+	}
+	require.Equal(t, []string{"node-a", "node-b", "node-c"}, names)
+
+	// The listing resumed from the last cursor rather than restarting.
+	require.Equal(t, []string{"", "node-b", "node-b"}, clt.calls)
+}
+
+func TestGetResourcesWithResumeExhaustsRetries(t *testing.T) {
+	t.Parallel()
+
+	proxy := &ProxyClient{
+		teleportClient: &TeleportClient{
+			Config: Config{
+				ConnectionRetry: &ConnectionRetryPolicy{
+					MaxAttempts: 2,
+					BackoffBase: time.Millisecond,
+				},
+			},
+		},
+	}
+
+	clt := &fakeListResourcesClient{
+		pages:    map[string]*types.ListResourcesResponse{},
+		failures: map[string]int{"": 10},
+	}
+
+	_, err := proxy.getResourcesWithResume(context.Background(), clt, proto.ListResourcesRequest{
+		ResourceType: types.KindNode,
+	})
+	require.True(t, trace.IsConnectionProblem(err))
+	require.Len(t, clt.calls, 2)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+// lockInForcePrefix matches the error message produced by the auth server
+// when a lock denies an operation (see services.LockInForceAccessDenied).
+const lockInForcePrefix = "lock targeting "
+
+// ErrLocked is returned when an operation was denied because a lock is in
+// force against the user, their roles, or the target node.
+type ErrLocked struct {
+	// Target describes what the lock targets.
+	Target string
+	// Message is the message attached to the lock, if any.
+	Message string
+	// Expires is when the lock expires; nil if the lock has no expiry or the
+	// expiry is not known.
+	Expires *time.Time
+}
+
+// Error implements the error interface.
+func (e *ErrLocked) Error() string {
+	s := fmt.Sprintf("lock targeting %v is in force", e.Target)
+	if e.Message != "" {
+		s += ": " + e.Message
+	}
+	if e.Expires != nil {
+		s += fmt.Sprintf(" (expires %v)", e.Expires.Format(time.RFC3339))
+	}
+	return s
+}
+
+// IsErrLocked returns true if the error (or any error it wraps) was caused
+// by a lock being in force.
+func IsErrLocked(err error) bool {
+	var lockErr *ErrLocked
+	return errors.As(err, &lockErr)
+}
+
+// parseLockInForceError inspects an error returned by the cluster and, if it
+// was caused by a lock being in force, converts it to an ErrLocked. It
+// returns the original error unchanged otherwise.
+func parseLockInForceError(err error) error {
+	if err == nil || !trace.IsAccessDenied(err) {
+		return err
+	}
+	if lockErr, ok := lockInForceError(trace.UserMessage(err)); ok {
+		return lockErr
+	}
+	return err
+}
+
+// lockInForceError parses a lock-in-force message out of free-form error
+// output from the cluster. It returns false if the output does not describe
+// a lock being in force.
+func lockInForceError(msg string) (*ErrLocked, bool) {
+	idx := strings.Index(msg, lockInForcePrefix)
+	if idx < 0 {
+		return nil, false
+	}
+	rest := msg[idx+len(lockInForcePrefix):]
+	target, lockMsg, found := strings.Cut(rest, " is in force")
+	if !found {
+		return nil, false
+	}
+	lockMsg = strings.TrimPrefix(lockMsg, ": ")
+	return &ErrLocked{Target: target, Message: strings.TrimSpace(lockMsg)}, true
+}
+
+// lockGetterWatcher is the subset of the auth client used to wait for locks
+// to be released.
+type lockGetterWatcher interface {
+	GetLocks(ctx context.Context, inForceOnly bool, targets ...types.LockTarget) ([]types.Lock, error)
+	NewWatcher(ctx context.Context, watch types.Watch) (types.Watcher, error)
+}
+
+// WaitForUnlock blocks until no locks matching the given targets remain in
+// force, or until the context is done. It relies on lock events rather than
+// polling, re-checking the in-force locks whenever one changes or the
+// earliest expiry passes.
+func WaitForUnlock(ctx context.Context, clt lockGetterWatcher, targets ...types.LockTarget) error {
+	watcher, err := clt.NewWatcher(ctx, types.Watch{
+		Kinds: []types.WatchKind{{Kind: types.KindLock}},
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer watcher.Close()
+
+	// Wait for the watcher to initialize before taking the first snapshot so
+	// no lock changes are missed in between.
+	select {
+	case event := <-watcher.Events():
+		if event.Type != types.OpInit {
+			return trace.BadParameter("expected init event, got %v", event.Type)
+		}
+	case <-watcher.Done():
+		return trace.Wrap(watcher.Error())
+	case <-ctx.Done():
+		return trace.Wrap(ctx.Err())
+	}
+
+	for {
+		locks, err := clt.GetLocks(ctx, true, targets...)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if len(locks) == 0 {
+			return nil
+		}
+
+		// Expired locks are not necessarily deleted right away, so also wake
+		// up when the earliest expiry passes.
+		expiryC := make(<-chan time.Time)
+		if expiry := earliestLockExpiry(locks); expiry != nil {
+			timer := time.NewTimer(time.Until(*expiry))
+			defer timer.Stop()
+			expiryC = timer.C
+		}
+
+		select {
+		case <-watcher.Events():
+		case <-expiryC:
+		case <-watcher.Done():
+			return trace.Wrap(watcher.Error())
+		case <-ctx.Done():
+			return trace.Wrap(ctx.Err())
+		}
+	}
+}
+
+// earliestLockExpiry returns the earliest expiry among the given locks, or
+// nil if none of them expire.
+func earliestLockExpiry(locks []types.Lock) *time.Time {
+	var earliest *time.Time
+	for _, lock := range locks {
+		expiry := lock.LockExpiry()
+		if expiry == nil {
+			continue
+		}
+		if earliest == nil || expiry.Before(*earliest) {
+			earliest = expiry
+		}
+	}
+	return earliest
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+func TestLockInForceError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		msg         string
+		wantLocked  bool
+		wantTarget  string
+		wantMessage string
+	}{
+		{
+			name:       "lock without message",
+			msg:        `lock targeting User:"alice" is in force`,
+			wantLocked: true,
+			wantTarget: `User:"alice"`,
+		},
+		{
+			name:        "lock with message",
+			msg:         `lock targeting Role:"dev" is in force: suspicious activity`,
+			wantLocked:  true,
+			wantTarget:  `Role:"dev"`,
+			wantMessage: "suspicious activity",
+		},
+		{
+			name:       "embedded in server output",
+			msg:        "ERROR: ssh: rejected: lock targeting Node:\"node-1\" is in force\n",
+			wantLocked: true,
+			wantTarget: `Node:"node-1"`,
+		},
+		{
+			name:       "unrelated error",
+			msg:        "access denied to perform action",
+			wantLocked: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			lockErr, ok := lockInForceError(tt.msg)
+			require.Equal(t, tt.wantLocked, ok)
+			if !tt.wantLocked {
+				return
+			}
+			require.Equal(t, tt.wantTarget, lockErr.Target)
+			require.Equal(t, tt.wantMessage, lockErr.Message)
+		})
+	}
+}
+
+func TestParseLockInForceError(t *testing.T) {
+	t.Parallel()
+
+	err := parseLockInForceError(trace.AccessDenied(`lock targeting User:"alice" is in force`))
+	require.True(t, IsErrLocked(err))
+
+	// Non-access-denied errors pass through unchanged.
+	orig := trace.BadParameter(`lock targeting User:"alice" is in force`)
+	require.Equal(t, orig, parseLockInForceError(orig))
+	require.False(t, IsErrLocked(parseLockInForceError(trace.AccessDenied("no cluster access"))))
+	require.NoError(t, parseLockInForceError(nil))
+}
+
+func TestEarliestLockExpiry(t *testing.T) {
+	t.Parallel()
+
+	newLock := func(name string, expiry *time.Time) types.Lock {
+		lock, err := types.NewLock(name, types.LockSpecV2{
+			Target:  types.LockTarget{User: "alice"},
+			Expires: expiry,
+		})
+		require.NoError(t, err)
+		return lock
+	}
+
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	require.Nil(t, earliestLockExpiry([]types.Lock{newLock("a", nil)}))
+	expiry := earliestLockExpiry([]types.Lock{
+		newLock("a", &later),
+		newLock("b", &now),
+		newLock("c", nil),
+	})
+	require.NotNil(t, expiry)
+	require.Equal(t, now, *expiry)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+)
+
+// LoginStage identifies a stage of the login ceremony. GUIs can use the
+// reported stage to tell the user what the client is currently waiting for.
+type LoginStage string
+
+const (
+	// LoginStagePing is reported before the client pings the proxy to
+	// discover the supported authentication methods.
+	LoginStagePing LoginStage = "ping"
+	// LoginStageSSORedirectOpened is reported when the client is about to
+	// open the SSO redirect in the browser and wait for the callback.
+	LoginStageSSORedirectOpened LoginStage = "sso-redirect-opened"
+	// LoginStageWaitingMFATouch is reported when the client is waiting for
+	// the user to complete an MFA challenge, typically a hardware key touch.
+	LoginStageWaitingMFATouch LoginStage = "waiting-mfa-touch"
+	// LoginStageIssuingCerts is reported when the client asks the cluster to
+	// issue user certificates.
+	LoginStageIssuingCerts LoginStage = "issuing-certs"
+	// LoginStageSavingProfile is reported when the client saves the issued
+	// certificates and profile to local storage.
+	LoginStageSavingProfile LoginStage = "saving-profile"
+)
+
+// LoginProgressFunc is called as the login ceremony enters each stage.
+// Returning an error cancels the login and is returned to the caller.
+type LoginProgressFunc func(stage LoginStage) error
+
+// reportLoginProgress notifies the configured LoginProgress callback, if any,
+// that the login ceremony entered the given stage. It provides a cooperative
+// cancellation point: a canceled context or an error from the callback aborts
+// the login before the stage starts.
+func (tc *TeleportClient) reportLoginProgress(ctx context.Context, stage LoginStage) error {
+	if err := ctx.Err(); err != nil {
+		return trace.Wrap(err)
+	}
+	if tc.LoginProgress == nil {
+		return nil
+	}
+	return trace.Wrap(tc.LoginProgress(stage))
+}
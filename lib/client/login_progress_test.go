@@ -0,0 +1,72 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportLoginProgress(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("nil callback", func(t *testing.T) {
+		tc := &TeleportClient{}
+		require.NoError(t, tc.reportLoginProgress(ctx, LoginStagePing))
+	})
+
+	t.Run("callback receives stage", func(t *testing.T) {
+		var got []LoginStage
+		tc := &TeleportClient{Config: Config{
+			LoginProgress: func(stage LoginStage) error {
+				got = append(got, stage)
+				return nil
+			},
+		}}
+		require.NoError(t, tc.reportLoginProgress(ctx, LoginStagePing))
+		require.NoError(t, tc.reportLoginProgress(ctx, LoginStageIssuingCerts))
+		require.Equal(t, []LoginStage{LoginStagePing, LoginStageIssuingCerts}, got)
+	})
+
+	t.Run("callback error cancels", func(t *testing.T) {
+		tc := &TeleportClient{Config: Config{
+			LoginProgress: func(LoginStage) error {
+				return trace.LimitExceeded("user canceled")
+			},
+		}}
+		err := tc.reportLoginProgress(ctx, LoginStageWaitingMFATouch)
+		require.True(t, trace.IsLimitExceeded(err))
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		called := false
+		tc := &TeleportClient{Config: Config{
+			LoginProgress: func(LoginStage) error {
+				called = true
+				return nil
+			},
+		}}
+		require.ErrorIs(t, tc.reportLoginProgress(canceledCtx, LoginStagePing), context.Canceled)
+		require.False(t, called)
+	})
+}
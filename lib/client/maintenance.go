@@ -0,0 +1,127 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/gravitational/teleport/api/types"
+	apiutils "github.com/gravitational/teleport/api/utils"
+)
+
+// MaintenanceLabel marks a node as under maintenance when set to anything but
+// an explicit false value. Freeform values like a ticket reference or a
+// window description also count as under maintenance.
+const MaintenanceLabel = types.TeleportNamespace + "/maintenance"
+
+// MaintenancePolicy controls what the client does when an SSH or file
+// transfer target is marked as under maintenance.
+type MaintenancePolicy string
+
+const (
+	// MaintenancePolicyWarn prints a warning but proceeds with the
+	// connection. This is the default.
+	MaintenancePolicyWarn MaintenancePolicy = "warn"
+	// MaintenancePolicyRefuse refuses the connection unless
+	// BypassMaintenance is set.
+	MaintenancePolicyRefuse MaintenancePolicy = "refuse"
+)
+
+// ParseMaintenancePolicy parses the user-facing name of a maintenance policy.
+func ParseMaintenancePolicy(s string) (MaintenancePolicy, error) {
+	switch policy := MaintenancePolicy(s); policy {
+	case MaintenancePolicyWarn, MaintenancePolicyRefuse:
+		return policy, nil
+	case "":
+		return MaintenancePolicyWarn, nil
+	default:
+		return MaintenancePolicyWarn, trace.BadParameter(
+			"invalid maintenance policy %q, expected %q or %q", s, MaintenancePolicyWarn, MaintenancePolicyRefuse)
+	}
+}
+
+// serverInMaintenance reports whether the node carries the maintenance label.
+// Only explicit false values ("false", "no", "off", ...) disable it; any
+// other value, e.g. a change ticket reference, marks the node as under
+// maintenance.
+func serverInMaintenance(server types.Server) bool {
+	value, ok := server.GetAllLabels()[MaintenanceLabel]
+	if !ok {
+		return false
+	}
+	enabled, err := apiutils.ParseBool(value)
+	if err != nil {
+		return true
+	}
+	return enabled
+}
+
+// checkNodesMaintenance warns about or refuses connections to target nodes
+// marked as under maintenance, depending on the configured policy. The check
+// is skipped entirely when BypassMaintenance is set.
+func (tc *TeleportClient) checkNodesMaintenance(ctx context.Context, proxyClient *ProxyClient, nodeAddrs []string) error {
+	if tc.BypassMaintenance {
+		return nil
+	}
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/checkNodesMaintenance",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	// Targets are dialed either by UUID or by hostname, so index the cluster
+	// nodes by both.
+	targets := make(map[string]struct{}, len(nodeAddrs))
+	for _, addr := range nodeAddrs {
+		targets[nodeName(addr)] = struct{}{}
+	}
+
+	servers, err := proxyClient.FindNodesByFilters(ctx, *tc.DefaultResourceFilter())
+	if err != nil {
+		// Fail closed when refusing is requested, otherwise the check is
+		// best effort and must not break connections.
+		if tc.MaintenancePolicy == MaintenancePolicyRefuse {
+			return trace.Wrap(err)
+		}
+		log.WithError(err).Debug("Unable to check nodes for maintenance markers.")
+		return nil
+	}
+	var inMaintenance []string
+	for _, server := range servers {
+		_, byName := targets[server.GetName()]
+		_, byHostname := targets[server.GetHostname()]
+		if (byName || byHostname) && serverInMaintenance(server) {
+			inMaintenance = append(inMaintenance, server.GetHostname())
+		}
+	}
+	if len(inMaintenance) == 0 {
+		return nil
+	}
+
+	if tc.MaintenancePolicy == MaintenancePolicyRefuse {
+		return trace.AccessDenied(
+			"node(s) %v are marked as under maintenance (%v label), refusing to connect; use --bypass-maintenance to connect anyway",
+			strings.Join(inMaintenance, ", "), MaintenanceLabel)
+	}
+	tc.Warningf("node(s) %v are marked as under maintenance, proceed with care.", strings.Join(inMaintenance, ", "))
+	return nil
+}
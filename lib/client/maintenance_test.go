@@ -0,0 +1,83 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerInMaintenance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc   string
+		labels map[string]string
+		want   bool
+	}{
+		{
+			desc:   "no label",
+			labels: map[string]string{"env": "prod"},
+			want:   false,
+		},
+		{
+			desc:   "label set to true",
+			labels: map[string]string{MaintenanceLabel: "true"},
+			want:   true,
+		},
+		{
+			desc:   "label set to yes",
+			labels: map[string]string{MaintenanceLabel: "yes"},
+			want:   true,
+		},
+		{
+			desc:   "label set to false",
+			labels: map[string]string{MaintenanceLabel: "false"},
+			want:   false,
+		},
+		{
+			desc:   "freeform value counts as maintenance",
+			labels: map[string]string{MaintenanceLabel: "CHG-1234 until 06:00 UTC"},
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+			node := nodeForTest(t, "node-a", "node-a.example.com:3022", tt.labels)
+			require.Equal(t, tt.want, serverInMaintenance(node))
+		})
+	}
+}
+
+func TestParseMaintenancePolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := ParseMaintenancePolicy("")
+	require.NoError(t, err)
+	require.Equal(t, MaintenancePolicyWarn, policy)
+
+	policy, err = ParseMaintenancePolicy("refuse")
+	require.NoError(t, err)
+	require.Equal(t, MaintenancePolicyRefuse, policy)
+
+	_, err = ParseMaintenancePolicy("ignore")
+	require.True(t, trace.IsBadParameter(err))
+}
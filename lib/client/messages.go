@@ -0,0 +1,96 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gravitational/trace"
+)
+
+// MessageSeverity classifies user-facing messages emitted by the client.
+type MessageSeverity int
+
+const (
+	// SeverityInfo is for informational messages, e.g. command banners.
+	SeverityInfo MessageSeverity = iota
+	// SeverityWarning is for messages about surprising but non-fatal
+	// conditions.
+	SeverityWarning
+	// SeverityError is for messages about failures.
+	SeverityError
+)
+
+// MessageWriter receives the user-facing messages the client would otherwise
+// print to its standard streams. Embedding applications and machine-readable
+// output modes can provide one via Config to keep stdout clean.
+type MessageWriter interface {
+	// WriteUserMessage handles a single user-facing message. The message
+	// does not end in a newline.
+	WriteUserMessage(severity MessageSeverity, message string)
+}
+
+// stdStreamMessageWriter is the default MessageWriter: informational
+// messages go to stdout and warnings and errors to stderr, with the severity
+// spelled out and highlighted when the stream is a terminal.
+type stdStreamMessageWriter struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (w stdStreamMessageWriter) WriteUserMessage(severity MessageSeverity, message string) {
+	out := w.stdout
+	var prefix string
+	switch severity {
+	case SeverityWarning:
+		out, prefix = w.stderr, "WARNING"
+	case SeverityError:
+		out, prefix = w.stderr, "ERROR"
+	}
+	if prefix != "" {
+		if trace.IsTerminal(out) {
+			prefix = "\x1b[1m" + prefix + "\x1b[0m"
+		}
+		message = prefix + ": " + message
+	}
+	fmt.Fprintln(out, message)
+}
+
+// messageWriter returns the configured MessageWriter, falling back to the
+// client's standard streams.
+func (tc *TeleportClient) messageWriter() MessageWriter {
+	if tc.Config.MessageWriter != nil {
+		return tc.Config.MessageWriter
+	}
+	return stdStreamMessageWriter{stdout: tc.Stdout, stderr: tc.Stderr}
+}
+
+// Infof emits an informational user-facing message.
+func (tc *TeleportClient) Infof(format string, args ...interface{}) {
+	tc.messageWriter().WriteUserMessage(SeverityInfo, fmt.Sprintf(format, args...))
+}
+
+// Warningf emits a warning user-facing message.
+func (tc *TeleportClient) Warningf(format string, args ...interface{}) {
+	tc.messageWriter().WriteUserMessage(SeverityWarning, fmt.Sprintf(format, args...))
+}
+
+// Errorf emits an error user-facing message.
+func (tc *TeleportClient) Errorf(format string, args ...interface{}) {
+	tc.messageWriter().WriteUserMessage(SeverityError, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdStreamMessageWriter(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	w := stdStreamMessageWriter{stdout: &stdout, stderr: &stderr}
+
+	w.WriteUserMessage(SeverityInfo, "running command")
+	w.WriteUserMessage(SeverityWarning, "multiple nodes matched")
+	w.WriteUserMessage(SeverityError, "it broke")
+
+	// Info goes to stdout, warnings and errors to stderr. Buffers are not
+	// terminals, so the severity prefixes are not colored.
+	require.Equal(t, "running command\n", stdout.String())
+	require.Equal(t, "WARNING: multiple nodes matched\nERROR: it broke\n", stderr.String())
+}
+
+type capturedMessage struct {
+	severity MessageSeverity
+	message  string
+}
+
+// captureMessageWriter collects messages for assertions.
+type captureMessageWriter struct {
+	messages []capturedMessage
+}
+
+func (w *captureMessageWriter) WriteUserMessage(severity MessageSeverity, message string) {
+	w.messages = append(w.messages, capturedMessage{severity: severity, message: message})
+}
+
+func TestConfiguredMessageWriter(t *testing.T) {
+	t.Parallel()
+
+	capture := &captureMessageWriter{}
+	tc := &TeleportClient{Config: Config{MessageWriter: capture}}
+
+	tc.Warningf("picking first: %q", "node-a")
+	tc.Infof("running")
+
+	require.Equal(t, []capturedMessage{
+		{severity: SeverityWarning, message: `picking first: "node-a"`},
+		{severity: SeverityInfo, message: "running"},
+	}, capture.messages)
+}
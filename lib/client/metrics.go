@@ -0,0 +1,151 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io"
+
+	"github.com/gravitational/trace"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gravitational/teleport"
+)
+
+// ClientMetrics holds optional Prometheus metrics describing the client's
+// port forwarding and file transfer activity. Long-running daemons embedding
+// the library (CI runners, tunnels) can register them on their own registry
+// via NewClientMetrics and set them on Config.Metrics; a nil ClientMetrics
+// disables collection. All methods are safe to call on a nil receiver.
+type ClientMetrics struct {
+	activeForwards prometheus.Gauge
+	forwardedBytes prometheus.Counter
+	transferBytes  prometheus.Counter
+	sftpOperations *prometheus.CounterVec
+}
+
+// NewClientMetrics creates the client metrics and registers them on the
+// provided registerer. Pass prometheus.DefaultRegisterer to expose them on
+// the default registry.
+func NewClientMetrics(registerer prometheus.Registerer) (*ClientMetrics, error) {
+	m := &ClientMetrics{
+		activeForwards: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: teleport.MetricNamespace,
+			Name:      "client_port_forward_connections",
+			Help:      "Number of currently active port forwarding connections",
+		}),
+		forwardedBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: teleport.MetricNamespace,
+			Name:      "client_port_forward_bytes_total",
+			Help:      "Total number of bytes proxied through port forwarding, in both directions",
+		}),
+		transferBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: teleport.MetricNamespace,
+			Name:      "client_sftp_transfer_bytes_total",
+			Help:      "Total number of file bytes moved by SFTP transfers",
+		}),
+		sftpOperations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: teleport.MetricNamespace,
+			Name:      "client_sftp_operations_total",
+			Help:      "Number of SFTP transfer operations by result",
+		}, []string{"result"}),
+	}
+
+	for _, collector := range []prometheus.Collector{
+		m.activeForwards,
+		m.forwardedBytes,
+		m.transferBytes,
+		m.sftpOperations,
+	} {
+		if err := registerer.Register(collector); err != nil {
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				return nil, trace.Wrap(err)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// forwardStarted records a new active port forwarding connection.
+func (m *ClientMetrics) forwardStarted() {
+	if m != nil {
+		m.activeForwards.Inc()
+	}
+}
+
+// forwardDone records the end of a port forwarding connection.
+func (m *ClientMetrics) forwardDone() {
+	if m != nil {
+		m.activeForwards.Dec()
+	}
+}
+
+// addForwardedBytes records bytes proxied through port forwarding.
+func (m *ClientMetrics) addForwardedBytes(n int64) {
+	if m != nil && n > 0 {
+		m.forwardedBytes.Add(float64(n))
+	}
+}
+
+// recordSFTPOperation records the outcome of an SFTP transfer operation.
+func (m *ClientMetrics) recordSFTPOperation(err error) {
+	if m == nil {
+		return
+	}
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	m.sftpOperations.WithLabelValues(result).Inc()
+}
+
+// transferStream wraps an SFTP progress stream so the bytes moved by the
+// transfer are counted. The stream may be nil when no progress reporting was
+// requested.
+func (m *ClientMetrics) transferStream(stream io.ReadWriter) io.ReadWriter {
+	return &countingStream{metrics: m, stream: stream}
+}
+
+// countingStream counts the bytes written through an SFTP progress stream.
+type countingStream struct {
+	metrics *ClientMetrics
+	stream  io.ReadWriter
+}
+
+// Write counts progress updates on downloads, where the stream is a copy
+// destination for the transferred chunks.
+func (s *countingStream) Write(p []byte) (int, error) {
+	if s.metrics != nil && len(p) > 0 {
+		s.metrics.transferBytes.Add(float64(len(p)))
+	}
+	if s.stream != nil {
+		return s.stream.Write(p)
+	}
+	return len(p), nil
+}
+
+// Read counts progress updates on uploads, where the stream is handed the
+// transferred chunks in the read buffer.
+func (s *countingStream) Read(p []byte) (int, error) {
+	if s.metrics != nil && len(p) > 0 {
+		s.metrics.transferBytes.Add(float64(len(p)))
+	}
+	if s.stream != nil {
+		return s.stream.Read(p)
+	}
+	return len(p), nil
+}
@@ -70,6 +70,9 @@ type PromptMFAChallengeOpts struct {
 	AllowStdinHijack bool
 	// AuthenticatorAttachment specifies the desired authenticator attachment.
 	AuthenticatorAttachment wancli.AuthenticatorAttachment
+	// AllowedTransports restricts WebAuthn logins to authenticators reachable
+	// via the given transports. An empty slice allows all transports.
+	AllowedTransports []wancli.AuthenticatorTransport
 	// PreferOTP favors OTP challenges, if applicable.
 	// Takes precedence over AuthenticatorAttachment settings.
 	PreferOTP bool
@@ -97,6 +100,10 @@ func (tc *TeleportClient) PromptMFAChallenge(ctx context.Context, proxyAddr stri
 	)
 	defer span.End()
 
+	if err := tc.injectFault(ctx, FaultPointMFA); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	addr := proxyAddr
 	if addr == "" {
 		addr = tc.WebProxyAddr
@@ -104,13 +111,18 @@ func (tc *TeleportClient) PromptMFAChallenge(ctx context.Context, proxyAddr stri
 
 	opts := &PromptMFAChallengeOpts{
 		AuthenticatorAttachment: tc.AuthenticatorAttachment,
+		AllowedTransports:       tc.WebauthnTransports,
 		PreferOTP:               tc.PreferOTP,
 	}
 	if applyOpts != nil {
 		applyOpts(opts)
 	}
 
-	return promptMFAStandalone(ctx, c, addr, opts)
+	// Queue behind any concurrent ceremony so parallel connects don't race
+	// hardware prompts, sharing a single touch for identical challenges.
+	return tc.mfaCeremonies.do(ctx, mfaCeremonyKey(c), func(ctx context.Context) (*proto.MFAAuthenticateResponse, error) {
+		return promptMFAStandalone(ctx, c, addr, opts)
+	})
 }
 
 // PromptMFAChallenge prompts the user to complete MFA authentication
@@ -243,6 +255,7 @@ func PromptMFAChallenge(ctx context.Context, c *proto.MFAAuthenticateChallenge,
 
 			resp, _, err := promptWebauthn(ctx, origin, wanlib.CredentialAssertionFromProto(c.WebauthnChallenge), mfaPrompt, &wancli.LoginOpts{
 				AuthenticatorAttachment: opts.AuthenticatorAttachment,
+				AllowedTransports:       opts.AllowedTransports,
 			})
 			respC <- response{kind: "WEBAUTHN", resp: resp, err: err}
 		}()
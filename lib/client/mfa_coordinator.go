@@ -0,0 +1,153 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"sync"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/client/proto"
+)
+
+// mfaCeremonyCoordinator throttles MFA ceremonies across goroutines. Only
+// one ceremony runs at a time and pending ceremonies are granted in FIFO
+// order, so parallel node connects produce a deterministic sequence of
+// prompts instead of racing. Ceremonies for an identical challenge are
+// coalesced into a single prompt whose response is shared.
+//
+// The zero value is ready to use.
+type mfaCeremonyCoordinator struct {
+	mu sync.Mutex
+	// active is true while a ceremony is running.
+	active bool
+	// waiters are ceremonies queued behind the active one, in arrival order.
+	waiters []chan struct{}
+	// inflight tracks running or queued ceremonies by challenge fingerprint
+	// so identical challenges share one prompt.
+	inflight map[string]*inflightCeremony
+}
+
+// inflightCeremony is a ceremony whose result may be shared by multiple
+// callers. done is closed once resp and err are populated.
+type inflightCeremony struct {
+	done chan struct{}
+	resp *proto.MFAAuthenticateResponse
+	err  error
+}
+
+// mfaCeremonyKey returns a fingerprint identifying the challenge, or an
+// empty string if the challenge cannot be coalesced.
+func mfaCeremonyKey(c *proto.MFAAuthenticateChallenge) string {
+	if c.GetWebauthnChallenge().GetPublicKey() == nil {
+		return ""
+	}
+	return base64.RawStdEncoding.EncodeToString(c.GetWebauthnChallenge().GetPublicKey().GetChallenge())
+}
+
+// do runs fn as an MFA ceremony, queueing behind any ceremony already in
+// progress. If a ceremony with the same key is already running or queued,
+// its response is shared instead of prompting again.
+func (c *mfaCeremonyCoordinator) do(ctx context.Context, key string, fn func(ctx context.Context) (*proto.MFAAuthenticateResponse, error)) (*proto.MFAAuthenticateResponse, error) {
+	c.mu.Lock()
+	if key != "" {
+		if ceremony, ok := c.inflight[key]; ok {
+			c.mu.Unlock()
+			select {
+			case <-ceremony.done:
+				return ceremony.resp, ceremony.err
+			case <-ctx.Done():
+				return nil, trace.Wrap(ctx.Err())
+			}
+		}
+	}
+
+	var ceremony *inflightCeremony
+	if key != "" {
+		if c.inflight == nil {
+			c.inflight = make(map[string]*inflightCeremony)
+		}
+		ceremony = &inflightCeremony{done: make(chan struct{})}
+		c.inflight[key] = ceremony
+	}
+	c.mu.Unlock()
+
+	finish := func(resp *proto.MFAAuthenticateResponse, err error) (*proto.MFAAuthenticateResponse, error) {
+		if ceremony != nil {
+			c.mu.Lock()
+			delete(c.inflight, key)
+			c.mu.Unlock()
+			ceremony.resp, ceremony.err = resp, err
+			close(ceremony.done)
+		}
+		return resp, err
+	}
+
+	if err := c.acquire(ctx); err != nil {
+		return finish(nil, trace.Wrap(err))
+	}
+	defer c.release()
+
+	return finish(fn(ctx))
+}
+
+// acquire blocks until the caller may run its ceremony, granting slots in
+// FIFO order.
+func (c *mfaCeremonyCoordinator) acquire(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.active {
+		c.active = true
+		c.mu.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	c.waiters = append(c.waiters, ch)
+	c.mu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		for i, waiter := range c.waiters {
+			if waiter == ch {
+				c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+				c.mu.Unlock()
+				return trace.Wrap(ctx.Err())
+			}
+		}
+		c.mu.Unlock()
+		// The slot was granted concurrently with cancellation; give it back.
+		c.release()
+		return trace.Wrap(ctx.Err())
+	}
+}
+
+// release hands the slot to the next queued ceremony, if any.
+func (c *mfaCeremonyCoordinator) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.waiters) > 0 {
+		ch := c.waiters[0]
+		c.waiters = c.waiters[1:]
+		close(ch)
+		return
+	}
+	c.active = false
+}
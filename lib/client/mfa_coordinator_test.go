@@ -0,0 +1,121 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/client/proto"
+)
+
+func TestMFACeremonyCoordinatorSerializes(t *testing.T) {
+	t.Parallel()
+
+	var coordinator mfaCeremonyCoordinator
+	var running int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := coordinator.do(context.Background(), "", func(context.Context) (*proto.MFAAuthenticateResponse, error) {
+				require.Equal(t, int32(1), atomic.AddInt32(&running, 1))
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return &proto.MFAAuthenticateResponse{}, nil
+			})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMFACeremonyCoordinatorCoalesces(t *testing.T) {
+	t.Parallel()
+
+	var coordinator mfaCeremonyCoordinator
+	var prompts int32
+	release := make(chan struct{})
+	want := &proto.MFAAuthenticateResponse{}
+
+	fn := func(context.Context) (*proto.MFAAuthenticateResponse, error) {
+		atomic.AddInt32(&prompts, 1)
+		<-release
+		return want, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := coordinator.do(context.Background(), "same-challenge", fn)
+			require.NoError(t, err)
+			require.Same(t, want, resp)
+		}()
+	}
+
+	// Give the goroutines a chance to coalesce before releasing the prompt.
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&prompts) == 1
+	}, time.Second, 10*time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&prompts))
+}
+
+func TestMFACeremonyCoordinatorCancel(t *testing.T) {
+	t.Parallel()
+
+	var coordinator mfaCeremonyCoordinator
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	go func() {
+		_, err := coordinator.do(context.Background(), "", func(context.Context) (*proto.MFAAuthenticateResponse, error) {
+			close(started)
+			<-release
+			return &proto.MFAAuthenticateResponse{}, nil
+		})
+		require.NoError(t, err)
+	}()
+	<-started
+
+	// A queued ceremony gives up when its context is canceled.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := coordinator.do(ctx, "", func(context.Context) (*proto.MFAAuthenticateResponse, error) {
+		t.Error("ceremony should not have run")
+		return nil, nil
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+
+	// The coordinator remains usable afterwards.
+	_, err = coordinator.do(context.Background(), "", func(context.Context) (*proto.MFAAuthenticateResponse, error) {
+		return &proto.MFAAuthenticateResponse{}, nil
+	})
+	require.NoError(t, err)
+}
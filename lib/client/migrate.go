@@ -0,0 +1,254 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/profile"
+	"github.com/gravitational/teleport/api/utils/keypaths"
+)
+
+const (
+	// clientStoreVersion is the current layout version of the on-disk client
+	// store. Bump it when the layout changes in a way that requires
+	// migration of existing profiles, keys or known hosts.
+	clientStoreVersion = 1
+	// storeVersionFile is the marker file recording the layout version a
+	// client store directory was last written with. A missing file means
+	// the directory was written by a client that predates versioning.
+	storeVersionFile = ".store-version"
+)
+
+// MigrationReport describes what a client store migration changed, so
+// callers can surface it to the user instead of state silently moving
+// underneath them.
+type MigrationReport struct {
+	// FromDir is the directory state was migrated from.
+	FromDir string `json:"from_dir"`
+	// ToDir is the directory state was migrated to. Equal to FromDir for
+	// in-place format upgrades.
+	ToDir string `json:"to_dir"`
+	// FromVersion is the layout version of the source directory.
+	FromVersion int `json:"from_version"`
+	// ToVersion is the layout version after migration.
+	ToVersion int `json:"to_version"`
+	// MigratedProfiles are the names of the profiles that were copied.
+	MigratedProfiles []string `json:"migrated_profiles,omitempty"`
+	// MigratedKeys is true if the session key directory was copied.
+	MigratedKeys bool `json:"migrated_keys"`
+	// MigratedKnownHosts is true if the known hosts file was copied.
+	MigratedKnownHosts bool `json:"migrated_known_hosts"`
+}
+
+// IsNoop returns true if the migration found nothing to do.
+func (r *MigrationReport) IsNoop() bool {
+	return r.FromVersion == r.ToVersion &&
+		len(r.MigratedProfiles) == 0 &&
+		!r.MigratedKeys && !r.MigratedKnownHosts
+}
+
+// String returns a human-readable summary of the migration.
+func (r *MigrationReport) String() string {
+	if r.IsNoop() {
+		return "client store is up to date, nothing to migrate"
+	}
+	parts := []string{fmt.Sprintf("migrated client store from %v (layout v%d) to %v (layout v%d)",
+		r.FromDir, r.FromVersion, r.ToDir, r.ToVersion)}
+	if len(r.MigratedProfiles) > 0 {
+		parts = append(parts, fmt.Sprintf("profiles: %v", strings.Join(r.MigratedProfiles, ", ")))
+	}
+	if r.MigratedKeys {
+		parts = append(parts, "session keys")
+	}
+	if r.MigratedKnownHosts {
+		parts = append(parts, "known hosts")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// readStoreVersion returns the layout version recorded in dir, or zero if no
+// version marker exists.
+func readStoreVersion(dir string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, storeVersionFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, trace.ConvertSystemError(err)
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, trace.BadParameter("invalid client store version marker in %v: %v", dir, err)
+	}
+	return version, nil
+}
+
+// writeStoreVersion records the layout version in dir.
+func writeStoreVersion(dir string, version int) error {
+	err := os.WriteFile(filepath.Join(dir, storeVersionFile), []byte(strconv.Itoa(version)+"\n"), 0600)
+	return trace.ConvertSystemError(err)
+}
+
+// MigrateClientStore migrates client state (profiles, session keys and known
+// hosts) from one tsh directory to another and upgrades the layout version
+// marker. It never overwrites state already present in the destination, so
+// re-running it is safe, and each item is staged and renamed into place so a
+// crash cannot leave a half-written copy behind. Passing the same directory
+// for both arguments performs an in-place format upgrade only.
+func MigrateClientStore(fromDir, toDir string) (*MigrationReport, error) {
+	fromDir = profile.FullProfilePath(fromDir)
+	toDir = profile.FullProfilePath(toDir)
+
+	fromVersion, err := readStoreVersion(fromDir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	report := &MigrationReport{
+		FromDir:     fromDir,
+		ToDir:       toDir,
+		FromVersion: fromVersion,
+		ToVersion:   clientStoreVersion,
+	}
+
+	if err := os.MkdirAll(toDir, os.ModeDir|profileDirPerms); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+
+	if fromDir != toDir {
+		// Profiles are top-level <name>.yaml files.
+		entries, err := os.ReadDir(fromDir)
+		if err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+				continue
+			}
+			copied, err := copyStorePath(filepath.Join(fromDir, entry.Name()), filepath.Join(toDir, entry.Name()))
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			if copied {
+				report.MigratedProfiles = append(report.MigratedProfiles,
+					strings.TrimSuffix(entry.Name(), ".yaml"))
+			}
+		}
+
+		report.MigratedKeys, err = copyStorePath(keypaths.KeyDir(fromDir), keypaths.KeyDir(toDir))
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		report.MigratedKnownHosts, err = copyStorePath(keypaths.KnownHostsPath(fromDir), keypaths.KnownHostsPath(toDir))
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
+	if err := writeStoreVersion(toDir, clientStoreVersion); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return report, nil
+}
+
+// copyStorePath copies a file or directory tree from src to dst, staging the
+// copy next to dst and renaming it into place so readers never observe a
+// partial copy. It returns false without copying if src does not exist or
+// dst already does.
+func copyStorePath(src, dst string) (bool, error) {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, trace.ConvertSystemError(err)
+	}
+	if _, err := os.Stat(dst); err == nil {
+		// Never clobber existing destination state.
+		return false, nil
+	} else if !os.IsNotExist(err) {
+		return false, trace.ConvertSystemError(err)
+	}
+
+	staging := dst + ".migrating"
+	if err := os.RemoveAll(staging); err != nil {
+		return false, trace.ConvertSystemError(err)
+	}
+	if srcInfo.IsDir() {
+		err = copyDir(src, staging)
+	} else {
+		err = copyFile(src, staging, srcInfo.Mode())
+	}
+	if err != nil {
+		os.RemoveAll(staging)
+		return false, trace.Wrap(err)
+	}
+	if err := os.Rename(staging, dst); err != nil {
+		os.RemoveAll(staging)
+		return false, trace.ConvertSystemError(err)
+	}
+	return true, nil
+}
+
+// copyDir recursively copies a directory tree, preserving file modes.
+func copyDir(src, dst string) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if err := os.MkdirAll(dst, srcInfo.Mode()); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	for _, entry := range entries {
+		srcPath := filepath.Join(src, entry.Name())
+		dstPath := filepath.Join(dst, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		if entry.IsDir() {
+			if err := copyDir(srcPath, dstPath); err != nil {
+				return trace.Wrap(err)
+			}
+			continue
+		}
+		if err := copyFile(srcPath, dstPath, info.Mode()); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// copyFile copies a single file with the given mode.
+func copyFile(src, dst string, mode os.FileMode) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return trace.ConvertSystemError(os.WriteFile(dst, data, mode))
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/utils/keypaths"
+)
+
+func TestMigrateClientStore(t *testing.T) {
+	t.Parallel()
+
+	fromDir := t.TempDir()
+	toDir := t.TempDir()
+
+	// Lay out a legacy store: profile, session keys and known hosts,
+	// without a version marker.
+	require.NoError(t, os.WriteFile(filepath.Join(fromDir, "proxy.example.com.yaml"), []byte("web_proxy_addr: proxy.example.com:3080\n"), 0600))
+	keyPath := keypaths.UserKeyPath(fromDir, "proxy.example.com", "alice")
+	require.NoError(t, os.MkdirAll(filepath.Dir(keyPath), 0700))
+	require.NoError(t, os.WriteFile(keyPath, []byte("key material"), 0600))
+	require.NoError(t, os.WriteFile(keypaths.KnownHostsPath(fromDir), []byte("@cert-authority *.example.com ssh-rsa AAAA\n"), 0600))
+
+	report, err := MigrateClientStore(fromDir, toDir)
+	require.NoError(t, err)
+	require.Equal(t, 0, report.FromVersion)
+	require.Equal(t, clientStoreVersion, report.ToVersion)
+	require.Equal(t, []string{"proxy.example.com"}, report.MigratedProfiles)
+	require.True(t, report.MigratedKeys)
+	require.True(t, report.MigratedKnownHosts)
+	require.False(t, report.IsNoop())
+
+	// Everything arrived, including nested key material.
+	data, err := os.ReadFile(keypaths.UserKeyPath(toDir, "proxy.example.com", "alice"))
+	require.NoError(t, err)
+	require.Equal(t, "key material", string(data))
+	_, err = os.Stat(keypaths.KnownHostsPath(toDir))
+	require.NoError(t, err)
+
+	// The destination is now marked with the current layout version.
+	version, err := readStoreVersion(toDir)
+	require.NoError(t, err)
+	require.Equal(t, clientStoreVersion, version)
+
+	// Re-running the migration does not clobber the destination.
+	require.NoError(t, os.WriteFile(filepath.Join(toDir, "proxy.example.com.yaml"), []byte("modified"), 0600))
+	report, err = MigrateClientStore(fromDir, toDir)
+	require.NoError(t, err)
+	require.Empty(t, report.MigratedProfiles)
+	data, err = os.ReadFile(filepath.Join(toDir, "proxy.example.com.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "modified", string(data))
+}
+
+func TestMigrateClientStoreInPlace(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	report, err := MigrateClientStore(dir, dir)
+	require.NoError(t, err)
+	require.Equal(t, 0, report.FromVersion)
+	require.Equal(t, clientStoreVersion, report.ToVersion)
+	require.Empty(t, report.MigratedProfiles)
+	require.False(t, report.IsNoop())
+
+	// A second run is a no-op with a readable summary.
+	report, err = MigrateClientStore(dir, dir)
+	require.NoError(t, err)
+	require.True(t, report.IsNoop())
+	require.Equal(t, "client store is up to date, nothing to migrate", report.String())
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// MultiForwardRequest describes local forwards to the same port on every
+// node matching a label selector, e.g. forward port 9100 of all
+// prom-exporter nodes to sequential local ports.
+type MultiForwardRequest struct {
+	// Labels selects the nodes to forward to.
+	Labels map[string]string
+	// RemotePort is the port forwarded to on each matching node.
+	RemotePort int
+	// LocalHost is the address local listeners bind to, "127.0.0.1" when
+	// empty.
+	LocalHost string
+	// LocalPortBase is the first local port to bind; each matching node
+	// takes the next sequential port. Zero picks free ports automatically.
+	LocalPortBase int
+}
+
+// CheckAndSetDefaults validates the request and fills in defaults.
+func (req *MultiForwardRequest) CheckAndSetDefaults() error {
+	if len(req.Labels) == 0 {
+		return trace.BadParameter("missing label selector")
+	}
+	if req.RemotePort < 1 || req.RemotePort > 65535 {
+		return trace.BadParameter("invalid remote port %v", req.RemotePort)
+	}
+	if req.LocalPortBase < 0 || req.LocalPortBase > 65535 {
+		return trace.BadParameter("invalid local port base %v", req.LocalPortBase)
+	}
+	if req.LocalHost == "" {
+		req.LocalHost = "127.0.0.1"
+	}
+	return nil
+}
+
+// NodeForward maps one matching node to its local listener.
+type NodeForward struct {
+	// Node is the name of the node.
+	Node string
+	// Hostname is the node's hostname.
+	Hostname string
+	// LocalAddr is the local listener address forwarding to the node.
+	LocalAddr string
+}
+
+// ForwardToMatchingNodes sets up a local forward to the requested port on
+// every node matching the request's label selector and returns the
+// node-to-listener mapping. The forwards stay up until the context is
+// canceled.
+func (tc *TeleportClient) ForwardToMatchingNodes(ctx context.Context, req MultiForwardRequest) ([]NodeForward, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/ForwardToMatchingNodes",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	if err := req.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	filter := tc.DefaultResourceFilter()
+	filter.Labels = req.Labels
+	nodes, err := proxyClient.FindNodesByFilters(ctx, *filter)
+	if err != nil {
+		proxyClient.Close()
+		return nil, trace.Wrap(err)
+	}
+	if len(nodes) == 0 {
+		proxyClient.Close()
+		return nil, trace.NotFound("no nodes matched labels %v", req.Labels)
+	}
+
+	// Connections and listeners accumulated so far are torn down when any
+	// node fails, so the caller never gets a partial set of forwards.
+	var closers []func() error
+	closeAll := func() {
+		for _, close := range closers {
+			close()
+		}
+		proxyClient.Close()
+	}
+
+	forwards := make([]NodeForward, 0, len(nodes))
+	remoteAddr := net.JoinHostPort("127.0.0.1", strconv.Itoa(req.RemotePort))
+	for i, node := range nodes {
+		nodeClient, err := tc.ConnectToNode(
+			ctx,
+			proxyClient,
+			NodeDetails{Addr: node.GetName() + ":0", Namespace: tc.Namespace, Cluster: tc.SiteName},
+			tc.Config.HostLogin,
+		)
+		if err != nil {
+			closeAll()
+			return nil, trace.Wrap(err, "connecting to node %v", node.GetHostname())
+		}
+		closers = append(closers, nodeClient.Close)
+
+		localPort := 0
+		if req.LocalPortBase != 0 {
+			localPort = req.LocalPortBase + i
+		}
+		listener, err := net.Listen("tcp", net.JoinHostPort(req.LocalHost, strconv.Itoa(localPort)))
+		if err != nil {
+			closeAll()
+			return nil, trace.Wrap(err, "binding local listener for node %v", node.GetHostname())
+		}
+		closers = append(closers, listener.Close)
+
+		go nodeClient.listenAndForward(ctx, listener, listener.Addr().String(), "tcp", remoteAddr)
+		forwards = append(forwards, NodeForward{
+			Node:      node.GetName(),
+			Hostname:  node.GetHostname(),
+			LocalAddr: listener.Addr().String(),
+		})
+	}
+
+	// The proxy and node connections must outlive this call; tear them
+	// down once the caller's context is done.
+	go func() {
+		<-ctx.Done()
+		closeAll()
+	}()
+
+	return forwards, nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiForwardRequestCheckAndSetDefaults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		req       MultiForwardRequest
+		assertErr require.ErrorAssertionFunc
+		localHost string
+	}{
+		{
+			name: "valid with defaults",
+			req: MultiForwardRequest{
+				Labels:     map[string]string{"role": "prom-exporter"},
+				RemotePort: 9100,
+			},
+			assertErr: require.NoError,
+			localHost: "127.0.0.1",
+		},
+		{
+			name: "local host preserved",
+			req: MultiForwardRequest{
+				Labels:     map[string]string{"role": "prom-exporter"},
+				RemotePort: 9100,
+				LocalHost:  "0.0.0.0",
+			},
+			assertErr: require.NoError,
+			localHost: "0.0.0.0",
+		},
+		{
+			name: "missing labels",
+			req: MultiForwardRequest{
+				RemotePort: 9100,
+			},
+			assertErr: require.Error,
+		},
+		{
+			name: "invalid remote port",
+			req: MultiForwardRequest{
+				Labels:     map[string]string{"role": "prom-exporter"},
+				RemotePort: 0,
+			},
+			assertErr: require.Error,
+		},
+		{
+			name: "invalid local port base",
+			req: MultiForwardRequest{
+				Labels:        map[string]string{"role": "prom-exporter"},
+				RemotePort:    9100,
+				LocalPortBase: 70000,
+			},
+			assertErr: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.CheckAndSetDefaults()
+			tt.assertErr(t, err)
+			if err == nil {
+				require.Equal(t, tt.localHost, tt.req.LocalHost)
+			}
+		})
+	}
+}
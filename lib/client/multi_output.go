@@ -0,0 +1,168 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/gravitational/trace"
+)
+
+// MultiNodeOutputMode selects how output from multi-node command execution is
+// presented.
+type MultiNodeOutputMode string
+
+const (
+	// MultiNodeOutputInterleaved streams output from all nodes as it
+	// arrives, without origin information. This is the historical default.
+	MultiNodeOutputInterleaved MultiNodeOutputMode = ""
+	// MultiNodeOutputPrefixed streams output as it arrives with each line
+	// prefixed by the originating host, in the style of pssh.
+	MultiNodeOutputPrefixed MultiNodeOutputMode = "prefixed"
+	// MultiNodeOutputBuffered buffers each node's output and emits it in
+	// host order once the run completes, so nodes never interleave.
+	MultiNodeOutputBuffered MultiNodeOutputMode = "buffered"
+)
+
+// ParseMultiNodeOutputMode parses the user-facing name of a multi-node output
+// mode.
+func ParseMultiNodeOutputMode(s string) (MultiNodeOutputMode, error) {
+	switch mode := MultiNodeOutputMode(s); mode {
+	case MultiNodeOutputInterleaved, MultiNodeOutputPrefixed, MultiNodeOutputBuffered:
+		return mode, nil
+	default:
+		return MultiNodeOutputInterleaved, trace.BadParameter(
+			"invalid output mode %q, expected one of %q or %q", s, MultiNodeOutputPrefixed, MultiNodeOutputBuffered)
+	}
+}
+
+// multiNodeOutput fans per-node command output into the client's stdout and
+// stderr according to the configured MultiNodeOutputMode.
+type multiNodeOutput struct {
+	mode   MultiNodeOutputMode
+	stdout io.Writer
+	stderr io.Writer
+
+	// mu serializes writes in prefixed mode, so lines from different nodes
+	// never mix, and guards buffers.
+	mu sync.Mutex
+	// buffers holds per-host captured output in buffered mode.
+	buffers map[string]*nodeOutputBuffer
+}
+
+// nodeOutputBuffer captures one node's stdout and stderr for later emission.
+type nodeOutputBuffer struct {
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+}
+
+func newMultiNodeOutput(mode MultiNodeOutputMode, stdout, stderr io.Writer) *multiNodeOutput {
+	return &multiNodeOutput{
+		mode:    mode,
+		stdout:  stdout,
+		stderr:  stderr,
+		buffers: make(map[string]*nodeOutputBuffer),
+	}
+}
+
+// writersFor returns the stdout and stderr writers a node's command should
+// use, and a flush function to call once the command finishes.
+func (o *multiNodeOutput) writersFor(host string) (stdout, stderr io.Writer, flush func()) {
+	switch o.mode {
+	case MultiNodeOutputPrefixed:
+		out := &hostPrefixWriter{mu: &o.mu, w: o.stdout, prefix: fmt.Sprintf("[%v] ", host)}
+		errw := &hostPrefixWriter{mu: &o.mu, w: o.stderr, prefix: fmt.Sprintf("[%v] ", host)}
+		return out, errw, func() {
+			out.flush()
+			errw.flush()
+		}
+	case MultiNodeOutputBuffered:
+		buf := &nodeOutputBuffer{}
+		o.mu.Lock()
+		o.buffers[host] = buf
+		o.mu.Unlock()
+		// Writes go to per-node buffers, nothing to flush until emit.
+		return &buf.stdout, &buf.stderr, func() {}
+	default:
+		return o.stdout, o.stderr, func() {}
+	}
+}
+
+// emit writes out the buffered per-node output in host order. It is a no-op
+// outside of buffered mode and must only be called after all commands have
+// finished.
+func (o *multiNodeOutput) emit() {
+	if o.mode != MultiNodeOutputBuffered {
+		return
+	}
+	hosts := make([]string, 0, len(o.buffers))
+	for host := range o.buffers {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		buf := o.buffers[host]
+		fmt.Fprintf(o.stdout, "=== %v ===\n", host)
+		io.Copy(o.stdout, &buf.stdout)
+		io.Copy(o.stderr, &buf.stderr)
+	}
+}
+
+// hostPrefixWriter prefixes every line written through it with the
+// originating host, buffering partial lines until a newline arrives so
+// concurrent writers never split a line.
+type hostPrefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	// partial accumulates bytes of the current, not yet terminated line.
+	partial []byte
+}
+
+func (w *hostPrefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		if _, err := fmt.Fprintf(w.w, "%s%s\n", w.prefix, w.partial[:i]); err != nil {
+			return 0, trace.Wrap(err)
+		}
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush emits any trailing output that did not end in a newline.
+func (w *hostPrefixWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.partial) == 0 {
+		return
+	}
+	fmt.Fprintf(w.w, "%s%s\n", w.prefix, w.partial)
+	w.partial = nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostPrefixWriter(t *testing.T) {
+	t.Parallel()
+
+	var sink bytes.Buffer
+	output := newMultiNodeOutput(MultiNodeOutputPrefixed, &sink, &sink)
+
+	stdout, _, flush := output.writersFor("node-a")
+
+	// Partial writes only show up once the line is terminated.
+	_, err := stdout.Write([]byte("hel"))
+	require.NoError(t, err)
+	require.Empty(t, sink.String())
+
+	_, err = stdout.Write([]byte("lo\nwor"))
+	require.NoError(t, err)
+	require.Equal(t, "[node-a] hello\n", sink.String())
+
+	// Flush emits the trailing unterminated line.
+	flush()
+	require.Equal(t, "[node-a] hello\n[node-a] wor\n", sink.String())
+}
+
+func TestMultiNodeOutputBuffered(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	output := newMultiNodeOutput(MultiNodeOutputBuffered, &stdout, &stderr)
+
+	outB, errB, flushB := output.writersFor("node-b")
+	outA, errA, flushA := output.writersFor("node-a")
+
+	// Writes arrive interleaved across nodes.
+	outB.Write([]byte("b stdout\n"))
+	outA.Write([]byte("a stdout\n"))
+	errA.Write([]byte("a stderr\n"))
+	errB.Write([]byte("b stderr\n"))
+	flushB()
+	flushA()
+
+	// Nothing is written until emit.
+	require.Empty(t, stdout.String())
+	require.Empty(t, stderr.String())
+
+	output.emit()
+	require.Equal(t, "=== node-a ===\na stdout\n=== node-b ===\nb stdout\n", stdout.String())
+	require.Equal(t, "a stderr\nb stderr\n", stderr.String())
+}
+
+func TestMultiNodeOutputInterleaved(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	output := newMultiNodeOutput(MultiNodeOutputInterleaved, &stdout, &stderr)
+
+	out, errw, flush := output.writersFor("node-a")
+	out.Write([]byte("plain stdout\n"))
+	errw.Write([]byte("plain stderr\n"))
+	flush()
+	output.emit()
+
+	require.Equal(t, "plain stdout\n", stdout.String())
+	require.Equal(t, "plain stderr\n", stderr.String())
+}
+
+func TestParseMultiNodeOutputMode(t *testing.T) {
+	t.Parallel()
+
+	mode, err := ParseMultiNodeOutputMode("prefixed")
+	require.NoError(t, err)
+	require.Equal(t, MultiNodeOutputPrefixed, mode)
+
+	mode, err = ParseMultiNodeOutputMode("buffered")
+	require.NoError(t, err)
+	require.Equal(t, MultiNodeOutputBuffered, mode)
+
+	mode, err = ParseMultiNodeOutputMode("")
+	require.NoError(t, err)
+	require.Equal(t, MultiNodeOutputInterleaved, mode)
+
+	_, err = ParseMultiNodeOutputMode("rainbow")
+	require.True(t, trace.IsBadParameter(err))
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+// Fields a node picker query can match against.
+const (
+	// NodeMatchFieldHostname indicates the query matched the node hostname.
+	NodeMatchFieldHostname = "hostname"
+	// NodeMatchFieldAddress indicates the query matched the node address.
+	NodeMatchFieldAddress = "addr"
+	// NodeMatchFieldLabel indicates the query matched a label value.
+	NodeMatchFieldLabel = "label"
+)
+
+// NodeMatch is a node ranked against a picker query, carrying enough
+// metadata for a frontend to highlight why it matched.
+type NodeMatch struct {
+	// Server is the matched node.
+	Server types.Server
+	// Score is the fuzzy match score; higher is better. Matches are only
+	// comparable within the results of a single query.
+	Score int
+	// Field is the field the best match was found in, one of the
+	// NodeMatchField* constants.
+	Field string
+	// Label is the name of the matched label when Field is
+	// NodeMatchFieldLabel.
+	Label string
+	// Value is the value of the matched field.
+	Value string
+	// Positions are the rune indexes in Value the query matched, for
+	// highlighting.
+	Positions []int
+}
+
+// fuzzyMatch matches query as a case-insensitive subsequence of target,
+// fzf-style. It returns the match score and the rune indexes of the matched
+// characters; ok is false if the query is not a subsequence of the target.
+// Consecutive matches and matches on word boundaries score higher, and
+// shorter targets win ties.
+func fuzzyMatch(query, target string) (score int, positions []int, ok bool) {
+	if query == "" {
+		return 0, nil, true
+	}
+	qRunes := []rune(strings.ToLower(query))
+	tRunes := []rune(target)
+
+	qi := 0
+	prevMatched := false
+	for ti, r := range tRunes {
+		if qi == len(qRunes) {
+			break
+		}
+		if unicode.ToLower(r) != qRunes[qi] {
+			prevMatched = false
+			continue
+		}
+		score++
+		if prevMatched {
+			// Runs of consecutive matches are the strongest signal.
+			score += 2
+		}
+		if ti == 0 || isWordBoundary(tRunes[ti-1]) {
+			score += 2
+		}
+		positions = append(positions, ti)
+		prevMatched = true
+		qi++
+	}
+	if qi != len(qRunes) {
+		return 0, nil, false
+	}
+	// Prefer shorter targets, so "db" ranks "db1" above "dashboard".
+	if len(tRunes) < 16 {
+		score += 16 - len(tRunes)
+	}
+	return score, positions, true
+}
+
+// isWordBoundary returns true if the preceding rune starts a new word for
+// fuzzy match bonus purposes.
+func isWordBoundary(prev rune) bool {
+	return prev == '-' || prev == '_' || prev == '.' || prev == '/' || prev == ' '
+}
+
+// matchNode ranks a single node against the query, returning the best match
+// across its hostname, address and label values.
+func matchNode(query string, node types.Server) (NodeMatch, bool) {
+	best := NodeMatch{Server: node, Score: -1}
+
+	consider := func(field, label, value string) {
+		if value == "" {
+			return
+		}
+		score, positions, ok := fuzzyMatch(query, value)
+		if !ok || score <= best.Score {
+			return
+		}
+		best.Score = score
+		best.Field = field
+		best.Label = label
+		best.Value = value
+		best.Positions = positions
+	}
+
+	consider(NodeMatchFieldHostname, "", node.GetHostname())
+	consider(NodeMatchFieldAddress, "", node.GetAddr())
+	for name, value := range node.GetAllLabels() {
+		consider(NodeMatchFieldLabel, name, value)
+	}
+
+	if best.Score < 0 {
+		return NodeMatch{}, false
+	}
+	return best, true
+}
+
+// RankNodes ranks the given nodes against a fuzzy picker query, dropping
+// nodes that do not match. Results are sorted by descending score, with
+// hostname as a tiebreak for stable output. An empty query matches every
+// node with a zero score.
+func RankNodes(query string, nodes []types.Server) []NodeMatch {
+	var matches []NodeMatch
+	for _, node := range nodes {
+		if match, ok := matchNode(query, node); ok {
+			matches = append(matches, match)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Server.GetHostname() < matches[j].Server.GetHostname()
+	})
+	return matches
+}
+
+// SearchNodes returns the nodes visible to the user ranked by fuzzy match
+// against the query, to power fzf-style interactive pickers. The node list
+// is fetched with the client's default resource filter, so any configured
+// label selectors or search keywords still apply.
+func (tc *TeleportClient) SearchNodes(ctx context.Context, query string) ([]NodeMatch, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/SearchNodes",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	nodes, err := tc.ListNodesWithFilters(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return RankNodes(query, nodes), nil
+}
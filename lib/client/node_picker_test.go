@@ -0,0 +1,102 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+func nodeForTest(t *testing.T, hostname, addr string, labels map[string]string) types.Server {
+	t.Helper()
+	node, err := types.NewServerWithLabels(hostname, types.KindNode, types.ServerSpecV2{
+		Hostname: hostname,
+		Addr:     addr,
+	}, labels)
+	require.NoError(t, err)
+	return node
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	t.Parallel()
+
+	// Non-subsequence queries do not match.
+	_, _, ok := fuzzyMatch("xyz", "web-1")
+	require.False(t, ok)
+
+	// Matching is case-insensitive and returns highlight positions.
+	score, positions, ok := fuzzyMatch("WEB", "web-1")
+	require.True(t, ok)
+	require.Positive(t, score)
+	require.Equal(t, []int{0, 1, 2}, positions)
+
+	// Consecutive matches beat scattered ones.
+	consecutive, _, ok := fuzzyMatch("web", "web-server")
+	require.True(t, ok)
+	scattered, _, ok := fuzzyMatch("web", "worker-east-b")
+	require.True(t, ok)
+	require.Greater(t, consecutive, scattered)
+
+	// An empty query matches everything.
+	_, _, ok = fuzzyMatch("", "anything")
+	require.True(t, ok)
+}
+
+func TestRankNodes(t *testing.T) {
+	t.Parallel()
+
+	nodes := []types.Server{
+		nodeForTest(t, "db-1", "10.0.0.1:3022", map[string]string{"env": "prod"}),
+		nodeForTest(t, "dashboard", "10.0.0.2:3022", map[string]string{"env": "staging"}),
+		nodeForTest(t, "web-1", "10.0.0.3:3022", map[string]string{"role": "db-replica"}),
+	}
+
+	// "db" ranks the short hostname first; web-1 still matches through its
+	// label value.
+	matches := RankNodes("db", nodes)
+	require.Len(t, matches, 3)
+	require.Equal(t, "db-1", matches[0].Server.GetHostname())
+	require.Equal(t, NodeMatchFieldHostname, matches[0].Field)
+	require.Equal(t, []int{0, 1}, matches[0].Positions)
+
+	var fields []string
+	for _, m := range matches {
+		if m.Server.GetHostname() == "web-1" {
+			fields = append(fields, m.Field, m.Label)
+		}
+	}
+	require.Equal(t, []string{NodeMatchFieldLabel, "role"}, fields)
+
+	// Address matching: the exact prefix outranks nodes that only match as
+	// a scattered subsequence.
+	matches = RankNodes("10.0.0.2", nodes)
+	require.NotEmpty(t, matches)
+	require.Equal(t, "dashboard", matches[0].Server.GetHostname())
+	require.Equal(t, NodeMatchFieldAddress, matches[0].Field)
+
+	// Nodes that match nothing are dropped.
+	matches = RankNodes("zzz", nodes)
+	require.Empty(t, matches)
+
+	// Empty query returns everything, sorted by hostname.
+	matches = RankNodes("", nodes)
+	require.Len(t, matches, 3)
+	require.Equal(t, "dashboard", matches[0].Server.GetHostname())
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/session"
+)
+
+// ListSessionParticipants returns the participants of an active session,
+// including the mode each joined with.
+func (tc *TeleportClient) ListSessionParticipants(ctx context.Context, sid session.ID) ([]types.Participant, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/ListSessionParticipants",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	tracker, err := proxyClient.CurrentCluster().GetSessionTracker(ctx, sid.String())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return tracker.GetParticipants(), nil
+}
+
+// InviteSessionParticipant adds the given user to an active session's tracker
+// in the given mode. Only the session originator may invite; the updated
+// tracker propagates to watchers, so invited users see the session appear in
+// their active session listings. The invitee still joins through the normal
+// join flow, where their own RBAC is enforced.
+func (tc *TeleportClient) InviteSessionParticipant(ctx context.Context, sid session.ID, user string, mode types.SessionParticipantMode) (types.Participant, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/InviteSessionParticipant",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	switch mode {
+	case types.SessionPeerMode, types.SessionObserverMode, types.SessionModeratorMode:
+	default:
+		return types.Participant{}, trace.BadParameter("invalid participant mode %q", mode)
+	}
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return types.Participant{}, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+	clt := proxyClient.CurrentCluster()
+
+	tracker, err := clt.GetSessionTracker(ctx, sid.String())
+	if err != nil {
+		return types.Participant{}, trace.Wrap(err)
+	}
+	if tracker.GetHostUser() != tc.Username {
+		return types.Participant{}, trace.AccessDenied("only the session originator %q may invite participants", tracker.GetHostUser())
+	}
+	for _, p := range tracker.GetParticipants() {
+		if p.User == user {
+			return types.Participant{}, trace.AlreadyExists("user %q is already a participant of session %v", user, sid)
+		}
+	}
+
+	participant := types.Participant{
+		ID:         uuid.New().String(),
+		User:       user,
+		Mode:       string(mode),
+		LastActive: time.Now().UTC(),
+	}
+	err = clt.UpdateSessionTracker(ctx, &proto.UpdateSessionTrackerRequest{
+		SessionID: sid.String(),
+		Update: &proto.UpdateSessionTrackerRequest_AddParticipant{
+			AddParticipant: &proto.SessionTrackerAddParticipant{
+				Participant: &participant,
+			},
+		},
+	})
+	if err != nil {
+		return types.Participant{}, trace.Wrap(err)
+	}
+	return participant, nil
+}
+
+// RemoveSessionParticipant removes the named user from an active session's
+// tracker. The session originator may remove anyone; other users may only
+// remove themselves. Server-side RBAC still applies to the tracker update.
+func (tc *TeleportClient) RemoveSessionParticipant(ctx context.Context, sid session.ID, user string) error {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/RemoveSessionParticipant",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+	clt := proxyClient.CurrentCluster()
+
+	tracker, err := clt.GetSessionTracker(ctx, sid.String())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if tracker.GetHostUser() != tc.Username && user != tc.Username {
+		return trace.AccessDenied("only the session originator %q may remove other participants", tracker.GetHostUser())
+	}
+
+	var participantID string
+	for _, p := range tracker.GetParticipants() {
+		if p.User == user {
+			participantID = p.ID
+			break
+		}
+	}
+	if participantID == "" {
+		return trace.NotFound("user %q is not a participant of session %v", user, sid)
+	}
+
+	err = clt.UpdateSessionTracker(ctx, &proto.UpdateSessionTrackerRequest{
+		SessionID: sid.String(),
+		Update: &proto.UpdateSessionTrackerRequest_RemoveParticipant{
+			RemoveParticipant: &proto.SessionTrackerRemoveParticipant{
+				ParticipantID: participantID,
+			},
+		},
+	})
+	return trace.Wrap(err)
+}
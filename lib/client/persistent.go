@@ -0,0 +1,77 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+)
+
+// persistentProxyClient returns the shared proxy connection, dialing or
+// rebuilding it as needed. The returned client's Close is a no-op so callers
+// can keep their usual defer proxyClient.Close() without tearing the shared
+// connection down; use ClosePersistentConnection to release it for real.
+func (tc *TeleportClient) persistentProxyClient(ctx context.Context) (*ProxyClient, error) {
+	tc.persistentProxyMu.Lock()
+	defer tc.persistentProxyMu.Unlock()
+
+	if tc.persistentProxy != nil {
+		select {
+		case <-tc.persistentProxyDone:
+			// The underlying SSH connection died; drop the stale client and
+			// fall through to rebuild.
+			tc.persistentProxy.close()
+			tc.persistentProxy = nil
+		default:
+			return tc.persistentProxy, nil
+		}
+	}
+
+	proxyClient, err := tc.newProxyClient(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	proxyClient.persistent = true
+
+	// Watch the SSH connection so the next caller rebuilds instead of
+	// receiving a dead client.
+	done := make(chan struct{})
+	go func() {
+		proxyClient.Client.Wait()
+		close(done)
+	}()
+
+	tc.persistentProxy = proxyClient
+	tc.persistentProxyDone = done
+	return proxyClient, nil
+}
+
+// ClosePersistentConnection tears down the shared proxy connection, if any.
+// Safe to call multiple times and when PersistentConnection is off.
+func (tc *TeleportClient) ClosePersistentConnection() error {
+	tc.persistentProxyMu.Lock()
+	defer tc.persistentProxyMu.Unlock()
+
+	if tc.persistentProxy == nil {
+		return nil
+	}
+	err := tc.persistentProxy.close()
+	tc.persistentProxy = nil
+	tc.persistentProxyDone = nil
+	return trace.Wrap(err)
+}
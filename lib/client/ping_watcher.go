@@ -0,0 +1,85 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/client/webclient"
+)
+
+// PingChangeFunc is invoked by WatchProxySettings when the proxy settings
+// advertised by the ping endpoint differ from the previously observed ones.
+// old is nil on the very first observation when no response was cached yet.
+type PingChangeFunc func(old, new *webclient.PingResponse)
+
+// WatchProxySettings polls the proxy ping endpoint every interval and invokes
+// onChange whenever the advertised settings differ from the previous
+// observation, letting long-lived clients react to proxy reconfiguration
+// (e.g. listener address changes or TLS routing toggles). It blocks until ctx
+// is canceled; run it in its own goroutine.
+//
+// The watcher deliberately does not touch the client's ping cache or apply
+// settings, since TeleportClient is not safe for concurrent use. Callers
+// reacting to a change should call InvalidatePing and Ping from the goroutine
+// that owns the client.
+func (tc *TeleportClient) WatchProxySettings(ctx context.Context, interval time.Duration, onChange PingChangeFunc) error {
+	if interval <= 0 {
+		return trace.BadParameter("refresh interval must be positive")
+	}
+	if onChange == nil {
+		return trace.BadParameter("missing change callback")
+	}
+
+	// Capture everything the poller needs up front so the goroutine never
+	// reads mutable client state.
+	cfg := &webclient.Config{
+		ProxyAddr:     tc.WebProxyAddr,
+		Insecure:      tc.InsecureSkipVerify,
+		Pool:          loopbackPool(tc.WebProxyAddr),
+		ConnectorName: tc.AuthConnector,
+		ExtraHeaders:  tc.ExtraProxyHeaders,
+	}
+	previous := tc.lastPing
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			pollCfg := *cfg
+			pollCfg.Context = ctx
+			pr, err := webclient.Ping(&pollCfg)
+			if err != nil {
+				log.WithError(err).Debug("Proxy settings refresh failed.")
+				continue
+			}
+			if previous != nil && reflect.DeepEqual(previous, pr) {
+				continue
+			}
+			onChange(previous, pr)
+			previous = pr
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -45,6 +45,38 @@ const (
 	statePlaying
 )
 
+// PlaybackWatermark configures an optional watermark rendered into the
+// terminal during session playback, making screenshots of recordings
+// attributable to the viewer.
+type PlaybackWatermark struct {
+	// Viewer is the username of the person viewing the recording.
+	Viewer string
+	// Message is an optional extra string rendered after the viewer
+	// and timestamp.
+	Message string
+
+	clock clockwork.Clock
+}
+
+// frameText renders the watermark text for a single frame.
+func (w *PlaybackWatermark) frameText() string {
+	clock := w.clock
+	if clock == nil {
+		clock = clockwork.NewRealClock()
+	}
+	text := fmt.Sprintf("[%v %v]", w.Viewer, clock.Now().UTC().Format(time.RFC3339))
+	if w.Message != "" {
+		text = fmt.Sprintf("%v %v", text, w.Message)
+	}
+	return text
+}
+
+// TranscriptHeader renders the watermark as a header line suitable for
+// prepending to an exported transcript of the recording.
+func (w *PlaybackWatermark) TranscriptHeader() string {
+	return fmt.Sprintf("exported by %v at %v\n", w.Viewer, time.Now().UTC().Format(time.RFC3339))
+}
+
 // sessionPlayer implements replaying terminal sessions. It runs a playback goroutine
 // and allows to control it
 type sessionPlayer struct {
@@ -58,6 +90,7 @@ type sessionPlayer struct {
 	stream        []byte
 	sessionEvents []events.EventFields
 	term          *terminal.Terminal
+	watermark     *PlaybackWatermark
 
 	// stopC is closed when playback ends (either because the end of the stream has
 	// been reached, or a hard stop was requested via EndPlayback().
@@ -67,13 +100,14 @@ type sessionPlayer struct {
 	log *logrus.Logger
 }
 
-func newSessionPlayer(sessionEvents []events.EventFields, stream []byte, term *terminal.Terminal) *sessionPlayer {
+func newSessionPlayer(sessionEvents []events.EventFields, stream []byte, term *terminal.Terminal, watermark *PlaybackWatermark) *sessionPlayer {
 	p := &sessionPlayer{
 		clock:         clockwork.NewRealClock(),
 		position:      -1, // position is the last successfully written event
 		stream:        stream,
 		sessionEvents: sessionEvents,
 		term:          term,
+		watermark:     watermark,
 		stopC:         make(chan struct{}),
 		log:           logrus.New(),
 	}
@@ -199,6 +233,24 @@ func timestampFrame(term *terminal.Terminal, message string) {
 	os.Stdout.WriteString(message)
 }
 
+// watermarkFrame prints the playback watermark in the top left corner of the
+// terminal after playing every 'print' event
+func watermarkFrame(term *terminal.Terminal, message string) {
+	const (
+		saveCursor    = "7"
+		restoreCursor = "8"
+	)
+	esc := func(s string) {
+		os.Stdout.Write([]byte("\x1b" + s))
+	}
+	esc(saveCursor)
+	defer esc(restoreCursor)
+
+	// move cursor to 0:0
+	esc(fmt.Sprintf("[%d;%df", 0, 0))
+	os.Stdout.WriteString(message)
+}
+
 func (p *sessionPlayer) close() {
 	p.stopOnce.Do(func() { close(p.stopC) })
 }
@@ -298,6 +350,9 @@ func (p *sessionPlayer) applyDelay(previousTimestamp time.Duration, e events.Eve
 	}
 
 	timestampFrame(p.term, e.GetString("time"))
+	if p.watermark != nil {
+		watermarkFrame(p.term, p.watermark.frameText())
+	}
 	p.clock.Sleep(delay)
 	return eventTime
 }
@@ -32,7 +32,7 @@ import (
 // immediately transitions to a stopped state.
 func TestEmptyPlay(t *testing.T) {
 	c := clockwork.NewFakeClock()
-	p := newSessionPlayer(nil, nil, testTerm(t))
+	p := newSessionPlayer(nil, nil, testTerm(t), nil)
 	p.clock = c
 
 	p.Play()
@@ -50,7 +50,7 @@ func TestEmptyPlay(t *testing.T) {
 func TestStop(t *testing.T) {
 	c := clockwork.NewFakeClock()
 	events := printEvents(100, 200)
-	p := newSessionPlayer(events, nil, testTerm(t))
+	p := newSessionPlayer(events, nil, testTerm(t), nil)
 	p.clock = c
 
 	p.Play()
@@ -77,7 +77,7 @@ func TestPlayPause(t *testing.T) {
 	// playback for the final event.
 	events := printEvents(100, 200, 300)
 	var stream []byte // intentionally empty, we dont care about stream contents here
-	p := newSessionPlayer(events, stream, testTerm(t))
+	p := newSessionPlayer(events, stream, testTerm(t), nil)
 	p.clock = c
 
 	p.Play()
@@ -145,7 +145,7 @@ func TestEndPlaybackWhilePlaying(t *testing.T) {
 	// that the stopC channel was written to.
 	events := printEvents(100, 200)
 	var stream []byte // intentionally empty, we dont care about stream contents here
-	p := newSessionPlayer(events, stream, testTerm(t))
+	p := newSessionPlayer(events, stream, testTerm(t), nil)
 	p.clock = c
 
 	p.Play()
@@ -179,7 +179,7 @@ func TestEndPlaybackWhilePaused(t *testing.T) {
 	// then pause it and verify the pause state before ending playback.
 	events := printEvents(100, 200)
 	var stream []byte // intentionally empty, we dont care about stream contents here
-	p := newSessionPlayer(events, stream, testTerm(t))
+	p := newSessionPlayer(events, stream, testTerm(t), nil)
 	p.clock = c
 
 	p.Play()
@@ -0,0 +1,145 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/gravitational/trace"
+)
+
+// PortRange is an inclusive range of local ports automatic port selection
+// may pick from. The zero value disables automatic selection.
+type PortRange struct {
+	// From is the first port of the range.
+	From int
+	// To is the last port of the range, inclusive.
+	To int
+}
+
+// IsZero reports whether the range is unset.
+func (r PortRange) IsZero() bool {
+	return r.From == 0 && r.To == 0
+}
+
+// check validates the range bounds.
+func (r PortRange) check() error {
+	if r.From < 1 || r.To > 65535 || r.To < r.From {
+		return trace.BadParameter("invalid port range %v-%v, expected 1 <= from <= to <= 65535", r.From, r.To)
+	}
+	return nil
+}
+
+// ParsePortRange parses a port range in "from-to" form, or a single port as
+// a range of one.
+func ParsePortRange(s string) (PortRange, error) {
+	from, to, found := strings.Cut(s, "-")
+	if !found {
+		to = from
+	}
+	fromPort, err := strconv.Atoi(from)
+	if err != nil {
+		return PortRange{}, trace.BadParameter("invalid port range %q: %v", s, err)
+	}
+	toPort, err := strconv.Atoi(to)
+	if err != nil {
+		return PortRange{}, trace.BadParameter("invalid port range %q: %v", s, err)
+	}
+	r := PortRange{From: fromPort, To: toPort}
+	if err := r.check(); err != nil {
+		return PortRange{}, trace.Wrap(err)
+	}
+	return r, nil
+}
+
+// ProbeLocalPort checks up front whether the local port can be bound, so
+// callers can detect a busy port before setting up forwards or tunnels. The
+// probe listener is closed immediately; the port may of course be taken by
+// another process between the probe and the real bind.
+func ProbeLocalPort(host string, port int) error {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return trace.Wrap(err, "local port %v is not available", port)
+	}
+	return trace.Wrap(ln.Close())
+}
+
+// LocalPortSelectedCallback is called when automatic port selection replaced
+// a busy requested local port with a free one from the configured range.
+type LocalPortSelectedCallback func(requestedPort, selectedPort int)
+
+// localPortSelectedMessage is the machine-readable notice printed when a
+// local port was selected automatically and no callback is configured.
+type localPortSelectedMessage struct {
+	Type          string `json:"type"`
+	RequestedPort int    `json:"requested_port"`
+	SelectedPort  int    `json:"selected_port"`
+}
+
+// reportLocalPortSelected reports an automatically selected local port via
+// the configured callback, or as a machine-readable JSON line otherwise.
+func (tc *TeleportClient) reportLocalPortSelected(requestedPort, selectedPort int) {
+	if tc.OnLocalPortSelected != nil {
+		tc.OnLocalPortSelected(requestedPort, selectedPort)
+		return
+	}
+	msg, err := json.Marshal(localPortSelectedMessage{
+		Type:          "local_port_selected",
+		RequestedPort: requestedPort,
+		SelectedPort:  selectedPort,
+	})
+	if err != nil {
+		return
+	}
+	tc.Infof("%s", msg)
+}
+
+// listenLocalPort binds the requested local port. When the port is busy and
+// LocalPortRange is configured, a free port is selected from the range
+// instead and reported via reportLocalPortSelected.
+func (tc *TeleportClient) listenLocalPort(host string, requestedPort int) (net.Listener, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(requestedPort))
+	socket, err := net.Listen("tcp", addr)
+	if err == nil {
+		return socket, nil
+	}
+	if tc.LocalPortRange.IsZero() {
+		return nil, trace.Errorf("Failed to bind to %v: %v.", addr, err)
+	}
+	if err := tc.LocalPortRange.check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	for port := tc.LocalPortRange.From; port <= tc.LocalPortRange.To; port++ {
+		if port == requestedPort {
+			continue
+		}
+		socket, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			continue
+		}
+		log.Debugf("Local port %v is busy, selected %v instead.", requestedPort, port)
+		tc.reportLocalPortSelected(requestedPort, port)
+		return socket, nil
+	}
+	return nil, trace.Errorf("Failed to bind to %v and no port in range %v-%v is available.",
+		addr, tc.LocalPortRange.From, tc.LocalPortRange.To)
+}
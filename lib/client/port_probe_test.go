@@ -0,0 +1,119 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"net"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+// takeLocalPort binds an ephemeral localhost port and returns its number.
+// The listener is closed when the test ends.
+func takeLocalPort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestParseLocalPortRange(t *testing.T) {
+	t.Parallel()
+
+	r, err := ParsePortRange("8000-8010")
+	require.NoError(t, err)
+	require.Equal(t, PortRange{From: 8000, To: 8010}, r)
+
+	r, err = ParsePortRange("8000")
+	require.NoError(t, err)
+	require.Equal(t, PortRange{From: 8000, To: 8000}, r)
+
+	for _, invalid := range []string{"", "abc", "8010-8000", "0-10", "8000-70000"} {
+		_, err := ParsePortRange(invalid)
+		require.True(t, trace.IsBadParameter(err), "expected BadParameter for %q", invalid)
+	}
+}
+
+func TestProbeLocalPort(t *testing.T) {
+	t.Parallel()
+
+	busyPort := takeLocalPort(t)
+	require.Error(t, ProbeLocalPort("127.0.0.1", busyPort))
+
+	freeLn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	freePort := freeLn.Addr().(*net.TCPAddr).Port
+	require.NoError(t, freeLn.Close())
+	require.NoError(t, ProbeLocalPort("127.0.0.1", freePort))
+}
+
+func TestListenLocalPort(t *testing.T) {
+	t.Parallel()
+
+	busyPort := takeLocalPort(t)
+
+	t.Run("no range fails on busy port", func(t *testing.T) {
+		tc := &TeleportClient{Config: Config{}}
+		_, err := tc.listenLocalPort("127.0.0.1", busyPort)
+		require.Error(t, err)
+	})
+
+	t.Run("free port binds directly", func(t *testing.T) {
+		tc := &TeleportClient{Config: Config{
+			OnLocalPortSelected: func(requested, selected int) {
+				t.Errorf("unexpected automatic selection %v -> %v", requested, selected)
+			},
+		}}
+		ln, err := tc.listenLocalPort("127.0.0.1", 0)
+		require.NoError(t, err)
+		require.NoError(t, ln.Close())
+	})
+
+	t.Run("busy port falls back to range", func(t *testing.T) {
+		// Build a range around another ephemeral port that is free again by
+		// the time the fallback probes it.
+		rangeLn, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		rangePort := rangeLn.Addr().(*net.TCPAddr).Port
+		require.NoError(t, rangeLn.Close())
+
+		var requested, selected int
+		tc := &TeleportClient{Config: Config{
+			LocalPortRange: PortRange{From: rangePort, To: rangePort},
+			OnLocalPortSelected: func(req, sel int) {
+				requested, selected = req, sel
+			},
+		}}
+		ln, err := tc.listenLocalPort("127.0.0.1", busyPort)
+		require.NoError(t, err)
+		defer ln.Close()
+		require.Equal(t, busyPort, requested)
+		require.Equal(t, rangePort, selected)
+		require.Equal(t, rangePort, ln.Addr().(*net.TCPAddr).Port)
+	})
+
+	t.Run("exhausted range fails", func(t *testing.T) {
+		tc := &TeleportClient{Config: Config{
+			LocalPortRange: PortRange{From: busyPort, To: busyPort},
+		}}
+		_, err := tc.listenLocalPort("127.0.0.1", busyPort)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	tracessh "github.com/gravitational/teleport/api/observability/tracing/ssh"
+	"github.com/gravitational/teleport/api/utils/retryutils"
+)
+
+const (
+	// portForwardReconnectInterval is the base delay between attempts to
+	// re-establish the node connection backing forwarded ports.
+	portForwardReconnectInterval = 5 * time.Second
+	// portForwardReconnectMax caps the delay between reconnection attempts.
+	portForwardReconnectMax = time.Minute
+)
+
+// PortForwardOutage describes a window during which forwarded connections to
+// a node could not be dialed, e.g. because the node was rebooting.
+type PortForwardOutage struct {
+	// Node is the address of the node the forwards target.
+	Node string
+	// Start is when dialing through the node first failed.
+	Start time.Time
+	// End is when the connection was re-established.
+	End time.Time
+}
+
+// PortForwardOutageCallback is called after a lost node connection backing
+// forwarded ports has been re-established, describing the outage window.
+type PortForwardOutageCallback func(PortForwardOutage)
+
+// portForwardReconnector is a netDialer that re-establishes the SSH
+// connection to a node when dials through it start failing, so local
+// forwarding listeners survive node restarts. Only dials made after the node
+// returns succeed; connections arriving during the outage fail as before.
+type portForwardReconnector struct {
+	ctx         context.Context
+	tc          *TeleportClient
+	proxyClient *ProxyClient
+	nodeDetails NodeDetails
+	user        string
+
+	mu sync.Mutex
+	// client is the current SSH client to dial through.
+	client *tracessh.Client
+	// outageStart is when dials began failing; zero outside an outage.
+	outageStart time.Time
+	// reconnecting is true while a background reconnection loop runs.
+	reconnecting bool
+}
+
+// Dial dials the given address through the node, kicking off a background
+// reconnection when the node connection appears to be gone.
+func (r *portForwardReconnector) Dial(network, addr string) (net.Conn, error) {
+	r.mu.Lock()
+	client := r.client
+	r.mu.Unlock()
+
+	conn, err := client.Dial(network, addr)
+	if err == nil {
+		return conn, nil
+	}
+
+	r.mu.Lock()
+	if r.outageStart.IsZero() {
+		r.outageStart = time.Now()
+	}
+	if !r.reconnecting {
+		r.reconnecting = true
+		go r.reconnectLoop()
+	}
+	r.mu.Unlock()
+
+	return nil, trace.Wrap(err)
+}
+
+// reconnectLoop attempts to reconnect to the node with backoff until it
+// returns or the context is done, then swaps the dialer's SSH client and
+// reports the outage window.
+func (r *portForwardReconnector) reconnectLoop() {
+	retry, err := retryutils.NewLinear(retryutils.LinearConfig{
+		First:  portForwardReconnectInterval,
+		Step:   portForwardReconnectInterval,
+		Max:    portForwardReconnectMax,
+		Jitter: retryutils.NewJitter(),
+	})
+	if err != nil {
+		log.WithError(err).Warn("Giving up on port forwarding reconnection.")
+		return
+	}
+
+	node := nodeName(r.nodeDetails.Addr)
+	log.Infof("Node %v connection lost, trying to re-establish port forwarding.", node)
+
+	for {
+		retry.Inc()
+		select {
+		case <-retry.After():
+		case <-r.ctx.Done():
+			return
+		}
+
+		nodeClient, err := r.tc.ConnectToNode(r.ctx, r.proxyClient, r.nodeDetails, r.user)
+		if err != nil {
+			log.WithError(err).Debugf("Node %v is still unreachable.", node)
+			continue
+		}
+
+		r.mu.Lock()
+		r.client = nodeClient.Client
+		outage := PortForwardOutage{
+			Node:  r.nodeDetails.Addr,
+			Start: r.outageStart,
+			End:   time.Now(),
+		}
+		r.outageStart = time.Time{}
+		r.reconnecting = false
+		r.mu.Unlock()
+
+		log.Infof("Re-established port forwarding to node %v after %v.", node, outage.End.Sub(outage.Start).Round(time.Second))
+		if r.tc.OnPortForwardOutage != nil {
+			r.tc.OnPortForwardOutage(outage)
+		}
+		return
+	}
+}
+
+// setupPortForwardReconnect attaches a reconnecting dialer to the node client
+// when the client is configured to keep forwarded ports alive across node
+// restarts. It is a no-op when the option is off or no forwards are
+// configured.
+func (tc *TeleportClient) setupPortForwardReconnect(ctx context.Context, proxyClient *ProxyClient, nodeDetails NodeDetails, user string, nodeClient *NodeClient) {
+	if !tc.ReconnectPortForwards {
+		return
+	}
+	if len(tc.Config.LocalForwardPorts) == 0 && len(tc.Config.DynamicForwardedPorts) == 0 {
+		return
+	}
+	nodeClient.reconnector = &portForwardReconnector{
+		ctx:         ctx,
+		tc:          tc,
+		proxyClient: proxyClient,
+		nodeDetails: nodeDetails,
+		user:        user,
+		client:      nodeClient.Client,
+	}
+}
+
+// portForwardDialer returns the dialer forwarded connections should use: the
+// reconnecting dialer when configured, otherwise the node's SSH client.
+func (c *NodeClient) portForwardDialer() netDialer {
+	if c.reconnector != nil {
+		return c.reconnector
+	}
+	return c.Client
+}
@@ -25,18 +25,62 @@ import (
 	"github.com/gravitational/trace"
 
 	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/types"
 	"github.com/gravitational/teleport/lib/auth"
 )
 
+// PresenceMFASolver solves a periodic presence MFA challenge on behalf of a
+// moderator, returning the authentication response. Implementations may
+// prompt a person or, for headless moderators (e.g. ChatOps bots), complete
+// the ceremony programmatically.
+type PresenceMFASolver func(ctx context.Context, challenge *proto.MFAAuthenticateChallenge) (*proto.MFAAuthenticateResponse, error)
+
 func runPresenceTask(ctx context.Context, term io.Writer, auth auth.ClientI, tc *TeleportClient, sessionID string) error {
 	fmt.Fprintf(term, "\r\nTeleport > MFA presence enabled\r\n")
 
+	solver := func(ctx context.Context, challenge *proto.MFAAuthenticateChallenge) (*proto.MFAAuthenticateResponse, error) {
+		return solveMFA(ctx, term, tc, challenge)
+	}
+	err := maintainSessionPresence(ctx, auth, sessionID, solver, func(err error) {
+		fmt.Fprintf(term, "\r\nstream error: %v\r\n", err)
+	})
+	return trace.Wrap(err)
+}
+
+// MaintainSessionPresence answers the periodic presence MFA checks of a
+// moderated session on behalf of the current user until the context is
+// canceled. The user must have already joined the session in moderator mode.
+// The supplied solver is invoked for every challenge; see PresenceMFASolver.
+func (tc *TeleportClient) MaintainSessionPresence(ctx context.Context, sessionID string, solver PresenceMFASolver) error {
+	if solver == nil {
+		return trace.BadParameter("missing presence MFA solver")
+	}
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	site := proxyClient.CurrentCluster()
+	defer site.Close()
+
+	return trace.Wrap(maintainSessionPresence(ctx, site, sessionID, solver, nil))
+}
+
+// maintainSessionPresence runs the presence loop: on every tick it requests a
+// challenge for the session, solves it via the solver and sends the solution
+// back. onStreamErr, if set, is invoked when the presence stream cannot be
+// established.
+func maintainSessionPresence(ctx context.Context, auth auth.ClientI, sessionID string, solver PresenceMFASolver, onStreamErr func(error)) error {
 	ticker := time.NewTicker(mfaChallengeInterval)
 	defer ticker.Stop()
 
 	stream, err := auth.MaintainSessionPresence(ctx)
 	if err != nil {
-		fmt.Fprintf(term, "\r\nstream error: %v\r\n", err)
+		if onStreamErr != nil {
+			onStreamErr(err)
+		}
 		return trace.Wrap(err)
 	}
 
@@ -59,7 +103,7 @@ func runPresenceTask(ctx context.Context, term io.Writer, auth auth.ClientI, tc
 				return trace.Wrap(err)
 			}
 
-			solution, err := solveMFA(ctx, term, tc, challenge)
+			solution, err := solver(ctx, challenge)
 			if err != nil {
 				return trace.Wrap(err)
 			}
@@ -80,6 +124,15 @@ func runPresenceTask(ctx context.Context, term io.Writer, auth auth.ClientI, tc
 	}
 }
 
+// ListPendingModeratedSessions returns the active session trackers that are
+// waiting for a moderator to join before the session can start.
+func (tc *TeleportClient) ListPendingModeratedSessions(ctx context.Context) ([]types.SessionTracker, error) {
+	sessions, err := tc.ListSessions(ctx, &SessionsFilter{
+		States: []types.SessionState{types.SessionState_SessionStatePending},
+	})
+	return sessions, trace.Wrap(err)
+}
+
 func solveMFA(ctx context.Context, term io.Writer, tc *TeleportClient, challenge *proto.MFAAuthenticateChallenge) (*proto.MFAAuthenticateResponse, error) {
 	fmt.Fprint(term, "\r\nTeleport > Please tap your MFA key\r\n")
 
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/trace"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// NodeProbeResult holds per-phase timings for establishing a session to a
+// node. It helps diagnose slow connections by showing which leg of the
+// connection is responsible for the latency.
+type NodeProbeResult struct {
+	// Node is the address of the probed node.
+	Node string `json:"node"`
+	// ProxyDial is the time it took to dial and authenticate to the proxy.
+	ProxyDial time.Duration `json:"proxy_dial"`
+	// Auth is the time it took to complete a round trip to the cluster's
+	// auth server through the proxy.
+	Auth time.Duration `json:"auth"`
+	// NodeDial is the time it took to dial and authenticate to the node,
+	// including any per-session MFA ceremony.
+	NodeDial time.Duration `json:"node_dial"`
+	// ShellReady is the time it took to open a session channel and start a
+	// shell once connected to the node.
+	ShellReady time.Duration `json:"shell_ready"`
+	// Total is the overall time from the start of the probe until the shell
+	// was ready.
+	Total time.Duration `json:"total"`
+}
+
+// ProbeNode measures the end-to-end time to establish a session to the given
+// node, broken down by phase: proxy dial, auth server round trip, node dial
+// and shell readiness. A shell is briefly started on the node, so the probe
+// shows up in the audit log like a regular (empty) session.
+func (tc *TeleportClient) ProbeNode(ctx context.Context, nodeAddr string) (*NodeProbeResult, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/ProbeNode",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(
+			attribute.String("node", nodeAddr),
+		),
+	)
+	defer span.End()
+
+	if !tc.Config.ProxySpecified() {
+		return nil, trace.BadParameter("proxy server is not specified")
+	}
+	if nodeAddr == "" {
+		return nil, trace.BadParameter("node address is not specified")
+	}
+
+	result := &NodeProbeResult{Node: nodeAddr}
+	start := time.Now()
+
+	phase := time.Now()
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+	result.ProxyDial = time.Since(phase)
+
+	phase = time.Now()
+	if _, err := proxyClient.CurrentCluster().GetDomainName(ctx); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	result.Auth = time.Since(phase)
+
+	phase = time.Now()
+	nodeClient, err := tc.ConnectToNode(
+		ctx,
+		proxyClient,
+		NodeDetails{Addr: nodeAddr, Namespace: tc.Namespace, Cluster: tc.SiteName},
+		tc.Config.HostLogin,
+	)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer nodeClient.Close()
+	result.NodeDial = time.Since(phase)
+
+	phase = time.Now()
+	session, err := nodeClient.Client.NewSession(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer session.Close()
+	if err := session.Shell(ctx); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	result.ShellReady = time.Since(phase)
+	result.Total = time.Since(start)
+
+	return result, nil
+}
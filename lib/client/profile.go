@@ -227,6 +227,10 @@ type ProfileStatus struct {
 	// list means there are no resource-specific restrictions.
 	AllowedResourceIDs []types.ResourceID
 
+	// DeviceExtensions holds the trusted device extensions encoded into the
+	// SSH certificate, if any.
+	DeviceExtensions DeviceCertExtensions
+
 	// IsVirtual is set when this profile does not actually exist on disk,
 	// probably because it was constructed from an identity file. When set,
 	// certain profile functions - particularly those that return paths to
@@ -364,6 +368,11 @@ func profileStatusFromKey(key *Key, opts profileOptions) (*ProfileStatus, error)
 		GCPServiceAccounts: tlsID.GCPServiceAccounts,
 		IsVirtual:          opts.IsVirtual,
 		AllowedResourceIDs: allowedResourceIDs,
+		DeviceExtensions: DeviceCertExtensions{
+			DeviceID:     sshCert.Extensions[teleport.CertExtensionDeviceID],
+			AssetTag:     sshCert.Extensions[teleport.CertExtensionDeviceAssetTag],
+			CredentialID: sshCert.Extensions[teleport.CertExtensionDeviceCredentialID],
+		},
 	}, nil
 }
 
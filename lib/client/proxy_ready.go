@@ -0,0 +1,115 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/gravitational/teleport/api/client/webclient"
+	"github.com/gravitational/teleport/api/utils/retryutils"
+)
+
+// WaitForProxyReady polls the proxy's find endpoint with linear backoff until
+// the proxy responds, the timeout elapses, or the context is canceled. It is
+// meant for automation that spins up clusters and needs to wait until the
+// proxy is serving before proceeding, replacing ad-hoc retry loops. The error
+// returned on timeout describes the last failure (DNS resolution, TLS
+// handshake, connection refused, unexpected response) rather than a bare
+// deadline error.
+func (tc *TeleportClient) WaitForProxyReady(ctx context.Context, timeout time.Duration) (*webclient.PingResponse, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/WaitForProxyReady",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	retry, err := retryutils.NewLinear(retryutils.LinearConfig{
+		First:  100 * time.Millisecond,
+		Step:   500 * time.Millisecond,
+		Max:    5 * time.Second,
+		Jitter: retryutils.NewJitter(),
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var lastReason string
+	for {
+		pr, err := webclient.Find(&webclient.Config{
+			Context:      ctx,
+			ProxyAddr:    tc.WebProxyAddr,
+			Insecure:     tc.InsecureSkipVerify,
+			Pool:         loopbackPool(tc.WebProxyAddr),
+			ExtraHeaders: tc.ExtraProxyHeaders,
+		})
+		if err == nil {
+			return pr, nil
+		}
+		lastReason = proxyNotReadyReason(err)
+		log.Debugf("Proxy %v is not ready: %v.", tc.WebProxyAddr, lastReason)
+
+		retry.Inc()
+		select {
+		case <-retry.After():
+		case <-ctx.Done():
+			return nil, trace.LimitExceeded("proxy %v did not become ready: %v", tc.WebProxyAddr, lastReason)
+		}
+	}
+}
+
+// proxyNotReadyReason translates a probe error into a human-readable reason
+// pointing at the failing layer.
+func proxyNotReadyReason(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return fmt.Sprintf("DNS resolution of %q failed: %v", dnsErr.Name, dnsErr)
+	}
+	var recordErr tls.RecordHeaderError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var certInvalidErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	if errors.As(err, &recordErr) || errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &certInvalidErr) || errors.As(err, &hostnameErr) {
+		return fmt.Sprintf("TLS handshake failed: %v", err)
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Sprintf("connection refused: %v", err)
+	}
+	var jsonErr *json.SyntaxError
+	if errors.As(err, &jsonErr) {
+		return fmt.Sprintf("proxy returned an unexpected response, possibly an intermediate load balancer error page: %v", err)
+	}
+	return err.Error()
+}
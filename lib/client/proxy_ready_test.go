@@ -0,0 +1,79 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/observability/tracing"
+)
+
+func TestWaitForProxyReady(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	newClient := func(t *testing.T, proxyAddr string) *TeleportClient {
+		tc, err := NewClient(&Config{
+			WebProxyAddr:       proxyAddr,
+			InsecureSkipVerify: true,
+			Tracer:             tracing.NoopProvider().Tracer("test"),
+		})
+		require.NoError(t, err)
+		return tc
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"server_version": "1.2.3"}`))
+		}))
+		t.Cleanup(srv.Close)
+
+		pr, err := newClient(t, strings.TrimPrefix(srv.URL, "https://")).WaitForProxyReady(ctx, 10*time.Second)
+		require.NoError(t, err)
+		require.Equal(t, "1.2.3", pr.ServerVersion)
+	})
+
+	t.Run("bad gateway until deadline", func(t *testing.T) {
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		}))
+		t.Cleanup(srv.Close)
+
+		_, err := newClient(t, strings.TrimPrefix(srv.URL, "https://")).WaitForProxyReady(ctx, time.Second)
+		require.True(t, trace.IsLimitExceeded(err))
+		require.Contains(t, err.Error(), "unexpected response")
+	})
+
+	t.Run("connection refused until deadline", func(t *testing.T) {
+		// Grab a port that is not being listened on.
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		addr := strings.TrimPrefix(srv.URL, "http://")
+		srv.Close()
+
+		_, err := newClient(t, addr).WaitForProxyReady(ctx, time.Second)
+		require.True(t, trace.IsLimitExceeded(err))
+	})
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/session"
+)
+
+// sessionChunker fetches parts of a recorded session byte stream; it is
+// implemented by auth.ClientI.
+type sessionChunker interface {
+	GetSessionChunk(namespace string, sid session.ID, offsetBytes, maxBytes int) ([]byte, error)
+}
+
+// SessionRecordingReader streams the raw byte stream of a recorded session,
+// fetching chunks from the audit log on demand instead of buffering the
+// whole recording in memory.
+type SessionRecordingReader struct {
+	chunks    sessionChunker
+	namespace string
+	sid       session.ID
+	offset    int64
+	buf       []byte
+	eof       bool
+	closer    func() error
+}
+
+func newSessionRecordingReader(chunks sessionChunker, namespace string, sid session.ID, offset int64, closer func() error) *SessionRecordingReader {
+	return &SessionRecordingReader{
+		chunks:    chunks,
+		namespace: namespace,
+		sid:       sid,
+		offset:    offset,
+		closer:    closer,
+	}
+}
+
+// Read implements io.Reader, fetching the next chunk of the recording when
+// the buffered one is exhausted.
+func (r *SessionRecordingReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		chunk, err := r.chunks.GetSessionChunk(r.namespace, r.sid, int(r.offset), events.MaxChunkBytes)
+		if err != nil {
+			return 0, trace.Wrap(err)
+		}
+		if len(chunk) == 0 {
+			r.eof = true
+			return 0, io.EOF
+		}
+		r.offset += int64(len(chunk))
+		r.buf = chunk
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Offset returns the position within the recording up to which chunks have
+// been fetched. After an interrupted download it can be passed as the start
+// offset of a new reader to continue where the previous one stopped.
+func (r *SessionRecordingReader) Offset() int64 {
+	return r.offset - int64(len(r.buf))
+}
+
+// Close releases the connections backing the reader, if any.
+func (r *SessionRecordingReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return trace.Wrap(r.closer())
+}
+
+// StreamSessionRecording returns a reader streaming the raw recording of the
+// given session starting at offset. Unlike buffering the recording with
+// repeated GetSessionChunk calls, the reader holds at most one chunk in
+// memory, making it suitable for saving multi-GB recordings to disk. The
+// caller must close the reader when done.
+func (tc *TeleportClient) StreamSessionRecording(ctx context.Context, namespace, sessionID string, offset int64) (*SessionRecordingReader, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/StreamSessionRecording",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	sid, err := session.ParseID(sessionID)
+	if err != nil {
+		return nil, trace.BadParameter("%q is not a valid session ID (must be GUID)", sessionID)
+	}
+	if offset < 0 {
+		return nil, trace.BadParameter("offset must not be negative, got %v", offset)
+	}
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	site := proxyClient.CurrentCluster()
+
+	return newSessionRecordingReader(site, namespace, *sid, offset, func() error {
+		return trace.NewAggregate(site.Close(), proxyClient.Close())
+	}), nil
+}
+
+// DownloadSessionRecording streams the recording of the given session to w,
+// optionally gzip-compressing it on the way. It returns the recording offset
+// reached, which can be passed to StreamSessionRecording to continue an
+// interrupted download (only meaningful for uncompressed downloads).
+func (tc *TeleportClient) DownloadSessionRecording(ctx context.Context, namespace, sessionID string, w io.Writer, compress bool) (int64, error) {
+	reader, err := tc.StreamSessionRecording(ctx, namespace, sessionID, 0)
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	defer reader.Close()
+
+	dst := w
+	var gzw *gzip.Writer
+	if compress {
+		gzw = gzip.NewWriter(w)
+		dst = gzw
+	}
+
+	if _, err := io.Copy(dst, reader); err != nil {
+		return reader.Offset(), trace.Wrap(err)
+	}
+	if gzw != nil {
+		if err := gzw.Close(); err != nil {
+			return reader.Offset(), trace.Wrap(err)
+		}
+	}
+
+	return reader.Offset(), nil
+}
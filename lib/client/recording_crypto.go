@@ -0,0 +1,112 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// recordingKeySize is the size of the curve25519 public key used to encrypt
+// locally captured session recordings.
+const recordingKeySize = 32
+
+// ParseRecordingEncryptionKey parses a base64-encoded curve25519 public key
+// as published by the cluster for client-side recording encryption.
+func ParseRecordingEncryptionKey(s string) (*[recordingKeySize]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, trace.BadParameter("invalid recording encryption key: %v", err)
+	}
+	if len(raw) != recordingKeySize {
+		return nil, trace.BadParameter("invalid recording encryption key: expected %d bytes, got %d", recordingKeySize, len(raw))
+	}
+	key := new([recordingKeySize]byte)
+	copy(key[:], raw)
+	return key, nil
+}
+
+// encryptingWriter encrypts each written chunk with an anonymous NaCl box
+// sealed to the cluster's recording public key and emits it as a
+// length-prefixed frame. Only the holder of the matching private key can
+// decrypt the stream, so recordings are end-to-end encrypted from the
+// endpoint.
+type encryptingWriter struct {
+	w   io.WriteCloser
+	pub *[recordingKeySize]byte
+}
+
+// newEncryptingWriter returns a writer that seals chunks to the given public
+// key before passing them to w.
+func newEncryptingWriter(w io.WriteCloser, pub *[recordingKeySize]byte) *encryptingWriter {
+	return &encryptingWriter{w: w, pub: pub}
+}
+
+// Write seals p and writes it as a single frame. A chunk must be decrypted
+// in full, so callers should keep chunks reasonably sized.
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	sealed, err := box.SealAnonymous(nil, p, e.pub, rand.Reader)
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	var frame [4]byte
+	binary.BigEndian.PutUint32(frame[:], uint32(len(sealed)))
+	if _, err := e.w.Write(frame[:]); err != nil {
+		return 0, trace.ConvertSystemError(err)
+	}
+	if _, err := e.w.Write(sealed); err != nil {
+		return 0, trace.ConvertSystemError(err)
+	}
+	return len(p), nil
+}
+
+// Close closes the underlying writer.
+func (e *encryptingWriter) Close() error {
+	return trace.Wrap(e.w.Close())
+}
+
+// DecryptRecordingStream reverses encryptingWriter framing: it reads sealed
+// frames from r, opens them with the recording key pair and writes the
+// plaintext to w. It is intended for server-side or offline tooling holding
+// the recording private key.
+func DecryptRecordingStream(w io.Writer, r io.Reader, pub, priv *[recordingKeySize]byte) error {
+	var frame [4]byte
+	for {
+		if _, err := io.ReadFull(r, frame[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return trace.ConvertSystemError(err)
+		}
+		sealed := make([]byte, binary.BigEndian.Uint32(frame[:]))
+		if _, err := io.ReadFull(r, sealed); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		plain, ok := box.OpenAnonymous(nil, sealed, pub, priv)
+		if !ok {
+			return trace.BadParameter("failed to decrypt recording frame")
+		}
+		if _, err := w.Write(plain); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/box"
+)
+
+func TestParseRecordingEncryptionKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseRecordingEncryptionKey("not base64!")
+	require.Error(t, err)
+
+	_, err = ParseRecordingEncryptionKey(base64.StdEncoding.EncodeToString([]byte("too short")))
+	require.Error(t, err)
+
+	pub, _, err := box.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	parsed, err := ParseRecordingEncryptionKey(base64.StdEncoding.EncodeToString(pub[:]))
+	require.NoError(t, err)
+	require.Equal(t, pub, parsed)
+}
+
+func TestEncryptedRecordingRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	recorder, err := newTypescriptRecorder(dir, "test-session", pub)
+	require.NoError(t, err)
+
+	chunks := []string{"$ whoami\r\n", "alice\r\n", "$ exit\r\n"}
+	for _, chunk := range chunks {
+		n, err := recorder.Write([]byte(chunk))
+		require.NoError(t, err)
+		require.Equal(t, len(chunk), n)
+	}
+	require.NoError(t, recorder.Close())
+
+	// The ciphertext on disk must not contain the session output.
+	sealed, err := os.ReadFile(filepath.Join(dir, "test-session.typescript.enc"))
+	require.NoError(t, err)
+	require.NotContains(t, string(sealed), "whoami")
+
+	// Decrypting with the private key recovers the typescript.
+	var plain bytes.Buffer
+	require.NoError(t, DecryptRecordingStream(&plain, bytes.NewReader(sealed), pub, priv))
+	require.Contains(t, plain.String(), "Script started on")
+	for _, chunk := range chunks {
+		require.Contains(t, plain.String(), chunk)
+	}
+	require.Contains(t, plain.String(), "Script done on")
+
+	// The wrong key cannot decrypt the stream.
+	otherPub, otherPriv, err := box.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.Error(t, DecryptRecordingStream(&bytes.Buffer{}, bytes.NewReader(sealed), otherPub, otherPriv))
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/session"
+)
+
+// fakeSessionChunker serves a fixed recording in chunks of at most chunkSize
+// bytes.
+type fakeSessionChunker struct {
+	recording []byte
+	chunkSize int
+}
+
+func (f *fakeSessionChunker) GetSessionChunk(namespace string, sid session.ID, offsetBytes, maxBytes int) ([]byte, error) {
+	if offsetBytes >= len(f.recording) {
+		return nil, nil
+	}
+	end := offsetBytes + f.chunkSize
+	if end > len(f.recording) {
+		end = len(f.recording)
+	}
+	return f.recording[offsetBytes:end], nil
+}
+
+func TestSessionRecordingReader(t *testing.T) {
+	t.Parallel()
+
+	recording := bytes.Repeat([]byte("0123456789"), 100)
+	sid := session.NewID()
+
+	t.Run("full stream", func(t *testing.T) {
+		chunks := &fakeSessionChunker{recording: recording, chunkSize: 64}
+		reader := newSessionRecordingReader(chunks, "default", sid, 0, nil)
+		out, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, recording, out)
+		require.Equal(t, int64(len(recording)), reader.Offset())
+	})
+
+	t.Run("continuation from offset", func(t *testing.T) {
+		chunks := &fakeSessionChunker{recording: recording, chunkSize: 64}
+
+		// read part of the stream, then resume from the reported offset
+		first := newSessionRecordingReader(chunks, "default", sid, 0, nil)
+		head := make([]byte, 100)
+		_, err := io.ReadFull(first, head)
+		require.NoError(t, err)
+
+		second := newSessionRecordingReader(chunks, "default", sid, first.Offset(), nil)
+		tail, err := io.ReadAll(second)
+		require.NoError(t, err)
+		require.Equal(t, recording, append(head, tail...))
+	})
+}
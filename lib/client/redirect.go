@@ -19,7 +19,6 @@ package client
 import (
 	"context"
 	"encoding/json"
-	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -54,6 +53,9 @@ type Redirector struct {
 	SSHLoginSSO
 	server *httptest.Server
 	mux    *http.ServeMux
+	// baseURL is the base URL the local callback server is
+	// reachable at, set once the server is started
+	baseURL string
 	// redirectURL will be set based on the response from the Teleport
 	// proxy server, will contain target redirect URL
 	// to launch SSO workflow
@@ -140,24 +142,30 @@ func NewRedirector(ctx context.Context, login SSHLoginSSO, config *RedirectorCon
 // Start launches local http server on the machine,
 // initiates SSO login request sequence with the Teleport Proxy
 func (rd *Redirector) Start() error {
-	if rd.BindAddr != "" {
+	switch {
+	case rd.CallbackServer != nil:
+		rd.CallbackServer.setHandler(rd.mux)
+		rd.baseURL = rd.CallbackServer.URL()
+	case rd.BindAddr != "":
 		log.Debugf("Binding to %v.", rd.BindAddr)
-		listener, err := net.Listen("tcp", rd.BindAddr)
+		listener, err := newCallbackListener(rd.BindAddr)
 		if err != nil {
-			return trace.Wrap(err, "%v: could not bind to %v, make sure the address is host:port format for ipv4 and [ipv6]:port format for ipv6, and the address is not in use", err, rd.BindAddr)
+			return trace.Wrap(err)
 		}
 		rd.server = &httptest.Server{
 			Listener: listener,
 			Config:   &http.Server{Handler: rd.mux},
 		}
 		rd.server.Start()
-	} else {
+		rd.baseURL = rd.server.URL
+	default:
 		rd.server = httptest.NewServer(rd.mux)
+		rd.baseURL = rd.server.URL
 	}
-	log.Infof("Waiting for response at: %v.", rd.server.URL)
+	log.Infof("Waiting for response at: %v.", rd.baseURL)
 
 	// communicate callback redirect URL to the Teleport Proxy
-	u, err := url.Parse(rd.server.URL + "/callback")
+	u, err := url.Parse(rd.baseURL + "/callback")
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -213,10 +221,10 @@ func (rd *Redirector) Done() <-chan struct{} {
 
 // ClickableURL returns a short clickable redirect URL
 func (rd *Redirector) ClickableURL() string {
-	if rd.server == nil {
+	if rd.baseURL == "" {
 		return "<undefined - server is not started>"
 	}
-	return utils.ClickableURL(rd.server.URL + rd.shortPath)
+	return utils.ClickableURL(rd.baseURL + rd.shortPath)
 }
 
 // ResponseC returns a channel with response
@@ -259,6 +267,11 @@ func (rd *Redirector) callback(w http.ResponseWriter, r *http.Request) (*auth.SS
 // Close closes redirector and releases all resources
 func (rd *Redirector) Close() error {
 	rd.cancel()
+	if rd.CallbackServer != nil {
+		// detach from the shared callback server instead of closing it,
+		// so it can be reused by subsequent logins
+		rd.CallbackServer.setHandler(nil)
+	}
 	if rd.server != nil {
 		rd.server.Close()
 	}
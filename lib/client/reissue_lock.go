@@ -0,0 +1,122 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/utils/retryutils"
+	"github.com/gravitational/teleport/lib/tlsca"
+	"github.com/gravitational/teleport/lib/utils"
+)
+
+const (
+	// reissueLockName is the name of the lock file created under
+	// Config.ReissueCoordinationDir.
+	reissueLockName = "reissue"
+	// reissueLockTimeout bounds how long a client waits for another process
+	// to finish its reissue before giving up.
+	reissueLockTimeout = time.Minute
+	// reissueLockJitter is the upper bound of the random delay applied
+	// before trying to take the reissue lock, so a fleet of clients starting
+	// at the same moment does not stampede the lock file.
+	reissueLockJitter = time.Second
+)
+
+// isPlainRenewal returns true if the params only renew the base user
+// certificates without requesting additional routing or access request
+// changes, so a renewal performed by another process with the same identity
+// is an exact substitute.
+func (p ReissueParams) isPlainRenewal() bool {
+	return p.NodeName == "" &&
+		p.KubernetesCluster == "" &&
+		len(p.AccessRequests) == 0 &&
+		len(p.DropAccessRequests) == 0 &&
+		p.RouteToDatabase.ServiceName == "" &&
+		p.RouteToApp.Name == "" &&
+		p.RouteToWindowsDesktop.WindowsDesktop == "" &&
+		p.ExistingCreds == nil
+}
+
+// currentTLSCertIdentity returns the identity parsed from the user TLS
+// certificate currently in the client store, or nil if it cannot be loaded.
+func (tc *TeleportClient) currentTLSCertIdentity() *tlsca.Identity {
+	if tc.localAgent == nil {
+		return nil
+	}
+	key, err := tc.localAgent.GetCoreKey()
+	if err != nil {
+		return nil
+	}
+	cert, err := key.TeleportTLSCertificate()
+	if err != nil {
+		return nil
+	}
+	identity, err := tlsca.FromSubject(cert.Subject, cert.NotAfter)
+	if err != nil {
+		return nil
+	}
+	return identity
+}
+
+// coordinateReissue serializes certificate reissues across processes sharing
+// the client store. It waits a short jittered delay, takes a file lock under
+// tc.ReissueCoordinationDir and then checks whether another process already
+// refreshed the certificates: if so, a plain renewal can be skipped and the
+// stored certificates reused. The returned unlock function must be called
+// once the reissue (or reuse) is complete.
+func (tc *TeleportClient) coordinateReissue(ctx context.Context, params ReissueParams) (unlock func() error, reused bool, err error) {
+	var before time.Time
+	if identity := tc.currentTLSCertIdentity(); identity != nil {
+		before = identity.Expires
+	}
+
+	select {
+	case <-time.After(retryutils.NewHalfJitter()(reissueLockJitter)):
+	case <-ctx.Done():
+		return nil, false, trace.Wrap(ctx.Err())
+	}
+
+	if err := os.MkdirAll(tc.ReissueCoordinationDir, 0700); err != nil {
+		return nil, false, trace.ConvertSystemError(err)
+	}
+	unlock, err = utils.FSTryWriteLockTimeout(ctx, filepath.Join(tc.ReissueCoordinationDir, reissueLockName), reissueLockTimeout)
+	if err != nil {
+		return nil, false, trace.Wrap(err)
+	}
+
+	// Only a plain renewal can reuse certificates refreshed by another
+	// process; reissues that change routing or access requests must still
+	// reach the auth server.
+	if before.IsZero() || !params.isPlainRenewal() {
+		return unlock, false, nil
+	}
+	identity := tc.currentTLSCertIdentity()
+	if identity == nil || !identity.Expires.After(before) {
+		return unlock, false, nil
+	}
+	if params.RouteToCluster != "" && identity.RouteToCluster != params.RouteToCluster {
+		return unlock, false, nil
+	}
+	log.Debug("User certificates were refreshed by another process while waiting for the reissue lock, reusing them.")
+	return unlock, true, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/client/proto"
+)
+
+func TestIsPlainRenewal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		params   ReissueParams
+		expected bool
+	}{
+		{
+			name:     "empty params",
+			params:   ReissueParams{},
+			expected: true,
+		},
+		{
+			name:     "route to cluster only",
+			params:   ReissueParams{RouteToCluster: "leaf"},
+			expected: true,
+		},
+		{
+			name:     "node name",
+			params:   ReissueParams{NodeName: "node-1"},
+			expected: false,
+		},
+		{
+			name:     "kubernetes cluster",
+			params:   ReissueParams{KubernetesCluster: "kube"},
+			expected: false,
+		},
+		{
+			name:     "access requests",
+			params:   ReissueParams{AccessRequests: []string{"req-1"}},
+			expected: false,
+		},
+		{
+			name:     "database route",
+			params:   ReissueParams{RouteToDatabase: proto.RouteToDatabase{ServiceName: "postgres"}},
+			expected: false,
+		},
+		{
+			name:     "app route",
+			params:   ReissueParams{RouteToApp: proto.RouteToApp{Name: "grafana"}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.expected, tt.params.isPlainRenewal())
+		})
+	}
+}
+
+func TestCoordinateReissue(t *testing.T) {
+	t.Parallel()
+
+	tc := &TeleportClient{Config: Config{
+		ReissueCoordinationDir: t.TempDir(),
+	}}
+
+	// Without stored certificates there is nothing to reuse, but the lock
+	// must still be acquired and released cleanly.
+	unlock, reused, err := tc.coordinateReissue(context.Background(), ReissueParams{})
+	require.NoError(t, err)
+	require.False(t, reused)
+	require.NoError(t, unlock())
+
+	// The lock can be re-acquired once released.
+	unlock, reused, err = tc.coordinateReissue(context.Background(), ReissueParams{})
+	require.NoError(t, err)
+	require.False(t, reused)
+	require.NoError(t, unlock())
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// remoteEnvProbe is the command used to detect the platform of a POSIX
+// node: kernel name, login shell and locale, one per line.
+const remoteEnvProbe = `uname -s; echo "${SHELL:-}"; echo "${LANG:-}"`
+
+// remoteEnvProbeWindows detects Windows nodes, where the POSIX probe fails.
+const remoteEnvProbeWindows = `echo %OS%`
+
+// RemoteEnvironment describes the platform of a connected node. Callers use
+// it to adjust behavior against heterogeneous fleets: escape-sequence and
+// UTF-8 handling for interactive sessions, and path semantics for file
+// transfers.
+type RemoteEnvironment struct {
+	// OS is the remote operating system in GOOS style ("linux", "darwin",
+	// "windows"), or empty when detection failed.
+	OS string
+	// Shell is the remote user's login shell. Empty on Windows or when not
+	// exported by the remote shell.
+	Shell string
+	// Locale is the remote LANG value, e.g. "en_US.UTF-8". Empty on Windows
+	// or when unset.
+	Locale string
+}
+
+// IsWindows returns true when the node runs Windows, meaning file paths use
+// backslash separators and drive letters.
+func (e *RemoteEnvironment) IsWindows() bool {
+	return e.OS == "windows"
+}
+
+// SupportsUTF8 returns true when the remote locale indicates UTF-8 output
+// can be rendered verbatim.
+func (e *RemoteEnvironment) SupportsUTF8() bool {
+	locale := strings.ToLower(e.Locale)
+	return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+}
+
+// PathSeparator returns the path separator used by the remote filesystem.
+func (e *RemoteEnvironment) PathSeparator() string {
+	if e.IsWindows() {
+		return `\`
+	}
+	return "/"
+}
+
+// RemoteEnvironment detects the platform of the connected node, caching the
+// result for the lifetime of the client. Detection runs a short probe
+// command in a fresh session, so it requires the user to have exec access
+// to the node.
+func (c *NodeClient) RemoteEnvironment(ctx context.Context) (*RemoteEnvironment, error) {
+	ctx, span := c.Tracer.Start(
+		ctx,
+		"nodeClient/RemoteEnvironment",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	c.remoteEnvMu.Lock()
+	defer c.remoteEnvMu.Unlock()
+	if c.remoteEnv != nil {
+		return c.remoteEnv, nil
+	}
+
+	env, err := c.detectRemoteEnvironment(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	c.remoteEnv = env
+	return env, nil
+}
+
+// detectRemoteEnvironment probes the node for its platform. The POSIX probe
+// is attempted first; when it fails the node is probed as Windows.
+func (c *NodeClient) detectRemoteEnvironment(ctx context.Context) (*RemoteEnvironment, error) {
+	out, err := c.runProbe(ctx, remoteEnvProbe)
+	if err == nil {
+		return parseRemoteEnvProbe(out), nil
+	}
+	log.WithError(err).Debug("POSIX platform probe failed, probing for Windows.")
+
+	out, winErr := c.runProbe(ctx, remoteEnvProbeWindows)
+	if winErr != nil {
+		return nil, trace.Wrap(err)
+	}
+	if strings.Contains(strings.ToLower(out), "windows") {
+		return &RemoteEnvironment{OS: "windows"}, nil
+	}
+	return &RemoteEnvironment{}, nil
+}
+
+// runProbe runs cmd in a fresh session and returns its trimmed output.
+func (c *NodeClient) runProbe(ctx context.Context, cmd string) (string, error) {
+	session, err := c.Client.NewSession(ctx)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	defer session.Close()
+
+	out, err := session.Output(ctx, cmd)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// parseRemoteEnvProbe parses the POSIX probe output: kernel name, shell and
+// locale, one per line. Missing lines leave the corresponding field empty.
+func parseRemoteEnvProbe(out string) *RemoteEnvironment {
+	env := &RemoteEnvironment{}
+	lines := strings.Split(out, "\n")
+	if len(lines) > 0 {
+		switch kernel := strings.TrimSpace(lines[0]); kernel {
+		case "Linux":
+			env.OS = "linux"
+		case "Darwin":
+			env.OS = "darwin"
+		default:
+			env.OS = strings.ToLower(kernel)
+		}
+	}
+	if len(lines) > 1 {
+		env.Shell = strings.TrimSpace(lines[1])
+	}
+	if len(lines) > 2 {
+		env.Locale = strings.TrimSpace(lines[2])
+	}
+	return env
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemoteEnvProbe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		out  string
+		want RemoteEnvironment
+	}{
+		{
+			name: "linux with shell and locale",
+			out:  "Linux\n/bin/bash\nen_US.UTF-8",
+			want: RemoteEnvironment{OS: "linux", Shell: "/bin/bash", Locale: "en_US.UTF-8"},
+		},
+		{
+			name: "darwin without locale",
+			out:  "Darwin\n/bin/zsh\n",
+			want: RemoteEnvironment{OS: "darwin", Shell: "/bin/zsh"},
+		},
+		{
+			name: "other kernel lowercased",
+			out:  "FreeBSD\n/bin/sh\nC",
+			want: RemoteEnvironment{OS: "freebsd", Shell: "/bin/sh", Locale: "C"},
+		},
+		{
+			name: "kernel only",
+			out:  "Linux",
+			want: RemoteEnvironment{OS: "linux"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, *parseRemoteEnvProbe(tt.out))
+		})
+	}
+}
+
+func TestRemoteEnvironmentHelpers(t *testing.T) {
+	t.Parallel()
+
+	linux := RemoteEnvironment{OS: "linux", Locale: "en_US.UTF-8"}
+	require.False(t, linux.IsWindows())
+	require.True(t, linux.SupportsUTF8())
+	require.Equal(t, "/", linux.PathSeparator())
+
+	legacy := RemoteEnvironment{OS: "linux", Locale: "C"}
+	require.False(t, legacy.SupportsUTF8())
+
+	windows := RemoteEnvironment{OS: "windows"}
+	require.True(t, windows.IsWindows())
+	require.False(t, windows.SupportsUTF8())
+	require.Equal(t, `\`, windows.PathSeparator())
+}
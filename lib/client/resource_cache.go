@@ -0,0 +1,306 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/profile"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/services"
+)
+
+// defaultResourceCacheTTL is used when a CachePolicy is set without an
+// explicit TTL.
+const defaultResourceCacheTTL = time.Hour
+
+// resourceCacheEntry is the on-disk format of a single cached resource
+// listing.
+type resourceCacheEntry struct {
+	// Created is the time the listing was stored.
+	Created time.Time `json:"created"`
+	// Resources are the individual resources in their standard JSON
+	// representations.
+	Resources []json.RawMessage `json:"resources"`
+}
+
+// resourceCache persists the last known resource inventory (nodes, apps,
+// databases, kube clusters) under the tsh profile directory, implementing
+// Config.CachePolicy. It lets List* methods fall back to the last seen
+// state when the proxy is unreachable.
+type resourceCache struct {
+	// dir is the cache root, usually ~/.tsh/cache.
+	dir    string
+	policy CachePolicy
+	clock  clockwork.Clock
+}
+
+// newResourceCache returns a cache rooted in the given tsh home directory,
+// or nil if no cache policy was configured.
+func newResourceCache(homePath string, policy *CachePolicy) *resourceCache {
+	if policy == nil {
+		return nil
+	}
+	return &resourceCache{
+		dir:    filepath.Join(profile.FullProfilePath(homePath), "cache"),
+		policy: *policy,
+		clock:  clockwork.NewRealClock(),
+	}
+}
+
+// path returns the cache file for a given proxy/cluster/resource kind.
+func (c *resourceCache) path(proxyHost, cluster, kind string) string {
+	return filepath.Join(c.dir, proxyHost, cluster, kind+".json")
+}
+
+// put stores a resource listing, replacing any previous entry.
+func (c *resourceCache) put(proxyHost, cluster, kind string, resources []json.RawMessage) error {
+	entry, err := json.Marshal(resourceCacheEntry{
+		Created:   c.clock.Now().UTC(),
+		Resources: resources,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	path := c.path(proxyHost, cluster, kind)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return trace.ConvertSystemError(os.WriteFile(path, entry, 0600))
+}
+
+// get returns a previously stored resource listing. It returns a NotFound
+// error if no entry exists or the entry has expired.
+func (c *resourceCache) get(proxyHost, cluster, kind string) ([]json.RawMessage, error) {
+	data, err := os.ReadFile(c.path(proxyHost, cluster, kind))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, trace.NotFound("no cached %v listing for cluster %q", kind, cluster)
+		}
+		return nil, trace.ConvertSystemError(err)
+	}
+	var entry resourceCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if !c.policy.NeverExpires {
+		ttl := c.policy.CacheTTL
+		if ttl <= 0 {
+			ttl = defaultResourceCacheTTL
+		}
+		if c.clock.Now().After(entry.Created.Add(ttl)) {
+			return nil, trace.NotFound("cached %v listing for cluster %q has expired", kind, cluster)
+		}
+	}
+	return entry.Resources, nil
+}
+
+// resourceCache returns the cache configured by Config.CachePolicy, or nil
+// if caching is disabled.
+func (tc *TeleportClient) resourceCache() *resourceCache {
+	return newResourceCache(tc.HomePath, tc.CachePolicy)
+}
+
+// hasNodeFilters reports whether the client is configured with node listing
+// filters (labels, search keywords or a predicate expression). Only default
+// listings are cached or served from the cache: storing a filtered subset
+// would poison the cache for later unfiltered listings, and a cached
+// unfiltered listing doesn't match the filter.
+func (tc *TeleportClient) hasNodeFilters() bool {
+	return len(tc.Labels) > 0 || len(tc.SearchKeywords) > 0 || tc.PredicateExpression != ""
+}
+
+// requestHasFilters reports whether the listing request carries filters. See
+// hasNodeFilters for why filtered listings bypass the cache.
+func requestHasFilters(req proto.ListResourcesRequest) bool {
+	return len(req.Labels) > 0 || len(req.SearchKeywords) > 0 || req.PredicateExpression != ""
+}
+
+// cacheNodes stores a node listing for the current cluster. Failures are
+// logged rather than returned; caching is best-effort.
+func (tc *TeleportClient) cacheNodes(nodes []types.Server) {
+	cache := tc.resourceCache()
+	if cache == nil {
+		return
+	}
+	resources := make([]json.RawMessage, 0, len(nodes))
+	for _, node := range nodes {
+		data, err := services.MarshalServer(node, services.PreserveResourceID())
+		if err != nil {
+			log.WithError(err).Debug("Failed to marshal node for the resource cache.")
+			return
+		}
+		resources = append(resources, data)
+	}
+	if err := cache.put(tc.WebProxyHost(), tc.SiteName, types.KindNode, resources); err != nil {
+		log.WithError(err).Debug("Failed to store nodes in the resource cache.")
+	}
+}
+
+// cachedNodes returns the last known node listing for the current cluster.
+func (tc *TeleportClient) cachedNodes() ([]types.Server, error) {
+	cache := tc.resourceCache()
+	if cache == nil {
+		return nil, trace.NotFound("resource caching is not enabled")
+	}
+	resources, err := cache.get(tc.WebProxyHost(), tc.SiteName, types.KindNode)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	nodes := make([]types.Server, 0, len(resources))
+	for _, data := range resources {
+		node, err := services.UnmarshalServer(data, types.KindNode)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// cacheApps stores an app listing for the current cluster.
+func (tc *TeleportClient) cacheApps(apps []types.Application) {
+	cache := tc.resourceCache()
+	if cache == nil {
+		return
+	}
+	resources := make([]json.RawMessage, 0, len(apps))
+	for _, app := range apps {
+		data, err := services.MarshalApp(app, services.PreserveResourceID())
+		if err != nil {
+			log.WithError(err).Debug("Failed to marshal app for the resource cache.")
+			return
+		}
+		resources = append(resources, data)
+	}
+	if err := cache.put(tc.WebProxyHost(), tc.SiteName, types.KindApp, resources); err != nil {
+		log.WithError(err).Debug("Failed to store apps in the resource cache.")
+	}
+}
+
+// cachedApps returns the last known app listing for the current cluster.
+func (tc *TeleportClient) cachedApps() ([]types.Application, error) {
+	cache := tc.resourceCache()
+	if cache == nil {
+		return nil, trace.NotFound("resource caching is not enabled")
+	}
+	resources, err := cache.get(tc.WebProxyHost(), tc.SiteName, types.KindApp)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	apps := make([]types.Application, 0, len(resources))
+	for _, data := range resources {
+		app, err := services.UnmarshalApp(data)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		apps = append(apps, app)
+	}
+	return apps, nil
+}
+
+// cacheDatabases stores a database listing for the current cluster.
+func (tc *TeleportClient) cacheDatabases(databases []types.Database) {
+	cache := tc.resourceCache()
+	if cache == nil {
+		return
+	}
+	resources := make([]json.RawMessage, 0, len(databases))
+	for _, database := range databases {
+		data, err := services.MarshalDatabase(database, services.PreserveResourceID())
+		if err != nil {
+			log.WithError(err).Debug("Failed to marshal database for the resource cache.")
+			return
+		}
+		resources = append(resources, data)
+	}
+	if err := cache.put(tc.WebProxyHost(), tc.SiteName, types.KindDatabase, resources); err != nil {
+		log.WithError(err).Debug("Failed to store databases in the resource cache.")
+	}
+}
+
+// cachedDatabases returns the last known database listing for the current
+// cluster.
+func (tc *TeleportClient) cachedDatabases() ([]types.Database, error) {
+	cache := tc.resourceCache()
+	if cache == nil {
+		return nil, trace.NotFound("resource caching is not enabled")
+	}
+	resources, err := cache.get(tc.WebProxyHost(), tc.SiteName, types.KindDatabase)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	databases := make([]types.Database, 0, len(resources))
+	for _, data := range resources {
+		database, err := services.UnmarshalDatabase(data)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		databases = append(databases, database)
+	}
+	return databases, nil
+}
+
+// cacheKubeClusters stores a kube cluster listing for the given teleport
+// cluster.
+func (tc *TeleportClient) cacheKubeClusters(teleportCluster string, clusters []types.KubeCluster) {
+	cache := tc.resourceCache()
+	if cache == nil {
+		return
+	}
+	resources := make([]json.RawMessage, 0, len(clusters))
+	for _, cluster := range clusters {
+		data, err := services.MarshalKubeCluster(cluster, services.PreserveResourceID())
+		if err != nil {
+			log.WithError(err).Debug("Failed to marshal kube cluster for the resource cache.")
+			return
+		}
+		resources = append(resources, data)
+	}
+	if err := cache.put(tc.WebProxyHost(), teleportCluster, types.KindKubernetesCluster, resources); err != nil {
+		log.WithError(err).Debug("Failed to store kube clusters in the resource cache.")
+	}
+}
+
+// cachedKubeClusters returns the last known kube cluster listing for the
+// given teleport cluster.
+func (tc *TeleportClient) cachedKubeClusters(teleportCluster string) ([]types.KubeCluster, error) {
+	cache := tc.resourceCache()
+	if cache == nil {
+		return nil, trace.NotFound("resource caching is not enabled")
+	}
+	resources, err := cache.get(tc.WebProxyHost(), teleportCluster, types.KindKubernetesCluster)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	clusters := make([]types.KubeCluster, 0, len(resources))
+	for _, data := range resources {
+		cluster, err := services.UnmarshalKubeCluster(data)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/types"
+)
+
+func TestResourceCache(t *testing.T) {
+	t.Parallel()
+
+	clock := clockwork.NewFakeClock()
+	cache := newResourceCache(t.TempDir(), &CachePolicy{CacheTTL: time.Minute})
+	cache.clock = clock
+
+	resources := []json.RawMessage{json.RawMessage(`{"kind":"node"}`)}
+
+	// nothing cached yet
+	_, err := cache.get("proxy.example.com", "example", types.KindNode)
+	require.True(t, trace.IsNotFound(err))
+
+	require.NoError(t, cache.put("proxy.example.com", "example", types.KindNode, resources))
+
+	// fresh entries are returned
+	got, err := cache.get("proxy.example.com", "example", types.KindNode)
+	require.NoError(t, err)
+	require.Equal(t, resources, got)
+
+	// other clusters are not affected
+	_, err = cache.get("proxy.example.com", "other", types.KindNode)
+	require.True(t, trace.IsNotFound(err))
+
+	// entries expire after the TTL
+	clock.Advance(2 * time.Minute)
+	_, err = cache.get("proxy.example.com", "example", types.KindNode)
+	require.True(t, trace.IsNotFound(err))
+
+	// unless the policy never expires entries
+	cache.policy = CachePolicy{NeverExpires: true}
+	got, err = cache.get("proxy.example.com", "example", types.KindNode)
+	require.NoError(t, err)
+	require.Equal(t, resources, got)
+}
+
+func TestListingFilterGuards(t *testing.T) {
+	t.Parallel()
+
+	tc := &TeleportClient{Config: Config{}}
+	require.False(t, tc.hasNodeFilters())
+	tc.Labels = map[string]string{"env": "prod"}
+	require.True(t, tc.hasNodeFilters())
+	tc.Labels = nil
+	tc.SearchKeywords = []string{"db"}
+	require.True(t, tc.hasNodeFilters())
+	tc.SearchKeywords = nil
+	tc.PredicateExpression = `labels["env"] == "prod"`
+	require.True(t, tc.hasNodeFilters())
+
+	require.False(t, requestHasFilters(proto.ListResourcesRequest{}))
+	require.True(t, requestHasFilters(proto.ListResourcesRequest{Labels: map[string]string{"env": "prod"}}))
+	require.True(t, requestHasFilters(proto.ListResourcesRequest{SearchKeywords: []string{"db"}}))
+	require.True(t, requestHasFilters(proto.ListResourcesRequest{PredicateExpression: `labels["env"] == "prod"`}))
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/utils/retryutils"
+)
+
+// ConnectionRetryPolicy configures automatic retries of transient proxy
+// errors (refused or reset connections, timeouts) in ConnectToProxy, Ping
+// and resource listing calls, so flaky networks don't break scripted
+// workflows. A nil policy or MaxAttempts of one or less disables retries.
+type ConnectionRetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// BackoffBase is the delay added after each failed attempt, jittered.
+	// Defaults to 250ms.
+	BackoffBase time.Duration
+	// BackoffMax caps the delay between attempts. Defaults to 5s.
+	BackoffMax time.Duration
+	// IsRetryable overrides the default transient-error classifier. Errors
+	// it returns false for are returned to the caller immediately.
+	IsRetryable func(error) bool
+}
+
+// withRetry runs fn, retrying transient errors according to the client's
+// ConnectionRetry policy. The last error is returned once the attempts are
+// exhausted, the error is not retryable, or the context is done.
+func (tc *TeleportClient) withRetry(ctx context.Context, fn func(context.Context) error) error {
+	policy := tc.ConnectionRetry
+	if policy == nil || policy.MaxAttempts <= 1 {
+		return trace.Wrap(fn(ctx))
+	}
+
+	backoffBase := policy.BackoffBase
+	if backoffBase <= 0 {
+		backoffBase = 250 * time.Millisecond
+	}
+	backoffMax := policy.BackoffMax
+	if backoffMax <= 0 {
+		backoffMax = 5 * time.Second
+	}
+	isRetryable := policy.IsRetryable
+	if isRetryable == nil {
+		isRetryable = isTransientConnectionError
+	}
+
+	retry, err := retryutils.NewLinear(retryutils.LinearConfig{
+		First:  backoffBase,
+		Step:   backoffBase,
+		Max:    backoffMax,
+		Jitter: retryutils.NewJitter(),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = fn(ctx)
+		if err == nil || attempt >= policy.MaxAttempts || !isRetryable(err) {
+			return trace.Wrap(err)
+		}
+		log.WithError(err).Debugf("Attempt %v/%v failed with a transient error, retrying.", attempt, policy.MaxAttempts)
+
+		retry.Inc()
+		select {
+		case <-retry.After():
+		case <-ctx.Done():
+			return trace.Wrap(err)
+		}
+	}
+}
+
+// isTransientConnectionError is the default classifier for errors worth
+// retrying: connectivity problems that may clear up on their own, as opposed
+// to authorization or usage errors that will fail the same way again.
+func isTransientConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if trace.IsConnectionProblem(err) || trace.IsLimitExceeded(err) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsTemporary {
+		return true
+	}
+	return false
+}
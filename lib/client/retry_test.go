@@ -0,0 +1,113 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRetry(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	policy := &ConnectionRetryPolicy{
+		MaxAttempts: 3,
+		BackoffBase: time.Millisecond,
+		BackoffMax:  5 * time.Millisecond,
+	}
+
+	t.Run("no policy runs once", func(t *testing.T) {
+		tc := &TeleportClient{}
+		attempts := 0
+		err := tc.withRetry(ctx, func(context.Context) error {
+			attempts++
+			return trace.ConnectionProblem(nil, "boom")
+		})
+		require.Error(t, err)
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("transient error is retried", func(t *testing.T) {
+		tc := &TeleportClient{Config: Config{ConnectionRetry: policy}}
+		attempts := 0
+		err := tc.withRetry(ctx, func(context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return trace.ConnectionProblem(nil, "boom")
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("permanent error is not retried", func(t *testing.T) {
+		tc := &TeleportClient{Config: Config{ConnectionRetry: policy}}
+		attempts := 0
+		err := tc.withRetry(ctx, func(context.Context) error {
+			attempts++
+			return trace.AccessDenied("nope")
+		})
+		require.True(t, trace.IsAccessDenied(err))
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("attempts are exhausted", func(t *testing.T) {
+		tc := &TeleportClient{Config: Config{ConnectionRetry: policy}}
+		attempts := 0
+		err := tc.withRetry(ctx, func(context.Context) error {
+			attempts++
+			return trace.ConnectionProblem(nil, "boom")
+		})
+		require.True(t, trace.IsConnectionProblem(err))
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("custom classifier", func(t *testing.T) {
+		tc := &TeleportClient{Config: Config{ConnectionRetry: &ConnectionRetryPolicy{
+			MaxAttempts: 2,
+			BackoffBase: time.Millisecond,
+			IsRetryable: func(error) bool { return true },
+		}}}
+		attempts := 0
+		err := tc.withRetry(ctx, func(context.Context) error {
+			attempts++
+			return trace.AccessDenied("flaky middlebox")
+		})
+		require.Error(t, err)
+		require.Equal(t, 2, attempts)
+	})
+}
+
+func TestIsTransientConnectionError(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, isTransientConnectionError(nil))
+	require.False(t, isTransientConnectionError(context.Canceled))
+	require.False(t, isTransientConnectionError(trace.BadParameter("bad")))
+	require.False(t, isTransientConnectionError(trace.AccessDenied("denied")))
+
+	require.True(t, isTransientConnectionError(trace.ConnectionProblem(nil, "reset")))
+	require.True(t, isTransientConnectionError(syscall.ECONNREFUSED))
+	require.True(t, isTransientConnectionError(trace.Wrap(syscall.ECONNRESET)))
+}
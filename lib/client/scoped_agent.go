@@ -0,0 +1,123 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/gravitational/teleport"
+)
+
+// scopedAgent wraps a forwarded key agent and only exposes keys whose
+// certificates are valid for the destination cluster. This keeps
+// certificates routed to other clusters from leaking onto remote hosts when
+// agent forwarding is used across jump host chains into leaf clusters.
+type scopedAgent struct {
+	agent.ExtendedAgent
+	// cluster is the destination teleport cluster.
+	cluster string
+}
+
+// newScopedAgent returns an agent scoped to the given cluster. An empty
+// cluster name disables scoping and returns the base agent unmodified.
+func newScopedAgent(base agent.ExtendedAgent, cluster string) agent.ExtendedAgent {
+	if cluster == "" {
+		return base
+	}
+	return &scopedAgent{ExtendedAgent: base, cluster: cluster}
+}
+
+// keyInScope returns true if the given agent key may be exposed on hosts of
+// the destination cluster. Certificates routed to a different cluster are out
+// of scope; plain keys and certificates without routing information are kept
+// for compatibility with non-teleport keys held by the agent.
+func (a *scopedAgent) keyInScope(blob []byte) bool {
+	pub, err := ssh.ParsePublicKey(blob)
+	if err != nil {
+		log.WithError(err).Debug("Failed to parse agent key, excluding it from the forwarded agent.")
+		return false
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return true
+	}
+	routeToCluster, ok := cert.Permissions.Extensions[teleport.CertExtensionTeleportRouteToCluster]
+	if !ok {
+		return true
+	}
+	return routeToCluster == a.cluster
+}
+
+// List returns the agent keys in scope for the destination cluster.
+func (a *scopedAgent) List() ([]*agent.Key, error) {
+	keys, err := a.ExtendedAgent.List()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	scoped := make([]*agent.Key, 0, len(keys))
+	for _, key := range keys {
+		if a.keyInScope(key.Blob) {
+			scoped = append(scoped, key)
+		}
+	}
+	return scoped, nil
+}
+
+// Signers returns signers for the agent keys in scope for the destination
+// cluster.
+func (a *scopedAgent) Signers() ([]ssh.Signer, error) {
+	signers, err := a.ExtendedAgent.Signers()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	scoped := make([]ssh.Signer, 0, len(signers))
+	for _, signer := range signers {
+		if a.keyInScope(signer.PublicKey().Marshal()) {
+			scoped = append(scoped, signer)
+		}
+	}
+	return scoped, nil
+}
+
+// Sign refuses to sign with keys out of scope for the destination cluster.
+func (a *scopedAgent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	if !a.keyInScope(key.Marshal()) {
+		return nil, trace.AccessDenied("agent key is not valid for cluster %q", a.cluster)
+	}
+	sig, err := a.ExtendedAgent.Sign(key, data)
+	return sig, trace.Wrap(err)
+}
+
+// SignWithFlags refuses to sign with keys out of scope for the destination
+// cluster.
+func (a *scopedAgent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	if !a.keyInScope(key.Marshal()) {
+		return nil, trace.AccessDenied("agent key is not valid for cluster %q", a.cluster)
+	}
+	sig, err := a.ExtendedAgent.SignWithFlags(key, data, flags)
+	return sig, trace.Wrap(err)
+}
+
+// Remove only removes keys in scope for the destination cluster.
+func (a *scopedAgent) Remove(key ssh.PublicKey) error {
+	if !a.keyInScope(key.Marshal()) {
+		return trace.NotFound("agent key is not valid for cluster %q", a.cluster)
+	}
+	return trace.Wrap(a.ExtendedAgent.Remove(key))
+}
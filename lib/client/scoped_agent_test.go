@@ -0,0 +1,121 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/gravitational/teleport"
+)
+
+// addAgentCert generates a user key, signs a certificate routed to the given
+// cluster and adds both to the agent. It returns the certificate's public
+// key.
+func addAgentCert(t *testing.T, keyring agent.Agent, caSigner ssh.Signer, cluster string) ssh.PublicKey {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(priv)
+	require.NoError(t, err)
+
+	cert := &ssh.Certificate{
+		Key:             signer.PublicKey(),
+		CertType:        ssh.UserCert,
+		ValidPrincipals: []string{"alice"},
+		ValidBefore:     ssh.CertTimeInfinity,
+		Permissions: ssh.Permissions{
+			Extensions: map[string]string{
+				teleport.CertExtensionTeleportRouteToCluster: cluster,
+			},
+		},
+	}
+	require.NoError(t, cert.SignCert(rand.Reader, caSigner))
+	require.NoError(t, keyring.Add(agent.AddedKey{
+		PrivateKey:  priv,
+		Certificate: cert,
+	}))
+	return cert
+}
+
+func TestScopedAgent(t *testing.T) {
+	t.Parallel()
+
+	_, caPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	caSigner, err := ssh.NewSignerFromKey(caPriv)
+	require.NoError(t, err)
+
+	keyring := agent.NewKeyring().(agent.ExtendedAgent)
+	rootCert := addAgentCert(t, keyring, caSigner, "root")
+	leafCert := addAgentCert(t, keyring, caSigner, "leaf")
+
+	// A plain key without a certificate, as held for non-teleport hosts.
+	_, plainPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: plainPriv}))
+	plainSigner, err := ssh.NewSignerFromKey(plainPriv)
+	require.NoError(t, err)
+
+	// An empty cluster leaves the agent unscoped.
+	unscoped := newScopedAgent(keyring, "")
+	keys, err := unscoped.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+
+	scoped := newScopedAgent(keyring, "root")
+
+	// List only exposes the root cluster's certificate and the plain key.
+	keys, err = scoped.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	for _, key := range keys {
+		require.NotEqual(t, leafCert.Marshal(), key.Blob)
+	}
+
+	// Signers likewise.
+	signers, err := scoped.Signers()
+	require.NoError(t, err)
+	require.Len(t, signers, 2)
+	for _, signer := range signers {
+		require.NotEqual(t, leafCert.Marshal(), signer.PublicKey().Marshal())
+	}
+
+	// Signing works for in-scope keys and is refused for the leaf cluster's
+	// certificate.
+	data := []byte("data to sign")
+	_, err = scoped.Sign(rootCert, data)
+	require.NoError(t, err)
+	_, err = scoped.Sign(plainSigner.PublicKey(), data)
+	require.NoError(t, err)
+	_, err = scoped.Sign(leafCert, data)
+	require.True(t, trace.IsAccessDenied(err))
+	_, err = scoped.(agent.ExtendedAgent).SignWithFlags(leafCert, data, 0)
+	require.True(t, trace.IsAccessDenied(err))
+
+	// The leaf certificate is still there, just hidden: the unscoped agent
+	// can sign with it.
+	_, err = keyring.Sign(leafCert, data)
+	require.NoError(t, err)
+}
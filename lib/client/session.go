@@ -79,6 +79,8 @@ type NodeSession struct {
 
 	enableEscapeSequences bool
 
+	escapePassThrough bool
+
 	terminal *terminal.Terminal
 
 	// forceDisconnect if we should immediately disconnect upon finish instead of waiting for the remote status.
@@ -97,6 +99,10 @@ type NodeSession struct {
 	// x11RefuseTime is an optional time at which X11 channel
 	// requests using the xauth cookie will be rejected.
 	x11RefuseTime time.Time
+
+	// localRecorder, if set, captures session output into a local
+	// typescript/timing file pair. See Config.LocalRecordingDir.
+	localRecorder *typescriptRecorder
 }
 
 // newSession creates a new Teleport session with the given remote node
@@ -133,6 +139,9 @@ func newSession(ctx context.Context,
 		terminal:              term,
 		shouldClearOnExit:     client.FIPSEnabled || isFIPS(),
 	}
+	if client.TC != nil {
+		ns.escapePassThrough = client.TC.EscapePassThrough
+	}
 	// if we're joining an existing session, we need to assume that session's
 	// existing/current terminal size:
 	if joinSession != nil {
@@ -271,8 +280,10 @@ func selectKeyAgent(tc *TeleportClient) agent.ExtendedAgent {
 		log.Debugf("Selecting system key agent.")
 		return tc.localAgent.systemAgent
 	case ForwardAgentLocal:
-		log.Debugf("Selecting local Teleport key agent.")
-		return tc.localAgent.ExtendedAgent
+		log.Debugf("Selecting local Teleport key agent scoped to cluster %q.", tc.SiteName)
+		// Only expose certificates valid for the destination cluster so keys
+		// routed to other clusters don't leak across jump host chains.
+		return newScopedAgent(tc.localAgent.ExtendedAgent, tc.SiteName)
 	default:
 		log.Debugf("No Key Agent selected.")
 		return nil
@@ -312,6 +323,24 @@ func (ns *NodeSession) interactiveSession(ctx context.Context, mode types.Sessio
 		return trace.Wrap(err)
 	}
 
+	// start a local typescript capture if one was requested
+	if dir := ns.nodeClient.TC.LocalRecordingDir; dir != "" {
+		var encryptionKey *[recordingKeySize]byte
+		if keyStr := ns.nodeClient.TC.RecordingEncryptionKey; keyStr != "" {
+			key, err := ParseRecordingEncryptionKey(keyStr)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			encryptionKey = key
+		}
+		recorder, err := newTypescriptRecorder(dir, string(ns.id), encryptionKey)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		ns.localRecorder = recorder
+		defer ns.localRecorder.Close()
+	}
+
 	if ns.terminal.IsAttached() {
 		// Put the terminal into raw mode. Note that this must be done before
 		// pipeInOut() as it may replace streams.
@@ -355,13 +384,18 @@ func (ns *NodeSession) allocateTerminal(ctx context.Context, termType string, s
 		}
 	}
 
-	// ... and request a server-side terminal of the same size:
+	// ... and request a server-side terminal of the same size with the
+	// configured terminal modes, if any:
+	terminalModes := ns.nodeClient.TC.TerminalModes
+	if terminalModes == nil {
+		terminalModes = ssh.TerminalModes{}
+	}
 	err = s.RequestPty(
 		ctx,
 		termType,
 		height,
 		width,
-		ssh.TerminalModes{},
+		terminalModes,
 	)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -492,15 +526,23 @@ func (ns *NodeSession) updateTerminalSize(ctx context.Context, s *tracessh.Sessi
 	}
 }
 
-// runShell executes user's shell on the remote node under an interactive session
-func (ns *NodeSession) runShell(ctx context.Context, mode types.SessionParticipantMode, beforeStart func(io.Writer), callback ShellCreatedCallback) error {
+// runShell executes user's shell on the remote node under an interactive
+// session. If shellCmd is not empty it is executed in place of the user's
+// default shell; because it is sent as an SSH exec request, the command is
+// recorded in the audit trail.
+func (ns *NodeSession) runShell(ctx context.Context, mode types.SessionParticipantMode, shellCmd string, beforeStart func(io.Writer), callback ShellCreatedCallback) error {
 	return ns.interactiveSession(ctx, mode, func(s *tracessh.Session, shell io.ReadWriteCloser) error {
 		if beforeStart != nil {
 			beforeStart(s.Stdout)
 		}
 
-		// start the shell on the server:
-		if err := s.Shell(ctx); err != nil {
+		if shellCmd != "" {
+			// start the requested shell or wrapper command on the server:
+			if err := s.Start(ctx, shellCmd); err != nil {
+				return trace.Wrap(err)
+			}
+		} else if err := s.Shell(ctx); err != nil {
+			// start the default shell on the server:
 			return trace.Wrap(err)
 		}
 		// call the client-supplied callback
@@ -524,9 +566,17 @@ func (ns *NodeSession) runCommand(ctx context.Context, mode types.SessionPartici
 	)
 	defer span.End()
 
+	// apply the configured PTY allocation override (ssh -tt/-T semantics)
+	switch ns.nodeClient.TC.PTYAllocation {
+	case PTYAllocationForce:
+		interactive = true
+	case PTYAllocationDisabled:
+		interactive = false
+	}
+
 	// If stdin is not a terminal, refuse to allocate terminal on the server and
-	// fallback to non-interactive mode
-	if interactive && !ns.terminal.IsAttached() {
+	// fallback to non-interactive mode, unless a PTY was forced explicitly.
+	if interactive && !ns.terminal.IsAttached() && ns.nodeClient.TC.PTYAllocation != PTYAllocationForce {
 		interactive = false
 		fmt.Fprintf(os.Stderr, "TTY will not be allocated on the server because stdin is not a terminal\n")
 	}
@@ -667,13 +717,15 @@ func handleNonPeerControls(mode types.SessionParticipantMode, term *terminal.Ter
 //
 // If the `forceDisconnect` boolean is true upon return, the session must be instantly terminated without
 // waiting for any remote task to finish.
-func handlePeerControls(term *terminal.Terminal, enableEscapeSequences bool, remoteStdin io.Writer) (forceDisconnect bool) {
+func handlePeerControls(term *terminal.Terminal, enableEscapeSequences, escapePassThrough bool, remoteStdin io.Writer) (forceDisconnect bool) {
 	stdin := term.Stdin()
-	if enableEscapeSequences {
+	if enableEscapeSequences && term.IsAttached() {
 		// escape.NewReader is used to enable manual disconnect sequences as those supported
 		// by tsh. These can be used to force a client disconnect since CTRL-C is merely passed
-		// to the other end and not interpreted as an exit request locally
-		stdin = escape.NewReader(stdin, term.Stderr(), func(err error) {
+		// to the other end and not interpreted as an exit request locally.
+		// Sequences are only intercepted for terminal-attached interactive
+		// sessions; piped input always passes through untouched.
+		reader := escape.NewReader(stdin, term.Stderr(), func(err error) {
 			log.Debugf("escape.NewReader error: %v", err)
 
 			switch err {
@@ -685,6 +737,8 @@ func handlePeerControls(term *terminal.Terminal, enableEscapeSequences bool, rem
 				fmt.Fprintf(term.Stderr(), "\r\nunknown error: %v\r\n", err.Error())
 			}
 		})
+		reader.SetPassThrough(escapePassThrough)
+		stdin = reader
 	}
 
 	_, err := io.Copy(remoteStdin, stdin)
@@ -699,11 +753,22 @@ func handlePeerControls(term *terminal.Terminal, enableEscapeSequences bool, rem
 
 // pipeInOut launches two goroutines: one to pipe the local input into the remote shell,
 // and another to pipe the output of the remote shell into the local output
-func (ns *NodeSession) pipeInOut(ctx context.Context, shell io.ReadWriteCloser, mode types.SessionParticipantMode, sess *tracessh.Session) {
+func (ns *NodeSession) pipeInOut(ctx context.Context, remoteShell io.ReadWriteCloser, mode types.SessionParticipantMode, sess *tracessh.Session) {
+	// count the session traffic and attribute it once the session closes
+	shell := &usageReadWriteCloser{ReadWriteCloser: remoteShell}
+	go func() {
+		<-ns.closer.C
+		ns.nodeClient.TC.recordDataUsage(DataUsageSSH, string(ns.id), shell.sent.Load(), shell.received.Load())
+	}()
+
 	// copy from the remote shell to the local output
 	go func() {
 		defer ns.closer.Close()
-		_, err := io.Copy(ns.terminal.Stdout(), shell)
+		var output io.Writer = ns.terminal.Stdout()
+		if ns.localRecorder != nil {
+			output = io.MultiWriter(output, ns.localRecorder)
+		}
+		_, err := io.Copy(output, shell)
 		if err != nil {
 			log.Errorf(err.Error())
 		}
@@ -728,7 +793,7 @@ func (ns *NodeSession) pipeInOut(ctx context.Context, shell io.ReadWriteCloser,
 	case types.SessionPeerMode:
 		// copy from the local input to the remote shell:
 		go func() {
-			if handlePeerControls(ns.terminal, ns.enableEscapeSequences, shell) {
+			if handlePeerControls(ns.terminal, ns.enableEscapeSequences, ns.escapePassThrough, shell) {
 				ns.forceDisconnect.Store(true)
 			}
 
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+// SessionsFilter selects the active session trackers returned by
+// ListSessions. Zero-valued fields do not filter.
+type SessionsFilter struct {
+	// Kinds restricts the result to sessions of the given kinds (SSH,
+	// kubernetes, database, app, desktop).
+	Kinds []types.SessionKind
+	// States restricts the result to sessions in the given states.
+	States []types.SessionState
+	// Participant restricts the result to sessions the given user
+	// participates in.
+	Participant string
+}
+
+// Match checks whether the given session tracker passes the filter.
+func (f *SessionsFilter) Match(tracker types.SessionTracker) bool {
+	if len(f.Kinds) > 0 {
+		var found bool
+		for _, kind := range f.Kinds {
+			if tracker.GetSessionKind() == kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.States) > 0 {
+		var found bool
+		for _, state := range f.States {
+			if tracker.GetState() == state {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Participant != "" {
+		var found bool
+		for _, participant := range tracker.GetParticipants() {
+			if participant.User == f.Participant {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ListSessions returns the active session trackers of the current cluster
+// that match the given filter. A nil filter returns all sessions the user is
+// allowed to see.
+func (tc *TeleportClient) ListSessions(ctx context.Context, filter *SessionsFilter) ([]types.SessionTracker, error) {
+	ctx, span := tc.Tracer.Start(
+		ctx,
+		"teleportClient/ListSessions",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+	)
+	defer span.End()
+
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	site := proxyClient.CurrentCluster()
+	defer site.Close()
+
+	trackers, err := site.GetActiveSessionTrackers(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if filter == nil {
+		return trackers, nil
+	}
+
+	var matched []types.SessionTracker
+	for _, tracker := range trackers {
+		if filter.Match(tracker) {
+			matched = append(matched, tracker)
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gravitational/trace"
+)
+
+// CallbackServer is a local HTTP server receiving SSO login callbacks that
+// stays bound across logins. Reusing one server keeps the redirect URI
+// stable, which matters for IdPs that whitelist specific redirect URIs.
+type CallbackServer struct {
+	listener net.Listener
+
+	mu sync.RWMutex
+	// handler serves the login currently in flight; nil between logins.
+	handler http.Handler
+}
+
+// NewCallbackServer binds bindAddr and starts serving SSO callbacks on it.
+// bindAddr may specify a single port ("127.0.0.1:8080") or an inclusive
+// port range ("127.0.0.1:8080-8090"), in which case the first free port in
+// the range is used. The server answers 404 until a login attaches to it.
+func NewCallbackServer(bindAddr string) (*CallbackServer, error) {
+	listener, err := newCallbackListener(bindAddr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	s := &CallbackServer{listener: listener}
+	go http.Serve(listener, http.HandlerFunc(s.serveHTTP))
+	return s, nil
+}
+
+// URL returns the base URL the server is reachable at.
+func (s *CallbackServer) URL() string {
+	return "http://" + s.listener.Addr().String()
+}
+
+// Close stops the server and releases the bound port.
+func (s *CallbackServer) Close() error {
+	return trace.Wrap(s.listener.Close())
+}
+
+// setHandler attaches the handler for a login in flight. Passing nil
+// detaches it, returning the server to its idle state.
+func (s *CallbackServer) setHandler(handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = handler
+}
+
+func (s *CallbackServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	handler := s.handler
+	s.mu.RUnlock()
+	if handler == nil {
+		http.NotFound(w, r)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// newCallbackListener binds the given address, which may specify a single
+// port or an inclusive port range ("host:8080-8090").
+func newCallbackListener(bindAddr string) (net.Listener, error) {
+	host, portSpec, err := net.SplitHostPort(bindAddr)
+	if err != nil {
+		return nil, trace.BadParameter("%v: make sure the address is host:port format for ipv4 and [ipv6]:port format for ipv6", err)
+	}
+
+	first, last, ok := parsePortRange(portSpec)
+	if !ok {
+		listener, err := net.Listen("tcp", bindAddr)
+		if err != nil {
+			return nil, trace.Wrap(err, "%v: could not bind to %v, make sure the address is host:port format for ipv4 and [ipv6]:port format for ipv6, and the address is not in use", err, bindAddr)
+		}
+		return listener, nil
+	}
+
+	for port := first; port <= last; port++ {
+		listener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err == nil {
+			return listener, nil
+		}
+	}
+	return nil, trace.LimitExceeded("could not bind to any port between %v and %v on %v, make sure at least one port in the range is free", first, last, host)
+}
+
+// parsePortRange parses an inclusive port range of the form "8080-8090".
+// It returns false if the string is not a port range.
+func parsePortRange(portSpec string) (first, last int, ok bool) {
+	firstStr, lastStr, found := strings.Cut(portSpec, "-")
+	if !found {
+		return 0, 0, false
+	}
+	first, firstErr := strconv.Atoi(firstStr)
+	last, lastErr := strconv.Atoi(lastStr)
+	if firstErr != nil || lastErr != nil || first < 1 || last > 65535 || first > last {
+		return 0, 0, false
+	}
+	return first, last, true
+}
+
+// String implements fmt.Stringer.
+func (s *CallbackServer) String() string {
+	return fmt.Sprintf("CallbackServer(%v)", s.listener.Addr())
+}
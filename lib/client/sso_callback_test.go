@@ -0,0 +1,111 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePortRange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		portSpec    string
+		first, last int
+		ok          bool
+	}{
+		{portSpec: "8080-8090", first: 8080, last: 8090, ok: true},
+		{portSpec: "8080-8080", first: 8080, last: 8080, ok: true},
+		{portSpec: "8080", ok: false},
+		{portSpec: "8090-8080", ok: false},
+		{portSpec: "0-8080", ok: false},
+		{portSpec: "8080-65536", ok: false},
+		{portSpec: "foo-bar", ok: false},
+		{portSpec: "", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.portSpec, func(t *testing.T) {
+			first, last, ok := parsePortRange(tt.portSpec)
+			require.Equal(t, tt.ok, ok)
+			if tt.ok {
+				require.Equal(t, tt.first, first)
+				require.Equal(t, tt.last, last)
+			}
+		})
+	}
+}
+
+func TestNewCallbackListenerPortRange(t *testing.T) {
+	t.Parallel()
+
+	// Occupy a port and verify that a range starting at it falls through to
+	// the next free port.
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer taken.Close()
+	_, takenPortStr, err := net.SplitHostPort(taken.Addr().String())
+	require.NoError(t, err)
+	takenPort, err := strconv.Atoi(takenPortStr)
+	require.NoError(t, err)
+
+	listener, err := newCallbackListener(fmt.Sprintf("127.0.0.1:%v-%v", takenPort, takenPort+10))
+	require.NoError(t, err)
+	defer listener.Close()
+	require.NotEqual(t, taken.Addr().String(), listener.Addr().String())
+
+	// A range with no free ports fails.
+	_, err = newCallbackListener(fmt.Sprintf("127.0.0.1:%v-%v", takenPort, takenPort))
+	require.Error(t, err)
+}
+
+func TestCallbackServerReuse(t *testing.T) {
+	t.Parallel()
+
+	srv, err := NewCallbackServer("127.0.0.1:0")
+	require.NoError(t, err)
+	defer srv.Close()
+
+	// No handler attached: the server answers 404.
+	resp, err := http.Get(srv.URL())
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// Attach a handler as a login would and verify it serves, then detach
+	// and verify the server returns to its idle state on the same address.
+	for i := 0; i < 2; i++ {
+		srv.setHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		resp, err = http.Get(srv.URL())
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		srv.setHandler(nil)
+		resp, err = http.Get(srv.URL())
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutPolicy groups the client-wide timeouts applied to individual
+// operations. Any field left at zero means no deadline is imposed for that
+// class of operation beyond whatever the caller's context carries.
+type TimeoutPolicy struct {
+	// DialTimeout bounds a single dial/connect attempt (e.g. ConnectToProxy).
+	DialTimeout time.Duration
+	// RPCTimeout bounds a single request/response exchange (e.g. Ping).
+	RPCTimeout time.Duration
+	// OperationTimeout bounds a whole logical client operation, which may
+	// consist of several dials and RPCs (e.g. ListNodesWithFilters).
+	OperationTimeout time.Duration
+}
+
+// OperationTimeoutError is returned when an operation exceeded a deadline
+// imposed by the client's TimeoutPolicy, as opposed to a deadline set by the
+// caller.
+type OperationTimeoutError struct {
+	// Op is the name of the operation that timed out.
+	Op string
+	// Timeout is the policy timeout that was exceeded.
+	Timeout time.Duration
+}
+
+// Error implements the error interface.
+func (e *OperationTimeoutError) Error() string {
+	return fmt.Sprintf("%v did not complete within %v (per the client timeout policy)", e.Op, e.Timeout)
+}
+
+// IsOperationTimeout returns true if the error (or any error it wraps) was
+// caused by a TimeoutPolicy deadline.
+func IsOperationTimeout(err error) bool {
+	var opErr *OperationTimeoutError
+	return errors.As(err, &opErr)
+}
+
+// timeoutClass selects which TimeoutPolicy field applies to an operation.
+type timeoutClass int
+
+const (
+	dialTimeout timeoutClass = iota
+	rpcTimeout
+	operationTimeout
+)
+
+// withTimeoutPolicy derives a context bounded by the policy timeout of the
+// given class. The returned cancel function must always be called. The
+// returned convert function maps context.DeadlineExceeded errors caused by
+// the policy into an OperationTimeoutError; errors of other origins are
+// returned unmodified.
+func (tc *TeleportClient) withTimeoutPolicy(ctx context.Context, class timeoutClass, op string) (context.Context, context.CancelFunc, func(error) error) {
+	noop := func(err error) error { return err }
+	if tc.TimeoutPolicy == nil {
+		return ctx, func() {}, noop
+	}
+	var timeout time.Duration
+	switch class {
+	case dialTimeout:
+		timeout = tc.TimeoutPolicy.DialTimeout
+	case rpcTimeout:
+		timeout = tc.TimeoutPolicy.RPCTimeout
+	case operationTimeout:
+		timeout = tc.TimeoutPolicy.OperationTimeout
+	}
+	if timeout <= 0 {
+		return ctx, func() {}, noop
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	convert := func(err error) error {
+		if err == nil {
+			return nil
+		}
+		// Only report a policy timeout if it was our deadline that fired,
+		// not the caller's context.
+		if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return &OperationTimeoutError{Op: op, Timeout: timeout}
+		}
+		return err
+	}
+	return timeoutCtx, cancel, convert
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// typescriptRecorder captures the output of an interactive session into a
+// script(1)-compatible typescript file plus a timing file usable with
+// scriptreplay(1). Recording happens entirely on the client and is
+// independent of any server-side session recording.
+//
+// Write errors are logged and disable further recording rather than
+// interrupting the live session.
+type typescriptRecorder struct {
+	mu     sync.Mutex
+	script io.WriteCloser
+	timing io.WriteCloser
+	// scriptPath is the path of the typescript file, for log messages.
+	scriptPath string
+	// last is the time of the previous write, used to compute the timing
+	// file delays.
+	last time.Time
+	// failed marks the recorder as broken after a write error; subsequent
+	// writes are silently dropped.
+	failed bool
+}
+
+// newTypescriptRecorder creates the recording directory if necessary and
+// opens <sessionID>.typescript and <sessionID>.timing inside it. Files are
+// created with owner-only permissions since session output may contain
+// sensitive data.
+//
+// When encryptionKey is set, both files get an additional ".enc" suffix and
+// their contents are sealed to the key with encryptingWriter framing, so only
+// the holder of the matching private key can read the recording.
+func newTypescriptRecorder(dir, sessionID string, encryptionKey *[recordingKeySize]byte) (*typescriptRecorder, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+
+	suffix := ""
+	if encryptionKey != nil {
+		suffix = ".enc"
+	}
+	scriptPath := filepath.Join(dir, sessionID+".typescript"+suffix)
+
+	scriptFile, err := os.OpenFile(scriptPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	timingFile, err := os.OpenFile(filepath.Join(dir, sessionID+".timing"+suffix), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		scriptFile.Close()
+		return nil, trace.ConvertSystemError(err)
+	}
+
+	var script, timing io.WriteCloser = scriptFile, timingFile
+	if encryptionKey != nil {
+		script = newEncryptingWriter(scriptFile, encryptionKey)
+		timing = newEncryptingWriter(timingFile, encryptionKey)
+	}
+
+	now := time.Now()
+	if _, err := fmt.Fprintf(script, "Script started on %s\n", now.Format(time.UnixDate)); err != nil {
+		script.Close()
+		timing.Close()
+		return nil, trace.ConvertSystemError(err)
+	}
+
+	return &typescriptRecorder{
+		script:     script,
+		timing:     timing,
+		scriptPath: scriptPath,
+		last:       now,
+	}, nil
+}
+
+// Write appends the session output chunk to the typescript file and records
+// its delay and length in the timing file. It always reports full success so
+// a local disk error never stalls the live session.
+func (r *typescriptRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failed {
+		return len(p), nil
+	}
+
+	now := time.Now()
+	delay := now.Sub(r.last).Seconds()
+	r.last = now
+
+	if _, err := r.script.Write(p); err != nil {
+		r.fail(err)
+		return len(p), nil
+	}
+	if _, err := fmt.Fprintf(r.timing, "%f %d\n", delay, len(p)); err != nil {
+		r.fail(err)
+	}
+	return len(p), nil
+}
+
+// fail logs the error and stops any further recording. The caller must hold
+// r.mu.
+func (r *typescriptRecorder) fail(err error) {
+	log.WithError(err).Warnf("Failed to write local session recording to %v, disabling capture.", r.scriptPath)
+	r.failed = true
+}
+
+// Close writes the typescript trailer and closes both files.
+func (r *typescriptRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errors []error
+	if !r.failed {
+		if _, err := fmt.Fprintf(r.script, "\nScript done on %s\n", time.Now().Format(time.UnixDate)); err != nil {
+			errors = append(errors, trace.ConvertSystemError(err))
+		}
+	}
+	if err := r.script.Close(); err != nil {
+		errors = append(errors, trace.ConvertSystemError(err))
+	}
+	if err := r.timing.Close(); err != nil {
+		errors = append(errors, trace.ConvertSystemError(err))
+	}
+	return trace.NewAggregate(errors...)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypescriptRecorder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	recorder, err := newTypescriptRecorder(dir, "test-session", nil)
+	require.NoError(t, err)
+
+	chunks := []string{"$ echo hello\r\n", "hello\r\n", "$ exit\r\n"}
+	for _, chunk := range chunks {
+		n, err := recorder.Write([]byte(chunk))
+		require.NoError(t, err)
+		require.Equal(t, len(chunk), n)
+	}
+	require.NoError(t, recorder.Close())
+
+	script, err := os.ReadFile(filepath.Join(dir, "test-session.typescript"))
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(script), "Script started on "))
+	require.Contains(t, string(script), strings.Join(chunks, ""))
+	require.Contains(t, string(script), "Script done on ")
+
+	timing, err := os.ReadFile(filepath.Join(dir, "test-session.timing"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(timing)), "\n")
+	require.Len(t, lines, len(chunks))
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		require.Len(t, fields, 2)
+		require.Equal(t, strconv.Itoa(len(chunks[i])), fields[1])
+	}
+
+	// opening the same session twice must not clobber existing evidence
+	_, err = newTypescriptRecorder(dir, "test-session", nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gravitational/teleport/lib/events"
+)
+
+const (
+	// DataUsageSSH is the usage kind for interactive SSH session traffic.
+	DataUsageSSH = "ssh"
+	// DataUsageForward is the usage kind for port forwarded traffic.
+	DataUsageForward = "forward"
+	// DataUsageSFTP is the usage kind for SFTP file transfer traffic.
+	DataUsageSFTP = "sftp"
+
+	// DataUsageEvent is the event type emitted on the events channel when a
+	// session, forwarded connection or file transfer finishes.
+	DataUsageEvent = "client.data.usage"
+)
+
+// DataUsageRecord is a snapshot of the traffic accumulated against a single
+// target, so bandwidth through shared bastions can be attributed to users
+// and sessions.
+type DataUsageRecord struct {
+	// Kind is one of DataUsageSSH, DataUsageForward or DataUsageSFTP.
+	Kind string
+	// User is the Teleport user the traffic is attributed to.
+	User string
+	// Target identifies the flow: the session ID for SSH sessions, the
+	// remote address for forwards and file transfers.
+	Target string
+	// BytesSent is the number of bytes sent to the remote end.
+	BytesSent uint64
+	// BytesReceived is the number of bytes received from the remote end.
+	BytesReceived uint64
+}
+
+// dataUsageAccounting accumulates per-target byte counts.
+type dataUsageAccounting struct {
+	mu      sync.Mutex
+	records map[string]*DataUsageRecord
+}
+
+func newDataUsageAccounting() *dataUsageAccounting {
+	return &dataUsageAccounting{records: make(map[string]*DataUsageRecord)}
+}
+
+func (a *dataUsageAccounting) add(kind, user, target string, sent, received uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := kind + "/" + target
+	record, ok := a.records[key]
+	if !ok {
+		record = &DataUsageRecord{Kind: kind, User: user, Target: target}
+		a.records[key] = record
+	}
+	record.BytesSent += sent
+	record.BytesReceived += received
+}
+
+func (a *dataUsageAccounting) snapshot() []DataUsageRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	records := make([]DataUsageRecord, 0, len(a.records))
+	for _, record := range a.records {
+		records = append(records, *record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Kind != records[j].Kind {
+			return records[i].Kind < records[j].Kind
+		}
+		return records[i].Target < records[j].Target
+	})
+	return records
+}
+
+// DataUsage returns a snapshot of the traffic accumulated by this client,
+// one record per kind and target, sorted for stable output.
+func (tc *TeleportClient) DataUsage() []DataUsageRecord {
+	if tc == nil || tc.dataUsage == nil {
+		return nil
+	}
+	return tc.dataUsage.snapshot()
+}
+
+// recordDataUsage attributes finished traffic to the client's user and
+// target, and emits a usage event on the events channel for listeners that
+// account in real time. Safe to call on a nil client so internal plumbing
+// doesn't need to guard against detached connections.
+func (tc *TeleportClient) recordDataUsage(kind, target string, sent, received uint64) {
+	if tc == nil || tc.dataUsage == nil || (sent == 0 && received == 0) {
+		return
+	}
+	tc.dataUsage.add(kind, tc.Username, target, sent, received)
+
+	// Best effort: drop the event when nobody is draining the channel.
+	select {
+	case tc.eventsCh <- events.EventFields{
+		events.EventType: DataUsageEvent,
+		events.EventUser: tc.Username,
+		"kind":           kind,
+		"target":         target,
+		"sent":           sent,
+		"received":       received,
+	}:
+	default:
+	}
+}
+
+// usageReadWriteCloser counts the bytes moved through a session stream:
+// reads come from the remote end, writes go to it.
+type usageReadWriteCloser struct {
+	io.ReadWriteCloser
+	sent     atomic.Uint64
+	received atomic.Uint64
+}
+
+func (u *usageReadWriteCloser) Read(p []byte) (int, error) {
+	n, err := u.ReadWriteCloser.Read(p)
+	u.received.Add(uint64(n))
+	return n, err
+}
+
+func (u *usageReadWriteCloser) Write(p []byte) (int, error) {
+	n, err := u.ReadWriteCloser.Write(p)
+	u.sent.Add(uint64(n))
+	return n, err
+}
+
+// usageStream counts the bytes moved through an SFTP progress stream: the
+// stream is written the transferred chunks on downloads and read on uploads.
+type usageStream struct {
+	stream   io.ReadWriter
+	sent     atomic.Uint64
+	received atomic.Uint64
+}
+
+func (u *usageStream) Write(p []byte) (int, error) {
+	u.received.Add(uint64(len(p)))
+	if u.stream != nil {
+		return u.stream.Write(p)
+	}
+	return len(p), nil
+}
+
+func (u *usageStream) Read(p []byte) (int, error) {
+	u.sent.Add(uint64(len(p)))
+	if u.stream != nil {
+		return u.stream.Read(p)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/lib/events"
+)
+
+func TestDataUsageAccounting(t *testing.T) {
+	t.Parallel()
+
+	usage := newDataUsageAccounting()
+	usage.add(DataUsageForward, "alice", "db.internal:5432", 100, 200)
+	usage.add(DataUsageForward, "alice", "db.internal:5432", 10, 20)
+	usage.add(DataUsageSSH, "alice", "session-1", 5, 6)
+
+	records := usage.snapshot()
+	require.Equal(t, []DataUsageRecord{
+		{Kind: DataUsageForward, User: "alice", Target: "db.internal:5432", BytesSent: 110, BytesReceived: 220},
+		{Kind: DataUsageSSH, User: "alice", Target: "session-1", BytesSent: 5, BytesReceived: 6},
+	}, records)
+}
+
+func TestRecordDataUsage(t *testing.T) {
+	t.Parallel()
+
+	tc := &TeleportClient{
+		Config:    Config{Username: "alice"},
+		eventsCh:  make(chan events.EventFields, 2),
+		dataUsage: newDataUsageAccounting(),
+	}
+
+	// Zero counts and nil clients are silently ignored.
+	tc.recordDataUsage(DataUsageForward, "db.internal:5432", 0, 0)
+	var nilTC *TeleportClient
+	nilTC.recordDataUsage(DataUsageForward, "db.internal:5432", 1, 1)
+	require.Empty(t, tc.DataUsage())
+	require.Empty(t, nilTC.DataUsage())
+
+	tc.recordDataUsage(DataUsageForward, "db.internal:5432", 100, 200)
+	records := tc.DataUsage()
+	require.Len(t, records, 1)
+	require.Equal(t, "alice", records[0].User)
+	require.Equal(t, uint64(100), records[0].BytesSent)
+	require.Equal(t, uint64(200), records[0].BytesReceived)
+
+	select {
+	case event := <-tc.EventsChannel():
+		require.Equal(t, DataUsageEvent, event.GetType())
+		require.Equal(t, "alice", event.GetString(events.EventUser))
+		require.Equal(t, "db.internal:5432", event.GetString("target"))
+	default:
+		t.Fatal("expected a data usage event on the events channel")
+	}
+
+	// A full events channel does not block accounting.
+	tc.eventsCh <- events.EventFields{}
+	tc.eventsCh <- events.EventFields{}
+	tc.recordDataUsage(DataUsageSSH, "session-1", 1, 2)
+	require.Len(t, tc.DataUsage(), 2)
+}
@@ -196,8 +196,15 @@ type SSHLoginSSO struct {
 	// Protocol is an optional protocol selection
 	Protocol string
 	// BindAddr is an optional host:port address to bind
-	// to for SSO login flows
+	// to for SSO login flows. The port may be an inclusive
+	// range, e.g. "127.0.0.1:8080-8090", in which case the
+	// first free port in the range is used.
 	BindAddr string
+	// CallbackServer is an optional pre-bound local server to receive
+	// the SSO callback on. When set it takes precedence over BindAddr
+	// and stays bound across logins, keeping the redirect URI stable
+	// for IdPs that whitelist specific redirect URIs.
+	CallbackServer *CallbackServer
 	// Browser can be used to pass the name of a browser to override the system
 	// default (not currently implemented), or set to 'none' to suppress
 	// browser opening entirely.
@@ -0,0 +1,102 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/web/app"
+)
+
+// WebSessionCookie is a browser cookie granting access to a Teleport web
+// application session.
+type WebSessionCookie struct {
+	// Name is the cookie name.
+	Name string
+	// Value is the cookie value.
+	Value string
+}
+
+// WebSessionExport bundles everything needed to use an application web
+// session from a browser: the launch URL and the cookies to install for the
+// application's public address. It bridges CLI login with browser workflows
+// without requiring the user to re-authenticate through the web UI.
+type WebSessionExport struct {
+	// URL is the address of the application the session grants access to.
+	URL string
+	// Host is the hostname the cookies must be scoped to.
+	Host string
+	// Cookies are the session cookies to install in the browser. They are
+	// bearer credentials: anyone holding them can use the session until it
+	// expires.
+	Cookies []WebSessionCookie
+	// Expires is when the web session expires.
+	Expires time.Time
+}
+
+// CreateAppBrowserSession creates an application web session for the given
+// request (where policy allows) and returns the cookies and launch URL a
+// browser needs to use it.
+func (tc *TeleportClient) CreateAppBrowserSession(ctx context.Context, req types.CreateAppSessionRequest) (*WebSessionExport, error) {
+	if req.PublicAddr == "" {
+		return nil, trace.BadParameter("missing application public address")
+	}
+
+	ws, err := tc.CreateAppSession(ctx, req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	host := req.PublicAddr
+	if h, _, err := net.SplitHostPort(req.PublicAddr); err == nil {
+		host = h
+	}
+
+	return &WebSessionExport{
+		URL:  fmt.Sprintf("https://%v", req.PublicAddr),
+		Host: host,
+		Cookies: []WebSessionCookie{
+			{Name: app.CookieName, Value: ws.GetName()},
+			{Name: app.SubjectCookieName, Value: ws.GetBearerToken()},
+		},
+		Expires: ws.GetExpiryTime(),
+	}, nil
+}
+
+// WriteCookieJar writes the exported cookies in the Netscape cookie jar
+// format understood by curl (--cookie) and common browser import extensions.
+func (e *WebSessionExport) WriteCookieJar(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "# Netscape HTTP Cookie File"); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	for _, cookie := range e.Cookies {
+		// domain, include-subdomains, path, secure, expiry, name, value
+		_, err := fmt.Fprintf(w, "%v\tFALSE\t/\tTRUE\t%v\t%v\t%v\n",
+			e.Host, e.Expires.Unix(), cookie.Name, cookie.Value)
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+	}
+	return nil
+}
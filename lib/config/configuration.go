@@ -1223,6 +1223,11 @@ func applyDiscoveryConfig(fc *FileConfig, cfg *service.Config) error {
 
 // applyKubeConfig applies file configuration for the "kubernetes_service" section.
 func applyKubeConfig(fc *FileConfig, cfg *service.Config) error {
+	var err error
+	cfg.Kube.EnableProxyProtocol, err = utils.ParseOnOff("proxy_protocol", fc.Kube.ProxyProtocol, true)
+	if err != nil {
+		return trace.Wrap(err)
+	}
 	if fc.Kube.ListenAddress != "" {
 		addr, err := utils.ParseHostPortAddr(fc.Kube.ListenAddress, int(defaults.SSHProxyListenPort))
 		if err != nil {
@@ -1248,6 +1253,11 @@ func applyKubeConfig(fc *FileConfig, cfg *service.Config) error {
 	if fc.Kube.KubeconfigFile != "" {
 		cfg.Kube.KubeconfigPath = fc.Kube.KubeconfigFile
 	}
+	cfg.Kube.DebugHeaders = fc.Kube.DebugHeaders
+	cfg.Kube.RecordPortForwardData, err = utils.ParseOnOff("record_port_forward_data", fc.Kube.RecordPortForwardData, true)
+	if err != nil {
+		return trace.Wrap(err)
+	}
 	if fc.Kube.KubeClusterName != "" {
 		cfg.Kube.KubeClusterName = fc.Kube.KubeClusterName
 	}
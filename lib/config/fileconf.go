@@ -1983,6 +1983,18 @@ type Kube struct {
 	DynamicLabels []CommandLabel `yaml:"commands,omitempty"`
 	// ResourceMatchers match cluster kube_cluster resources.
 	ResourceMatchers []ResourceMatcher `yaml:"resources,omitempty"`
+	// ProxyProtocol enables support for HAProxy proxy protocol version 1 when it is turned 'on'.
+	// Verify whether the service is in front of a trusted load balancer.
+	// The default value is 'on'.
+	ProxyProtocol string `yaml:"proxy_protocol,omitempty"`
+	// DebugHeaders enables injection of diagnostic headers into kube
+	// responses to aid troubleshooting. Meant for temporary debugging only,
+	// as the headers expose routing details.
+	DebugHeaders bool `yaml:"debug_headers,omitempty"`
+	// RecordPortForwardData enables metadata-only audit events for kubectl
+	// port-forward data streams (ports, byte counts and duration) when it is
+	// turned 'on'. No payload is captured. The default value is 'on'.
+	RecordPortForwardData string `yaml:"record_port_forward_data,omitempty"`
 }
 
 // ReverseTunnel is a SSH reverse tunnel maintained by one cluster's
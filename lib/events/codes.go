@@ -270,6 +270,12 @@ const (
 	// KubernetesClusterDeleteCode is the kube.delete event code.
 	KubernetesClusterDeleteCode = "T3012I"
 
+	// KubeAttachCode is an event code for a kubectl attach command.
+	KubeAttachCode = "T3013I"
+	// KubeAttachFailureCode is an event code for a failed kubectl attach
+	// command.
+	KubeAttachFailureCode = "T3013E"
+
 	// The following codes correspond to SFTP file operations.
 	SFTPOpenCode            = "TS001I"
 	SFTPOpenFailureCode     = "TS001E"
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"text/template"
 
 	"github.com/gravitational/trace"
 	"github.com/sirupsen/logrus"
@@ -71,6 +72,14 @@ type Values struct {
 	ImpersonateGroups []string
 	// Namespace allows to define the default namespace value.
 	Namespace string
+	// Namespaces optionally overrides Namespace per kubernetes cluster,
+	// keyed by kube cluster name.
+	Namespaces map[string]string
+	// ContextNameTemplate optionally overrides the default
+	// "<teleport-cluster>-<kube-cluster>" context naming. It is parsed as a
+	// Go template with {{.TeleportCluster}} and {{.KubeCluster}} fields, so
+	// platform teams can enforce their own naming conventions.
+	ContextNameTemplate string
 	// KubeClusters is a list of kubernetes clusters to generate contexts for.
 	KubeClusters []string
 	// SelectCluster is the name of the kubernetes cluster to set in
@@ -132,7 +141,10 @@ func Update(path string, v Values, storeAllCAs bool) error {
 		}
 
 		for _, c := range v.KubeClusters {
-			contextName := ContextName(v.TeleportClusterName, c)
+			contextName, err := v.contextName(c)
+			if err != nil {
+				return trace.Wrap(err)
+			}
 			authName := contextName
 			execArgs := []string{
 				"kube", "credentials",
@@ -159,10 +171,13 @@ func Update(path string, v Values, storeAllCAs bool) error {
 			}
 			config.AuthInfos[authName] = authInfo
 
-			setContext(config.Contexts, contextName, clusterName, authName, v.Namespace)
+			setContext(config.Contexts, contextName, clusterName, authName, v.namespaceFor(c))
 		}
 		if v.SelectCluster != "" {
-			contextName := ContextName(v.TeleportClusterName, v.SelectCluster)
+			contextName, err := v.contextName(v.SelectCluster)
+			if err != nil {
+				return trace.Wrap(err)
+			}
 			if _, ok := config.Contexts[contextName]; !ok {
 				return trace.BadParameter("can't switch kubeconfig context to cluster %q, run 'tsh kube ls' to see available clusters", v.SelectCluster)
 			}
@@ -180,10 +195,15 @@ func Update(path string, v Values, storeAllCAs bool) error {
 
 		clusterName := v.TeleportClusterName
 		contextName := clusterName
+		namespace := v.Namespace
 
 		if len(v.KubeClusters) == 1 {
 			kubeClusterName := v.KubeClusters[0]
-			contextName = ContextName(clusterName, kubeClusterName)
+			contextName, err = v.contextName(kubeClusterName)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			namespace = v.namespaceFor(kubeClusterName)
 		}
 
 		// Called when generating an identity file, use plaintext credentials.
@@ -203,7 +223,7 @@ func Update(path string, v Values, storeAllCAs bool) error {
 				ClientCertificateData: v.Credentials.TLSCert,
 				ClientKeyData:         rsaKeyPEM,
 			}
-			setContext(config.Contexts, contextName, clusterName, contextName, v.Namespace)
+			setContext(config.Contexts, contextName, clusterName, contextName, namespace)
 			setSelectedExtension(config.Contexts, config.CurrentContext, clusterName)
 			config.CurrentContext = contextName
 		} else if !trace.IsBadParameter(err) {
@@ -344,6 +364,46 @@ func ContextName(teleportCluster, kubeCluster string) string {
 	return fmt.Sprintf("%s-%s", teleportCluster, kubeCluster)
 }
 
+// ContextNameFromTemplate renders a kubeconfig context name from the given
+// Go template, exposing {{.TeleportCluster}} and {{.KubeCluster}} fields. An
+// empty template falls back to the default ContextName scheme.
+func ContextNameFromTemplate(tmpl, teleportCluster, kubeCluster string) (string, error) {
+	if tmpl == "" {
+		return ContextName(teleportCluster, kubeCluster), nil
+	}
+	t, err := template.New("kube-context").Parse(tmpl)
+	if err != nil {
+		return "", trace.BadParameter("failed to parse kube context name template %q: %v", tmpl, err)
+	}
+	var out bytes.Buffer
+	if err := t.Execute(&out, struct {
+		TeleportCluster string
+		KubeCluster     string
+	}{teleportCluster, kubeCluster}); err != nil {
+		return "", trace.BadParameter("failed to render kube context name template %q: %v", tmpl, err)
+	}
+	name := out.String()
+	if name == "" {
+		return "", trace.BadParameter("kube context name template %q rendered an empty name", tmpl)
+	}
+	return name, nil
+}
+
+// contextName renders the context name for the given kube cluster according
+// to the values' naming template.
+func (v Values) contextName(kubeCluster string) (string, error) {
+	return ContextNameFromTemplate(v.ContextNameTemplate, v.TeleportClusterName, kubeCluster)
+}
+
+// namespaceFor returns the default namespace for the given kube cluster,
+// preferring a per-cluster override over the global default.
+func (v Values) namespaceFor(kubeCluster string) string {
+	if namespace, ok := v.Namespaces[kubeCluster]; ok {
+		return namespace
+	}
+	return v.Namespace
+}
+
 // KubeClusterFromContext extracts the kubernetes cluster name from context
 // name generated by this package.
 func KubeClusterFromContext(contextName, teleportCluster string) string {
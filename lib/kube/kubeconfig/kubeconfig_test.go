@@ -318,6 +318,59 @@ func TestUpdateWithExec(t *testing.T) {
 	}
 }
 
+func TestContextNameFromTemplate(t *testing.T) {
+	name, err := ContextNameFromTemplate("", "teleport-cluster", "my-cluster")
+	require.NoError(t, err)
+	require.Equal(t, "teleport-cluster-my-cluster", name)
+
+	name, err = ContextNameFromTemplate("{{.KubeCluster}}@{{.TeleportCluster}}", "teleport-cluster", "my-cluster")
+	require.NoError(t, err)
+	require.Equal(t, "my-cluster@teleport-cluster", name)
+
+	_, err = ContextNameFromTemplate("{{.KubeCluster", "teleport-cluster", "my-cluster")
+	require.Error(t, err)
+
+	_, err = ContextNameFromTemplate(`{{""}}`, "teleport-cluster", "my-cluster")
+	require.Error(t, err)
+}
+
+func TestUpdateWithContextTemplateAndNamespaces(t *testing.T) {
+	const (
+		clusterName = "teleport-cluster"
+		clusterAddr = "https://1.2.3.6:3080"
+		tshPath     = "/path/to/tsh"
+	)
+
+	creds, _, err := genUserKey("localhost")
+	require.NoError(t, err)
+
+	kubeconfigPath, _ := setup(t)
+	err = Update(kubeconfigPath, Values{
+		TeleportClusterName: clusterName,
+		ClusterAddr:         clusterAddr,
+		Credentials:         creds,
+		Namespace:           "default-ns",
+		Namespaces:          map[string]string{"cluster-b": "team-b"},
+		ContextNameTemplate: "{{.TeleportCluster}}/{{.KubeCluster}}",
+		KubeClusters:        []string{"cluster-a", "cluster-b"},
+		Exec: &ExecValues{
+			TshBinaryPath: tshPath,
+		},
+	}, false)
+	require.NoError(t, err)
+
+	config, err := Load(kubeconfigPath)
+	require.NoError(t, err)
+
+	ctxA, ok := config.Contexts[clusterName+"/cluster-a"]
+	require.True(t, ok)
+	require.Equal(t, "default-ns", ctxA.Namespace)
+
+	ctxB, ok := config.Contexts[clusterName+"/cluster-b"]
+	require.True(t, ok)
+	require.Equal(t, "team-b", ctxB.Namespace)
+}
+
 func TestUpdateWithExecAndProxy(t *testing.T) {
 	const (
 		clusterName = "teleport-cluster"
@@ -0,0 +1,85 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/exp/slices"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+const (
+	// KindKubeExec is a pseudo-resource kind that can be used in role deny
+	// rules (with the create verb) to block kubectl exec while still
+	// allowing kubectl attach.
+	KindKubeExec = "kube_exec"
+	// KindKubeAttach is a pseudo-resource kind that can be used in role
+	// deny rules (with the create verb) to block kubectl attach while
+	// still allowing kubectl exec.
+	KindKubeAttach = "kube_attach"
+
+	// kubeSessionTypeLabel labels session recordings with the type of the
+	// session (exec or attach) so recordings of the two can be told apart.
+	kubeSessionTypeLabel = "teleport.dev/kube-session-type"
+)
+
+// isAttachRequest returns true when the request is a kubectl attach rather
+// than an exec; both are served by the same handler.
+func isAttachRequest(req *http.Request) bool {
+	return strings.HasSuffix(req.URL.Path, "/attach")
+}
+
+// kubeSessionType returns the session type label value for exec or attach
+// sessions.
+func kubeSessionType(isAttach bool) string {
+	if isAttach {
+		return "attach"
+	}
+	return "exec"
+}
+
+// checkExecAttachDenied returns an access denied error when one of the
+// user's roles explicitly denies the requested session type via a deny rule
+// on the kube_exec or kube_attach pseudo-resources. By default both session
+// types are allowed, matching the previous behavior.
+func (f *Forwarder) checkExecAttachDenied(ctx *authContext, isAttach bool) error {
+	kind := KindKubeExec
+	if isAttach {
+		kind = KindKubeAttach
+	}
+
+	roles, err := getRolesByName(f, ctx.Context.Identity.GetIdentity().Groups)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, role := range roles {
+		for _, rule := range role.GetRules(types.Deny) {
+			if !slices.Contains(rule.Resources, kind) {
+				continue
+			}
+			if rule.HasVerb(types.VerbCreate) || rule.HasVerb(types.Wildcard) {
+				return trace.AccessDenied("role %v denies kubectl %s for this user",
+					role.GetName(), kubeSessionType(isAttach))
+			}
+		}
+	}
+	return nil
+}
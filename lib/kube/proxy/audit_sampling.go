@@ -0,0 +1,99 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/exp/slices"
+)
+
+// AuditSamplingConfig controls sampling of kube.request audit events on
+// high-traffic clusters. Sampling only ever drops events that match none of
+// the always-record rules, so security-relevant events are kept in full.
+type AuditSamplingConfig struct {
+	// SampleRate is the fraction of sampleable kube.request events to emit,
+	// in the range (0, 1]. Zero disables sampling and emits every event.
+	SampleRate float64
+	// AlwaysRecordVerbs are HTTP methods exempt from sampling. Defaults to
+	// the mutating verbs (POST, PUT, PATCH, DELETE).
+	AlwaysRecordVerbs []string
+	// AlwaysRecordResources are resource kinds exempt from sampling, e.g.
+	// "secrets".
+	AlwaysRecordResources []string
+}
+
+// CheckAndSetDefaults validates the config and fills in defaults.
+func (c *AuditSamplingConfig) CheckAndSetDefaults() error {
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return trace.BadParameter("audit sample rate must be between 0 and 1, got %v", c.SampleRate)
+	}
+	if len(c.AlwaysRecordVerbs) == 0 {
+		c.AlwaysRecordVerbs = []string{
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+		}
+	}
+	return nil
+}
+
+// auditSampler decides which kube.request events are emitted based on the
+// AuditSamplingConfig.
+type auditSampler struct {
+	cfg AuditSamplingConfig
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// newAuditSampler returns an auditSampler for the given config. The config
+// must have been validated with CheckAndSetDefaults.
+func newAuditSampler(cfg AuditSamplingConfig, seed int64) *auditSampler {
+	return &auditSampler{
+		cfg:  cfg,
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// sample returns true if an event with the given verb, resource kind and
+// response code should be emitted. Error responses, verbs and resource
+// kinds on the always-record lists are never dropped.
+func (s *auditSampler) sample(verb, resourceKind string, status int) bool {
+	if s.cfg.SampleRate == 0 || s.cfg.SampleRate == 1 {
+		return true
+	}
+	// Always record non-2xx responses: denied or failed requests are the
+	// most security-relevant ones.
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return true
+	}
+	if slices.Contains(s.cfg.AlwaysRecordVerbs, verb) {
+		return true
+	}
+	if resourceKind != "" && slices.Contains(s.cfg.AlwaysRecordResources, resourceKind) {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Float64() < s.cfg.SampleRate
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditSampler(t *testing.T) {
+	t.Parallel()
+
+	newSampler := func(t *testing.T, cfg AuditSamplingConfig) *auditSampler {
+		require.NoError(t, cfg.CheckAndSetDefaults())
+		return newAuditSampler(cfg, 0 /* seed */)
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		sampler := newSampler(t, AuditSamplingConfig{})
+		for i := 0; i < 100; i++ {
+			require.True(t, sampler.sample(http.MethodGet, "pods", http.StatusOK))
+		}
+	})
+
+	t.Run("always records mutating verbs", func(t *testing.T) {
+		sampler := newSampler(t, AuditSamplingConfig{SampleRate: 0.01})
+		for _, verb := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+			for i := 0; i < 100; i++ {
+				require.True(t, sampler.sample(verb, "pods", http.StatusOK))
+			}
+		}
+	})
+
+	t.Run("always records errors", func(t *testing.T) {
+		sampler := newSampler(t, AuditSamplingConfig{SampleRate: 0.01})
+		for i := 0; i < 100; i++ {
+			require.True(t, sampler.sample(http.MethodGet, "pods", http.StatusForbidden))
+		}
+	})
+
+	t.Run("always records listed resources", func(t *testing.T) {
+		sampler := newSampler(t, AuditSamplingConfig{
+			SampleRate:            0.01,
+			AlwaysRecordResources: []string{"secrets"},
+		})
+		for i := 0; i < 100; i++ {
+			require.True(t, sampler.sample(http.MethodGet, "secrets", http.StatusOK))
+		}
+	})
+
+	t.Run("samples the rest", func(t *testing.T) {
+		sampler := newSampler(t, AuditSamplingConfig{SampleRate: 0.5})
+		var kept int
+		for i := 0; i < 1000; i++ {
+			if sampler.sample(http.MethodGet, "pods", http.StatusOK) {
+				kept++
+			}
+		}
+		require.Greater(t, kept, 350)
+		require.Less(t, kept, 650)
+	})
+
+	t.Run("invalid rate", func(t *testing.T) {
+		cfg := AuditSamplingConfig{SampleRate: 1.5}
+		require.Error(t, cfg.CheckAndSetDefaults())
+	})
+}
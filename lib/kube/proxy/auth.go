@@ -171,6 +171,8 @@ func extractKubeCreds(ctx context.Context, cluster string, clientCfg *rest.Confi
 		return nil, trace.Wrap(err, "failed to generate transport config from kubeconfig: %v", err)
 	}
 
+	credType, expiry := staticCredentialMetadata(clientCfg)
+
 	log.Debug("Initialized Kubernetes credentials")
 	return &staticKubeCreds{
 		tlsConfig:       tlsConfig,
@@ -178,6 +180,8 @@ func extractKubeCreds(ctx context.Context, cluster string, clientCfg *rest.Confi
 		targetAddr:      targetAddr,
 		kubeClient:      client,
 		clientRestCfg:   clientCfg,
+		credType:        credType,
+		expiry:          expiry,
 	}, nil
 }
 
@@ -192,6 +192,7 @@ current-context: foo
 						transportConfig: &transport.Config{},
 						kubeClient:      &kubernetes.Clientset{},
 						clientRestCfg:   &rest.Config{},
+						credType:        "kubeconfig",
 					},
 					kubeCluster: mustCreateKubernetesClusterV3(t, "foo"),
 				},
@@ -201,6 +202,7 @@ current-context: foo
 						transportConfig: &transport.Config{},
 						kubeClient:      &kubernetes.Clientset{},
 						clientRestCfg:   &rest.Config{},
+						credType:        "kubeconfig",
 					},
 					kubeCluster: mustCreateKubernetesClusterV3(t, "bar"),
 				},
@@ -210,6 +212,7 @@ current-context: foo
 						transportConfig: &transport.Config{},
 						kubeClient:      &kubernetes.Clientset{},
 						clientRestCfg:   &rest.Config{},
+						credType:        "kubeconfig",
 					},
 					kubeCluster: mustCreateKubernetesClusterV3(t, "baz"),
 				},
@@ -234,6 +237,7 @@ current-context: foo
 						transportConfig: &transport.Config{},
 						kubeClient:      &kubernetes.Clientset{},
 						clientRestCfg:   &rest.Config{},
+						credType:        "kubeconfig",
 					},
 					kubeCluster: mustCreateKubernetesClusterV3(t, teleClusterName),
 				},
@@ -251,6 +255,7 @@ current-context: foo
 						transportConfig: &transport.Config{},
 						kubeClient:      &kubernetes.Clientset{},
 						clientRestCfg:   &rest.Config{},
+						credType:        "kubeconfig",
 					},
 					kubeCluster: mustCreateKubernetesClusterV3(t, "foo"),
 				},
@@ -260,6 +265,7 @@ current-context: foo
 						transportConfig: &transport.Config{},
 						kubeClient:      &kubernetes.Clientset{},
 						clientRestCfg:   &rest.Config{},
+						credType:        "kubeconfig",
 					},
 					kubeCluster: mustCreateKubernetesClusterV3(t, "bar"),
 				},
@@ -269,6 +275,7 @@ current-context: foo
 						transportConfig: &transport.Config{},
 						kubeClient:      &kubernetes.Clientset{},
 						clientRestCfg:   &rest.Config{},
+						credType:        "kubeconfig",
 					},
 					kubeCluster: mustCreateKubernetesClusterV3(t, "baz"),
 				},
@@ -0,0 +1,81 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gravitational/trace"
+)
+
+// AuthorizationHookRequest carries the metadata of a Kubernetes API request
+// being authorized. Fields derived from the request path are best-effort and
+// may be empty for non-resource URLs.
+type AuthorizationHookRequest struct {
+	// User is the Teleport username making the request.
+	User string
+	// Groups are the Teleport roles of the user.
+	Groups []string
+	// Verb is the HTTP method of the request.
+	Verb string
+	// APIGroup is the Kubernetes API group and version, e.g. "core/v1".
+	APIGroup string
+	// Resource is the Kubernetes resource kind, e.g. "pods".
+	Resource string
+	// ResourceName is the name of the targeted resource, if any.
+	ResourceName string
+	// Namespace is the Kubernetes namespace of the targeted resource, if any.
+	Namespace string
+	// KubernetesCluster is the name of the target Kubernetes cluster.
+	KubernetesCluster string
+	// Path is the raw URL path of the request.
+	Path string
+}
+
+// AuthorizationHook is an optional external authorization check (e.g. an OPA
+// webhook) invoked after Teleport RBAC has allowed a Kubernetes request. A
+// returned error denies the request and is surfaced to the client as a kube
+// Forbidden status, so platform teams can layer fine-grained policies without
+// forking the Forwarder.
+type AuthorizationHook func(ctx context.Context, req AuthorizationHookRequest) error
+
+// runAuthorizationHook invokes the configured external authorization hook
+// for the given request, translating a denial into an access denied error.
+// It is a no-op when no hook is configured.
+func (f *Forwarder) runAuthorizationHook(req *http.Request, actx *authContext) error {
+	if f.cfg.AuthorizationHook == nil {
+		return nil
+	}
+	r := parseResourcePath(req.URL.Path)
+	hookReq := AuthorizationHookRequest{
+		User:              actx.User.GetName(),
+		Groups:            actx.Identity.GetIdentity().Groups,
+		Verb:              req.Method,
+		APIGroup:          r.apiGroup,
+		Resource:          r.resourceKind,
+		ResourceName:      r.resourceName,
+		Namespace:         r.namespace,
+		KubernetesCluster: actx.kubeClusterName,
+		Path:              req.URL.Path,
+	}
+	if err := f.cfg.AuthorizationHook(req.Context(), hookReq); err != nil {
+		f.log.WithError(err).Debugf("External authorization hook denied %s %s for user %q.",
+			hookReq.Verb, hookReq.Path, hookReq.User)
+		return trace.AccessDenied("access to %s %s denied by external authorization policy: %v",
+			hookReq.Verb, hookReq.Path, err)
+	}
+	return nil
+}
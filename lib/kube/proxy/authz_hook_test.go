@@ -0,0 +1,71 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/auth"
+)
+
+func TestRunAuthorizationHook(t *testing.T) {
+	t.Parallel()
+
+	user, err := types.NewUser("bob")
+	require.NoError(t, err)
+	actx := &authContext{
+		kubeClusterName: "kube-a",
+		Context: auth.Context{
+			User:     user,
+			Identity: identity,
+		},
+	}
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/dev/pods/nginx", nil)
+
+	// No hook configured: nothing to do.
+	f := &Forwarder{log: logrus.NewEntry(logrus.New())}
+	require.NoError(t, f.runAuthorizationHook(req, actx))
+
+	// The hook receives the parsed request metadata.
+	var got AuthorizationHookRequest
+	f.cfg.AuthorizationHook = func(ctx context.Context, req AuthorizationHookRequest) error {
+		got = req
+		return nil
+	}
+	require.NoError(t, f.runAuthorizationHook(req, actx))
+	require.Equal(t, "bob", got.User)
+	require.Equal(t, "GET", got.Verb)
+	require.Equal(t, "core/v1", got.APIGroup)
+	require.Equal(t, "pods", got.Resource)
+	require.Equal(t, "nginx", got.ResourceName)
+	require.Equal(t, "dev", got.Namespace)
+	require.Equal(t, "kube-a", got.KubernetesCluster)
+
+	// A hook error is translated into an access denied error so it reaches
+	// the client as a kube Forbidden status.
+	f.cfg.AuthorizationHook = func(ctx context.Context, req AuthorizationHookRequest) error {
+		return trace.BadParameter("namespace dev is frozen")
+	}
+	err = f.runAuthorizationHook(req, actx)
+	require.True(t, trace.IsAccessDenied(err))
+	require.ErrorContains(t, err, "namespace dev is frozen")
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/lib/kube/proxy/responsewriters"
+)
+
+// isBrowserRequest returns true when the request appears to originate from a
+// web browser rather than kubectl or another kubernetes client. Browsers
+// advertise text/html in their Accept header; kubernetes clients ask for
+// application/json or protobuf.
+func isBrowserRequest(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// browserErrorPage is the help page served to browsers that hit the
+// kubernetes endpoint, a common misconfiguration. kubectl and other
+// kubernetes clients keep receiving proper Status objects.
+const browserErrorPage = `<!DOCTYPE html>
+<html>
+<head><title>Teleport Kubernetes Proxy</title></head>
+<body>
+<h1>Teleport Kubernetes Proxy</h1>
+<p>This endpoint serves the Kubernetes API and is not meant to be accessed with a web browser.</p>
+<p>To access Kubernetes clusters protected by Teleport, use <code>tsh</code> and <code>kubectl</code>:</p>
+<pre>
+tsh login --proxy=&lt;proxy-address&gt;
+tsh kube login &lt;kube-cluster&gt;
+kubectl get pods
+</pre>
+<p>See the <a href="https://goteleport.com/docs/kubernetes-access/">Kubernetes Access documentation</a> for details.</p>
+<p>Error: %s</p>
+</body>
+</html>
+`
+
+// writeBrowserErrorPage writes a friendly HTML error page describing how to
+// access kubernetes clusters through Teleport.
+func (f *Forwarder) writeBrowserErrorPage(rw http.ResponseWriter, respErr error) {
+	rw.Header().Set(responsewriters.ContentTypeHeader, "text/html; charset=utf-8")
+	rw.WriteHeader(trace.ErrorToCode(respErr))
+	if _, err := fmt.Fprintf(rw, browserErrorPage, html.EscapeString(respErr.Error())); err != nil {
+		f.log.Warningf("Failed writing browser error response body: %v", err)
+	}
+}
+
+// negotiateErrorResponse writes the error in a format suitable for the
+// client: browsers get an HTML help page, everything else gets a kube Status
+// object. It returns true if the error was handled.
+func (f *Forwarder) negotiateErrorResponse(rw http.ResponseWriter, r *http.Request, respErr error) bool {
+	if !isBrowserRequest(r) {
+		return false
+	}
+	f.writeBrowserErrorPage(rw, respErr)
+	return true
+}
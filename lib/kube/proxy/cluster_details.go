@@ -42,6 +42,14 @@ type kubeDetails struct {
 	dynamicLabels *labels.Dynamic
 	// kubeCluster is the dynamic kube_cluster or a static generated from kubeconfig and that only has the name populated.
 	kubeCluster types.KubeCluster
+	// identityHeaders, if set, maps the Teleport identity into custom headers
+	// instead of Impersonate-* for clusters using external auth webhooks.
+	// See identityHeaderLabelPrefix.
+	identityHeaders *identityHeaders
+	// forwardRewrite, if set, injects static headers and rewrites the base
+	// path of forwarded requests. See staticHeaderLabelPrefix and
+	// basePathLabel.
+	forwardRewrite *forwardRewrite
 }
 
 // newClusterDetails creates a proxied kubeDetails structure given a dynamic cluster.
@@ -67,10 +75,22 @@ func newClusterDetails(ctx context.Context, cloudClients cloud.Clients, cluster
 		go dynLabels.Start()
 	}
 
+	idHeaders, err := parseIdentityHeaders(cluster)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	rewrite, err := parseForwardRewrite(cluster)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	return &kubeDetails{
-		kubeCreds:     creds,
-		dynamicLabels: dynLabels,
-		kubeCluster:   cluster,
+		kubeCreds:       creds,
+		dynamicLabels:   dynLabels,
+		kubeCluster:     cluster,
+		identityHeaders: idHeaders,
+		forwardRewrite:  rewrite,
 	}, nil
 }
 
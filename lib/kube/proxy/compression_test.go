@@ -0,0 +1,60 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCompressorDecompressorRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	payload := bytes.Repeat([]byte("kubernetes pod list payload "), 100)
+
+	for _, encoding := range []string{"", contentEncodingGZIP, contentEncodingZSTD} {
+		encoding := encoding
+		t.Run("encoding "+encoding, func(t *testing.T) {
+			t.Parallel()
+			headers := http.Header{}
+			if encoding != "" {
+				headers.Set(contentEncodingHeader, encoding)
+			}
+			compressor, decompressor, err := getResponseCompressorDecompressor(headers)
+			require.NoError(t, err)
+
+			var compressed bytes.Buffer
+			comp := compressor(&compressed)
+			_, err = comp.Write(payload)
+			require.NoError(t, err)
+			require.NoError(t, comp.Close())
+
+			var decompressed bytes.Buffer
+			require.NoError(t, decompressor(&decompressed, &compressed))
+			require.Equal(t, payload, decompressed.Bytes())
+		})
+	}
+
+	t.Run("unknown encoding", func(t *testing.T) {
+		t.Parallel()
+		headers := http.Header{}
+		headers.Set(contentEncodingHeader, "br")
+		_, _, err := getResponseCompressorDecompressor(headers)
+		require.Error(t, err)
+	})
+}
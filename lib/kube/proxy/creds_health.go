@@ -0,0 +1,98 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/gravitational/trace"
+	"k8s.io/client-go/rest"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+const (
+	// credentialHealthLabel is set on heartbeated kube clusters to surface
+	// whether the credentials backing them are still valid.
+	credentialHealthLabel = types.TeleportInternalLabelPrefix + "credential-health"
+	// credentialHealthHealthy marks clusters with valid credentials.
+	credentialHealthHealthy = "healthy"
+	// credentialHealthExpired marks clusters whose credentials expired and
+	// could not be refreshed.
+	credentialHealthExpired = "expired"
+)
+
+// staticCredentialMetadata determines the credential type of a kubeconfig
+// entry and, when the entry embeds a client certificate, its expiry. Exec
+// plugin credentials report no expiry because client-go re-runs the plugin
+// on use, refreshing them transparently.
+func staticCredentialMetadata(clientCfg *rest.Config) (credType string, expiry time.Time) {
+	switch {
+	case clientCfg.ExecProvider != nil:
+		return "kubeconfig exec plugin", time.Time{}
+	case clientCfg.BearerToken != "" || clientCfg.BearerTokenFile != "":
+		return "kubeconfig bearer token", time.Time{}
+	case len(clientCfg.CertData) > 0 || clientCfg.CertFile != "":
+		certData, err := rest.TLSConfigFor(clientCfg)
+		if err != nil || len(certData.Certificates) == 0 || len(certData.Certificates[0].Certificate) == 0 {
+			return "kubeconfig client certificate", time.Time{}
+		}
+		cert, err := x509.ParseCertificate(certData.Certificates[0].Certificate[0])
+		if err != nil {
+			return "kubeconfig client certificate", time.Time{}
+		}
+		return "kubeconfig client certificate", cert.NotAfter
+	default:
+		return "kubeconfig", time.Time{}
+	}
+}
+
+// checkCredentialHealth verifies the credentials backing the cluster are
+// still valid. Expired short-lived cloud credentials are refreshed in place;
+// anything else that expired is reported with an explicit error naming the
+// cluster and the credential type, instead of letting the upstream API
+// server fail the request opaquely.
+func (k *kubeDetails) checkCredentialHealth() error {
+	if k.kubeCreds == nil {
+		return nil
+	}
+	exp := k.validUntil()
+	if exp.IsZero() || time.Now().Before(exp) {
+		return nil
+	}
+
+	if dyn, ok := k.kubeCreds.(*dynamicKubeCreds); ok {
+		if err := dyn.renewClientset(k.kubeCluster); err != nil {
+			return trace.ConnectionProblem(err,
+				"credentials for kubernetes cluster %q (%s) expired at %v and could not be refreshed",
+				k.kubeCluster.GetName(), k.credentialType(), exp.Format(time.RFC3339))
+		}
+		return nil
+	}
+
+	return trace.ConnectionProblem(nil,
+		"credentials for kubernetes cluster %q (%s) expired at %v; update the kubeconfig entry with fresh credentials",
+		k.kubeCluster.GetName(), k.credentialType(), exp.Format(time.RFC3339))
+}
+
+// credentialHealth returns the heartbeat label value describing the current
+// credential state of the cluster.
+func (k *kubeDetails) credentialHealth() string {
+	if err := k.checkCredentialHealth(); err != nil {
+		return credentialHealthExpired
+	}
+	return credentialHealthHealthy
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	authztypes "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+func kubeClusterForTest(t *testing.T, name string) types.KubeCluster {
+	t.Helper()
+	cluster, err := types.NewKubernetesClusterV3(types.Metadata{Name: name}, types.KubernetesClusterSpecV3{})
+	require.NoError(t, err)
+	return cluster
+}
+
+func TestStaticCredentialMetadata(t *testing.T) {
+	t.Parallel()
+
+	credType, expiry := staticCredentialMetadata(&rest.Config{
+		ExecProvider: &clientcmdapi.ExecConfig{Command: "aws"},
+	})
+	require.Equal(t, "kubeconfig exec plugin", credType)
+	require.True(t, expiry.IsZero())
+
+	credType, expiry = staticCredentialMetadata(&rest.Config{BearerToken: "token"})
+	require.Equal(t, "kubeconfig bearer token", credType)
+	require.True(t, expiry.IsZero())
+
+	credType, expiry = staticCredentialMetadata(&rest.Config{})
+	require.Equal(t, "kubeconfig", credType)
+	require.True(t, expiry.IsZero())
+}
+
+func TestCheckCredentialHealthStatic(t *testing.T) {
+	t.Parallel()
+
+	cluster := kubeClusterForTest(t, "static-cluster")
+
+	// Credentials without a known expiry are always considered healthy.
+	details := &kubeDetails{
+		kubeCreds:   &staticKubeCreds{credType: "kubeconfig bearer token"},
+		kubeCluster: cluster,
+	}
+	require.NoError(t, details.checkCredentialHealth())
+	require.Equal(t, credentialHealthHealthy, details.credentialHealth())
+
+	// Expired static credentials produce an explicit error naming the
+	// cluster and the credential type.
+	details = &kubeDetails{
+		kubeCreds: &staticKubeCreds{
+			credType: "kubeconfig client certificate",
+			expiry:   time.Now().Add(-time.Hour),
+		},
+		kubeCluster: cluster,
+	}
+	err := details.checkCredentialHealth()
+	require.True(t, trace.IsConnectionProblem(err))
+	require.ErrorContains(t, err, "static-cluster")
+	require.ErrorContains(t, err, "kubeconfig client certificate")
+	require.Equal(t, credentialHealthExpired, details.credentialHealth())
+}
+
+func TestCheckCredentialHealthDynamicRefresh(t *testing.T) {
+	t.Parallel()
+
+	cluster := kubeClusterForTest(t, "eks-cluster")
+
+	var calls int
+	client := func(ctx context.Context, cluster types.KubeCluster) (*rest.Config, time.Time, error) {
+		calls++
+		return &rest.Config{Host: "https://localhost:443"}, time.Now().Add(time.Hour), nil
+	}
+	checker := func(ctx context.Context, cluster string, sarClient authztypes.SelfSubjectAccessReviewInterface) error {
+		return nil
+	}
+
+	dyn, err := newDynamicKubeCreds(context.Background(), cluster, logrus.StandardLogger(), client, checker)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, dyn.close()) })
+	require.Equal(t, 1, calls)
+
+	details := &kubeDetails{kubeCreds: dyn, kubeCluster: cluster}
+	require.NoError(t, details.checkCredentialHealth())
+	require.Equal(t, 1, calls)
+
+	// Expired short-lived credentials are refreshed in place.
+	dyn.Lock()
+	dyn.expiry = time.Now().Add(-time.Minute)
+	dyn.Unlock()
+	require.NoError(t, details.checkCredentialHealth())
+	require.Equal(t, 2, calls)
+	require.True(t, dyn.validUntil().After(time.Now()))
+}
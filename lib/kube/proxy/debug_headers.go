@@ -0,0 +1,69 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+)
+
+// Diagnostic response headers injected when ForwarderConfig.DebugHeaders is
+// enabled, identifying which hop served a request and how long the upstream
+// cluster took. They expose topology details, so the mode is meant for
+// admin-driven troubleshooting of multi-hop kube routing only.
+const (
+	// DebugHeaderEndpoint is the kube cluster endpoint the request was
+	// forwarded to.
+	DebugHeaderEndpoint = "X-Teleport-Kube-Endpoint"
+	// DebugHeaderServerID is the host ID of the Teleport service that
+	// handled the request.
+	DebugHeaderServerID = "X-Teleport-Kube-Server-Id"
+	// DebugHeaderCluster is the name of the Teleport cluster the handling
+	// service belongs to.
+	DebugHeaderCluster = "X-Teleport-Kube-Cluster"
+	// DebugHeaderUpstreamLatency is the elapsed time of the upstream round
+	// trip, as a Go duration string.
+	DebugHeaderUpstreamLatency = "X-Teleport-Kube-Upstream-Latency"
+)
+
+// debugHeadersRoundTripper wraps a session round tripper and stamps the
+// diagnostic headers onto every upstream response.
+type debugHeadersRoundTripper struct {
+	inner http.RoundTripper
+	clock clockwork.Clock
+	// endpoint is the address of the session's target kube cluster.
+	endpoint string
+	// serverID is the host ID of this Teleport service.
+	serverID string
+	// cluster is the name of this Teleport cluster.
+	cluster string
+}
+
+// RoundTrip sends the request upstream and injects the diagnostic headers
+// into the response.
+func (d *debugHeadersRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := d.clock.Now()
+	resp, err := d.inner.RoundTrip(req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	resp.Header.Set(DebugHeaderEndpoint, d.endpoint)
+	resp.Header.Set(DebugHeaderServerID, d.serverID)
+	resp.Header.Set(DebugHeaderCluster, d.cluster)
+	resp.Header.Set(DebugHeaderUpstreamLatency, d.clock.Now().Sub(start).String())
+	return resp, nil
+}
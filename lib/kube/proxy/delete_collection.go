@@ -0,0 +1,154 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/gravitational/trace"
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/exp/slices"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/httplib"
+	"github.com/gravitational/teleport/lib/kube/proxy/responsewriters"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/utils"
+)
+
+// Kinds of namespaced resources whose delete-collection requests are
+// intercepted alongside pods. Roles cannot express these kinds in
+// kubernetes_resources yet, so per-resource matching only applies to the
+// kinds listed in types.KubernetesResourcesKinds; the others are authorized
+// at the cluster level, exactly like their single-resource deletes.
+const (
+	kindKubeSecret     = "secret"
+	kindKubeDeployment = "deployment"
+	kindKubeJob        = "job"
+)
+
+// deleteResourcesCollection intercepts delete-collection requests for
+// namespaced kinds other than pods. It lists the resources from the upstream
+// cluster and deletes each one individually using the kube principals the
+// user's roles allow for it, so bulk deletes behave consistently with
+// per-resource deletes across kinds.
+func (f *Forwarder) deleteResourcesCollection(ctx *authContext, w http.ResponseWriter, req *http.Request, p httprouter.Params) (resp interface{}, err error) {
+	sess, err := f.newClusterSession(*ctx)
+	if err != nil {
+		// This error goes to kubernetes client and is not visible in the logs
+		// of the teleport server if not logged here.
+		f.log.Errorf("Failed to create cluster session: %v.", err)
+		return nil, trace.Wrap(err)
+	}
+
+	sess.upgradeToHTTP2 = true
+	sess.forwarder, err = f.makeSessionForwarder(sess)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	if err := f.setupForwardingHeaders(sess, req); err != nil {
+		// This error goes to kubernetes client and is not visible in the logs
+		// of the teleport server if not logged here.
+		f.log.Errorf("Failed to set up forwarding headers: %v.", err)
+		return nil, trace.Wrap(err)
+	}
+	// status holds the returned response code.
+	var status int
+	// Check if the target Kubernetes cluster is not served by the current service.
+	// If it's the case, forward the request to the target Kube Service where the
+	// filtering logic will be applied.
+	if !f.isLocalKubeCluster(sess) {
+		rw := httplib.NewResponseStatusRecorder(w)
+		sess.forwarder.ServeHTTP(rw, req)
+		status = rw.Status()
+	} else {
+		memoryRW := responsewriters.NewMemoryResponseWriter()
+		listReq := req.Clone(req.Context())
+		// reset body and method since list does not need the body response.
+		listReq.Body = nil
+		listReq.Method = http.MethodGet
+		sess.forwarder.ServeHTTP(memoryRW, listReq)
+		// decompress the response body to be able to parse it.
+		if err := decompressInplace(memoryRW); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		status, err = f.handleDeleteCollectionReq(req, &sess.authContext, memoryRW, w)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
+	f.emitAuditEvent(ctx, req, sess, status)
+
+	return nil, nil
+}
+
+// deleteCollectionItem deletes a single resource from a delete-collection
+// request using the kube principals the user's roles allow for it. It
+// reports whether the resource was deleted; items whose principals do not
+// match or whose delete fails upstream are skipped.
+func (f *Forwarder) deleteCollectionItem(req *http.Request, authCtx *authContext, details *kubeDetails, resource types.KubernetesResource, deleteFn func(*kubernetes.Clientset) error) (bool, error) {
+	impersonatedUsers, impersonatedGroups, err := f.impersonationPrincipalsForResource(authCtx, req.Header, resource)
+	// no match was found, we ignore the request.
+	if err != nil {
+		return false, nil
+	}
+	// create a new kubernetes.Client using the impersonated users and groups
+	// that matched the current resource.
+	client, err := newImpersonatedKubeClient(details.kubeCreds, impersonatedUsers, impersonatedGroups)
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	// delete each resource individually.
+	if err := deleteFn(client); err != nil {
+		// TODO(tigrato): check what should we do when delete returns an error.
+		// Should we check if it's permission error?
+		// Check if the resource has already been deleted by a concurrent request
+		return false, nil
+	}
+	return true, nil
+}
+
+// impersonationPrincipalsForResource computes the impersonated kube users and
+// groups the user's roles allow for the given resource. Kinds that roles
+// cannot express in kubernetes_resources skip per-resource matching, since
+// access to them was already authorized at the cluster level.
+func (f *Forwarder) impersonationPrincipalsForResource(authCtx *authContext, header http.Header, resource types.KubernetesResource) (users string, groups []string, err error) {
+	matchers := []services.RoleMatcher{
+		services.NewKubernetesClusterLabelMatcher(authCtx.kubeClusterLabels),
+	}
+	if slices.Contains(types.KubernetesResourcesKinds, resource.Kind) {
+		matchers = append(matchers, services.NewKubernetesResourceMatcher(resource))
+	}
+	allowedKubeGroups, allowedKubeUsers, err := authCtx.Checker.CheckKubeGroupsAndUsers(
+		authCtx.sessionTTL,
+		false,
+		matchers...,
+	)
+	if err != nil {
+		return "", nil, trace.Wrap(err)
+	}
+	allowedKubeUsers, allowedKubeGroups = fillDefaultKubePrincipalDetails(allowedKubeUsers, allowedKubeGroups, authCtx.User.GetName())
+
+	impersonatedUsers, impersonatedGroups, err := computeImpersonatedPrincipals(
+		f.log, utils.StringsSet(allowedKubeUsers), utils.StringsSet(allowedKubeGroups),
+		header,
+	)
+	return impersonatedUsers, impersonatedGroups, trace.Wrap(err)
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+const (
+	// staticHeaderLabelPrefix is the kube_cluster static label prefix that
+	// configures static header injection for a cluster. The label key suffix
+	// names the header and the label value is sent verbatim with every
+	// forwarded request, e.g.:
+	//
+	//	static-headers.teleport.dev/X-Audience: "managed-cluster-1"
+	//
+	// Impersonate-* headers cannot be configured this way, the forwarder
+	// alone controls impersonation.
+	staticHeaderLabelPrefix = "static-headers.teleport.dev/"
+	// basePathLabel is the kube_cluster static label that configures a base
+	// path prepended to the API path of every forwarded request, for managed
+	// clusters whose API server is served under a path prefix.
+	basePathLabel = "rewrite.teleport.dev/base-path"
+)
+
+// forwardRewrite holds the per-cluster static headers and base path applied
+// to every request forwarded to the cluster.
+type forwardRewrite struct {
+	// headers are set on forwarded requests, overriding any client-supplied
+	// values for the same header.
+	headers http.Header
+	// basePath, if set, is prepended to the request path.
+	basePath string
+}
+
+// parseForwardRewrite builds the forwarding rewrite configured on the
+// cluster's static labels. It returns nil when the cluster has no such
+// labels.
+func parseForwardRewrite(cluster types.KubeCluster) (*forwardRewrite, error) {
+	var rewrite *forwardRewrite
+	ensure := func() *forwardRewrite {
+		if rewrite == nil {
+			rewrite = &forwardRewrite{headers: make(http.Header)}
+		}
+		return rewrite
+	}
+
+	for key, value := range cluster.GetStaticLabels() {
+		switch {
+		case key == basePathLabel:
+			if !strings.HasPrefix(value, "/") {
+				return nil, trace.BadParameter("base path %q of cluster %q must start with a slash", value, cluster.GetName())
+			}
+			ensure().basePath = strings.TrimSuffix(path.Clean(value), "/")
+		case strings.HasPrefix(key, staticHeaderLabelPrefix):
+			header := strings.TrimPrefix(key, staticHeaderLabelPrefix)
+			if header == "" {
+				return nil, trace.BadParameter("static header label %q of cluster %q is missing a header name", key, cluster.GetName())
+			}
+			if strings.HasPrefix(http.CanonicalHeaderKey(header), ImpersonateHeaderPrefix) {
+				return nil, trace.BadParameter("static header %q of cluster %q must not use the %v prefix", header, cluster.GetName(), ImpersonateHeaderPrefix)
+			}
+			ensure().headers.Set(header, value)
+		}
+	}
+	return rewrite, nil
+}
+
+// apply sets the static headers on the request and prepends the base path.
+// Client-supplied values for the configured headers are replaced, never
+// appended to.
+func (r *forwardRewrite) apply(req *http.Request) {
+	for header, values := range r.headers {
+		req.Header[header] = nil
+		for _, value := range values {
+			req.Header.Add(header, value)
+		}
+	}
+	if r.basePath != "" {
+		req.URL.Path = r.basePath + req.URL.Path
+	}
+}
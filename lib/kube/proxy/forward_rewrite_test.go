@@ -0,0 +1,93 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+func TestParseForwardRewrite(t *testing.T) {
+	t.Parallel()
+
+	newCluster := func(t *testing.T, labels map[string]string) types.KubeCluster {
+		cluster, err := types.NewKubernetesClusterV3(types.Metadata{
+			Name:   "test",
+			Labels: labels,
+		}, types.KubernetesClusterSpecV3{})
+		require.NoError(t, err)
+		return cluster
+	}
+
+	t.Run("no labels", func(t *testing.T) {
+		rewrite, err := parseForwardRewrite(newCluster(t, map[string]string{"env": "prod"}))
+		require.NoError(t, err)
+		require.Nil(t, rewrite)
+	})
+
+	t.Run("impersonation header rejected", func(t *testing.T) {
+		_, err := parseForwardRewrite(newCluster(t, map[string]string{
+			staticHeaderLabelPrefix + "impersonate-user": "admin",
+		}))
+		require.Error(t, err)
+	})
+
+	t.Run("relative base path rejected", func(t *testing.T) {
+		_, err := parseForwardRewrite(newCluster(t, map[string]string{
+			basePathLabel: "k8s",
+		}))
+		require.Error(t, err)
+	})
+
+	t.Run("applies headers and base path", func(t *testing.T) {
+		rewrite, err := parseForwardRewrite(newCluster(t, map[string]string{
+			staticHeaderLabelPrefix + "X-Audience": "managed-cluster-1",
+			basePathLabel:                          "/k8s/",
+		}))
+		require.NoError(t, err)
+		require.NotNil(t, rewrite)
+
+		req := &http.Request{
+			URL: &url.URL{Path: "/api/v1/namespaces"},
+			Header: http.Header{
+				// Client-supplied values for configured headers are replaced.
+				"X-Audience": []string{"spoofed"},
+			},
+		}
+		rewrite.apply(req)
+		require.Equal(t, []string{"managed-cluster-1"}, req.Header.Values("X-Audience"))
+		require.Equal(t, "/k8s/api/v1/namespaces", req.URL.Path)
+	})
+
+	t.Run("headers only", func(t *testing.T) {
+		rewrite, err := parseForwardRewrite(newCluster(t, map[string]string{
+			staticHeaderLabelPrefix + "X-Audience": "managed-cluster-1",
+		}))
+		require.NoError(t, err)
+		require.NotNil(t, rewrite)
+
+		req := &http.Request{
+			URL:    &url.URL{Path: "/api/v1/namespaces"},
+			Header: http.Header{},
+		}
+		rewrite.apply(req)
+		require.Equal(t, "/api/v1/namespaces", req.URL.Path)
+	})
+}
@@ -50,6 +50,8 @@ import (
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/exp/slices"
 	"golang.org/x/net/http2"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -79,6 +81,7 @@ import (
 	"github.com/gravitational/teleport/lib/kube/proxy/responsewriters"
 	"github.com/gravitational/teleport/lib/kube/proxy/streamproto"
 	kubeutils "github.com/gravitational/teleport/lib/kube/utils"
+	"github.com/gravitational/teleport/lib/observability/metrics"
 	"github.com/gravitational/teleport/lib/reversetunnel"
 	"github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/teleport/lib/srv"
@@ -154,6 +157,33 @@ type ForwarderConfig struct {
 	CheckImpersonationPermissions ImpersonationPermissionsChecker
 	// PublicAddr is the address that can be used to reach the kube cluster
 	PublicAddr string
+	// DebugHeaders enables injection of diagnostic headers (selected
+	// endpoint, server ID, Teleport cluster, upstream latency) into
+	// responses to simplify troubleshooting multi-hop kube routing. The
+	// headers expose topology details, so this should only be enabled by an
+	// admin while debugging.
+	DebugHeaders bool
+	// RecordPortForwardData enables emission of metadata-only audit events
+	// describing completed port forward data streams (target port, byte
+	// counts and duration). No payload is captured.
+	RecordPortForwardData bool
+	// PortForwardMaxStreams caps the number of concurrent streams a single
+	// port forwarding session may have open. Zero means no limit.
+	PortForwardMaxStreams int
+	// AuthorizationHook is an optional external authorization check invoked
+	// after Teleport RBAC for each Kubernetes request. Denials are returned
+	// to the client as kube Forbidden status responses.
+	AuthorizationHook AuthorizationHook
+	// AuditSampling controls sampling of kube.request audit events.
+	AuditSampling AuditSamplingConfig
+	// RetryThrottledGets enables a bounded client-transparent retry of GET
+	// requests throttled by the upstream API server (429/503). Throttled
+	// responses are surfaced in metrics and logs either way.
+	RetryThrottledGets bool
+	// KubeconfigReloadInterval, when set, enables periodic re-reading of the
+	// kubeconfig file so contexts added or removed on disk are registered and
+	// unregistered without a restart. Zero disables reloading.
+	KubeconfigReloadInterval time.Duration
 	// log is the logger function
 	log logrus.FieldLogger
 }
@@ -199,6 +229,9 @@ func (f *ForwarderConfig) CheckAndSetDefaults() error {
 	if f.Component == "" {
 		f.Component = "kube_forwarder"
 	}
+	if err := f.AuditSampling.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
 
 	if f.CheckImpersonationPermissions == nil {
 		f.CheckImpersonationPermissions = checkImpersonationPermissions
@@ -230,6 +263,15 @@ func NewForwarder(cfg ForwarderConfig) (*Forwarder, error) {
 		return nil, trace.Wrap(err)
 	}
 
+	if err := metrics.RegisterPrometheusCollectors(
+		kubeUpstreamThrottledRequests,
+		kubeUploadQueueBytes,
+		kubeUploadQueueFiles,
+		kubeThrottledExecSessions,
+	); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	clientCredentials, err := ttlmap.New(defaults.ClientCacheSize)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -250,7 +292,10 @@ func NewForwarder(cfg ForwarderConfig) (*Forwarder, error) {
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
 		},
-		clusterDetails: make(map[string]*kubeDetails),
+		clusterDetails:     make(map[string]*kubeDetails),
+		kubeconfigClusters: make(map[string]struct{}),
+		auditSampler:       newAuditSampler(cfg.AuditSampling, cfg.Clock.Now().UnixNano()),
+		uploadMonitor:      newUploadQueueMonitor(cfg.DataDir, cfg.Clock),
 	}
 
 	fwd.router.UseRawPath = true
@@ -267,6 +312,9 @@ func NewForwarder(cfg ForwarderConfig) (*Forwarder, error) {
 	fwd.router.GET("/api/:ver/pods", fwd.withAuth(fwd.listPods))
 	fwd.router.GET("/api/:ver/namespaces/:podNamespace/pods", fwd.withAuth(fwd.listPods))
 	fwd.router.DELETE("/api/:ver/namespaces/:podNamespace/pods", fwd.withAuth(fwd.deletePodsCollection))
+	fwd.router.DELETE("/api/:ver/namespaces/:podNamespace/secrets", fwd.withAuth(fwd.deleteResourcesCollection))
+	fwd.router.DELETE("/apis/apps/:ver/namespaces/:podNamespace/deployments", fwd.withAuth(fwd.deleteResourcesCollection))
+	fwd.router.DELETE("/apis/batch/:ver/namespaces/:podNamespace/jobs", fwd.withAuth(fwd.deleteResourcesCollection))
 	fwd.router.POST("/api/:ver/namespaces/:podNamespace/pods", fwd.withAuth(
 		func(ctx *authContext, w http.ResponseWriter, r *http.Request, _ httprouter.Params) (interface{}, error) {
 			// Forward pod creation to default handler.
@@ -286,6 +334,12 @@ func NewForwarder(cfg ForwarderConfig) (*Forwarder, error) {
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
+		for name := range fwd.clusterDetails {
+			fwd.kubeconfigClusters[name] = struct{}{}
+		}
+		if cfg.KubeconfigPath != "" && cfg.KubeconfigReloadInterval > 0 {
+			go fwd.kubeconfigReloadLoop(closeCtx, cfg.KubeconfigReloadInterval)
+		}
 	}
 	return fwd, nil
 }
@@ -313,11 +367,19 @@ type Forwarder struct {
 	// clusterDetails contain kubernetes credentials for multiple clusters.
 	// map key is cluster name.
 	clusterDetails map[string]*kubeDetails
-	rwMutexDetails sync.RWMutex
+	// kubeconfigClusters tracks which entries of clusterDetails were loaded
+	// from the kubeconfig file, so reloads only unregister those.
+	kubeconfigClusters map[string]struct{}
+	rwMutexDetails     sync.RWMutex
 	// sessions tracks in-flight sessions
 	sessions map[uuid.UUID]*session
 	// upgrades connections to websockets
 	upgrader websocket.Upgrader
+	// auditSampler decides which kube.request events are emitted.
+	auditSampler *auditSampler
+	// uploadMonitor applies backpressure to new exec sessions when the
+	// session recording upload queue backs up.
+	uploadMonitor *uploadQueueMonitor
 }
 
 // Close signals close to all outstanding or background operations
@@ -499,11 +561,17 @@ func (f *Forwarder) withAuthStd(handler handlerWithAuthFuncStd) http.HandlerFunc
 	return httplib.MakeStdHandlerWithErrorWriter(func(w http.ResponseWriter, req *http.Request) (interface{}, error) {
 		authContext, err := f.authenticate(req)
 		if err != nil {
+			if f.negotiateErrorResponse(w, req, err) {
+				return nil, nil
+			}
 			return nil, trace.Wrap(err)
 		}
 		if err := f.authorize(req.Context(), authContext); err != nil {
 			return nil, trace.Wrap(err)
 		}
+		if err := f.runAuthorizationHook(req, authContext); err != nil {
+			return nil, trace.Wrap(err)
+		}
 
 		return handler(authContext, w, req)
 	}, f.formatResponseError)
@@ -528,11 +596,17 @@ func (f *Forwarder) withAuth(handler handlerWithAuthFunc) httprouter.Handle {
 	return httplib.MakeHandlerWithErrorWriter(func(w http.ResponseWriter, req *http.Request, p httprouter.Params) (interface{}, error) {
 		authContext, err := f.authenticate(req)
 		if err != nil {
+			if f.negotiateErrorResponse(w, req, err) {
+				return nil, nil
+			}
 			return nil, trace.Wrap(err)
 		}
 		if err := f.authorize(req.Context(), authContext); err != nil {
 			return nil, trace.Wrap(err)
 		}
+		if err := f.runAuthorizationHook(req, authContext); err != nil {
+			return nil, trace.Wrap(err)
+		}
 		err = f.acquireConnectionLockWithIdentity(req.Context(), authContext)
 		if err != nil {
 			return nil, trace.Wrap(err)
@@ -560,6 +634,9 @@ func (f *Forwarder) withAuthPassthrough(handler handlerWithAuthFunc) httprouter.
 }
 
 func (f *Forwarder) formatForwardResponseError(rw http.ResponseWriter, r *http.Request, respErr error) {
+	if f.negotiateErrorResponse(rw, r, respErr) {
+		return
+	}
 	f.formatResponseError(rw, respErr)
 }
 
@@ -748,6 +825,9 @@ func (f *Forwarder) emitAuditEvent(ctx *authContext, req *http.Request, sess *cl
 	if r.skipEvent {
 		return
 	}
+	if !f.auditSampler.sample(req.Method, r.resourceKind, status) {
+		return
+	}
 	// Emit audit event.
 	event := &apievents.KubeRequest{
 		Metadata: apievents.Metadata{
@@ -1299,11 +1379,14 @@ func (f *Forwarder) execNonInteractive(ctx *authContext, w http.ResponseWriter,
 
 	sessionStart := f.cfg.Clock.Now().UTC()
 
+	isAttach := isAttachRequest(req)
+
 	serverMetadata := apievents.ServerMetadata{
 		ServerID:        f.cfg.HostID,
 		ServerNamespace: f.cfg.Namespace,
 		ServerHostname:  sess.teleportCluster.name,
 		ServerAddr:      sess.kubeAddress,
+		ServerLabels:    map[string]string{kubeSessionTypeLabel: kubeSessionType(isAttach)},
 	}
 
 	sessionMetadata := apievents.SessionMetadata{
@@ -1383,9 +1466,16 @@ func (f *Forwarder) execNonInteractive(ctx *authContext, w http.ResponseWriter,
 		}
 	}()
 
+	failureCode := events.ExecFailureCode
+	successCode := events.ExecCode
+	if isAttach {
+		failureCode = events.KubeAttachFailureCode
+		successCode = events.KubeAttachCode
+	}
+
 	executor, err := f.getExecutor(*ctx, sess, req)
 	if err != nil {
-		execEvent.Code = events.ExecFailureCode
+		execEvent.Code = failureCode
 		execEvent.Error, execEvent.ExitCode = exitCode(err)
 
 		f.log.WithError(err).Warning("Failed creating executor.")
@@ -1394,7 +1484,7 @@ func (f *Forwarder) execNonInteractive(ctx *authContext, w http.ResponseWriter,
 
 	streamOptions := proxy.options()
 	if err = executor.StreamWithContext(req.Context(), streamOptions); err != nil {
-		execEvent.Code = events.ExecFailureCode
+		execEvent.Code = failureCode
 		execEvent.Error, execEvent.ExitCode = exitCode(err)
 
 		f.log.WithError(err).Warning("Executor failed while streaming.")
@@ -1405,7 +1495,7 @@ func (f *Forwarder) execNonInteractive(ctx *authContext, w http.ResponseWriter,
 		return nil, nil
 	}
 
-	execEvent.Code = events.ExecCode
+	execEvent.Code = successCode
 
 	return nil, nil
 }
@@ -1444,6 +1534,16 @@ func (f *Forwarder) exec(ctx *authContext, w http.ResponseWriter, req *http.Requ
 		}
 	}()
 
+	// Async recordings buffer on local disk first; hold back or refuse new
+	// exec sessions while the upload queue is backed up so recordings are
+	// not lost if the pod dies.
+	if !services.IsRecordSync(ctx.recordingConfig.GetMode()) {
+		if err := f.uploadMonitor.waitForCapacity(); err != nil {
+			f.log.WithError(err).Warn("Session recording upload queue is saturated, refusing exec session.")
+			return nil, trace.Wrap(err)
+		}
+	}
+
 	sess, err := f.newClusterSession(*ctx)
 	if err != nil {
 		// This error goes to kubernetes client and is not visible in the logs
@@ -1493,6 +1593,10 @@ func (f *Forwarder) exec(ctx *authContext, w http.ResponseWriter, req *http.Requ
 		return f.remoteExec(ctx, w, req, p, sess, request, proxy)
 	}
 
+	if err := f.checkExecAttachDenied(ctx, isAttachRequest(req)); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	if !request.tty {
 		resp, err = f.execNonInteractive(ctx, w, req, p, request, proxy, sess)
 		return
@@ -1602,6 +1706,62 @@ func (f *Forwarder) portForward(ctx *authContext, w http.ResponseWriter, req *ht
 		}
 	}
 
+	var onPortForwardData portForwardDataCallback
+	if f.cfg.RecordPortForwardData {
+		onPortForwardData = func(addr string, txBytes, rxBytes uint64, d time.Duration) {
+			if sess.noAuditEvents {
+				return
+			}
+			portForward := &apievents.PortForward{
+				Metadata: apievents.Metadata{
+					Type: events.PortForwardEvent,
+					Code: events.PortForwardCode,
+				},
+				UserMetadata: ctx.eventUserMeta(),
+				ConnectionMetadata: apievents.ConnectionMetadata{
+					LocalAddr:  sess.kubeAddress,
+					RemoteAddr: req.RemoteAddr,
+					Protocol:   events.EventProtocolKube,
+				},
+				Addr: addr,
+				Status: apievents.Status{
+					Success: true,
+					UserMessage: fmt.Sprintf("%v bytes sent, %v bytes received over %v",
+						txBytes, rxBytes, d.Round(time.Millisecond)),
+				},
+			}
+			if err := f.cfg.StreamEmitter.EmitAuditEvent(f.ctx, portForward); err != nil {
+				f.log.WithError(err).Warn("Failed to emit event.")
+			}
+		}
+	}
+
+	onPortForwardEnd := func(counts portForwardStreamCounts) {
+		if sess.noAuditEvents {
+			return
+		}
+		portForward := &apievents.PortForward{
+			Metadata: apievents.Metadata{
+				Type: events.PortForwardEvent,
+				Code: events.PortForwardCode,
+			},
+			UserMetadata: ctx.eventUserMeta(),
+			ConnectionMetadata: apievents.ConnectionMetadata{
+				LocalAddr:  sess.kubeAddress,
+				RemoteAddr: req.RemoteAddr,
+				Protocol:   events.EventProtocolKube,
+			},
+			Status: apievents.Status{
+				Success: true,
+				UserMessage: fmt.Sprintf("port forwarding session ended: %d streams total, %d peak concurrent, %d rejected",
+					counts.total, counts.peak, counts.rejected),
+			},
+		}
+		if err := f.cfg.StreamEmitter.EmitAuditEvent(f.ctx, portForward); err != nil {
+			f.log.WithError(err).Warn("Failed to emit event.")
+		}
+	}
+
 	q := req.URL.Query()
 	request := portForwardRequest{
 		podNamespace:       p.ByName("podNamespace"),
@@ -1611,8 +1771,11 @@ func (f *Forwarder) portForward(ctx *authContext, w http.ResponseWriter, req *ht
 		httpRequest:        req,
 		httpResponseWriter: w,
 		onPortForward:      onPortForward,
+		onPortForwardData:  onPortForwardData,
+		onPortForwardEnd:   onPortForwardEnd,
 		targetDialer:       dialer,
 		pingPeriod:         f.cfg.ConnPingPeriod,
+		maxStreams:         f.cfg.PortForwardMaxStreams,
 	}
 	f.log.Debugf("Starting %v.", request)
 	err = runPortForwarding(request)
@@ -1647,10 +1810,24 @@ const (
 )
 
 func (f *Forwarder) setupForwardingHeaders(sess *clusterSession, req *http.Request) error {
-	if err := setupImpersonationHeaders(f.log, sess.authContext, req.Header); err != nil {
+	if sess.identityHeaders != nil {
+		// the cluster is configured for identity header passthrough, forward
+		// the identity in the configured headers instead of impersonating
+		if err := sess.identityHeaders.setHeaders(sess.authContext, req.Header); err != nil {
+			return trace.Wrap(err)
+		}
+	} else if err := setupImpersonationHeaders(f.log, sess.authContext, req.Header); err != nil {
 		return trace.Wrap(err)
 	}
 
+	if sess.forwardRewrite != nil {
+		// Inject the cluster's static headers and base path. This runs after
+		// the impersonation setup so validated Impersonate-* headers are
+		// never overridden, parseForwardRewrite additionally rejects them at
+		// configuration time.
+		sess.forwardRewrite.apply(req)
+	}
+
 	// Setup scheme, override target URL to the destination address
 	req.URL.Scheme = "https"
 	req.RequestURI = req.URL.Path + "?" + req.URL.RawQuery
@@ -1842,6 +2019,7 @@ func (f *Forwarder) getExecutor(ctx authContext, sess *clusterSession, req *http
 		tlsConfig:       sess.tlsConfig,
 		pingPeriod:      f.cfg.ConnPingPeriod,
 		originalHeaders: req.Header,
+		identityHeaders: sess.identityHeaders,
 	})
 	rt := http.RoundTripper(upgradeRoundTripper)
 	if sess.creds != nil {
@@ -1862,6 +2040,7 @@ func (f *Forwarder) getDialer(ctx authContext, sess *clusterSession, req *http.R
 		tlsConfig:       sess.tlsConfig,
 		pingPeriod:      f.cfg.ConnPingPeriod,
 		originalHeaders: req.Header,
+		identityHeaders: sess.identityHeaders,
 	})
 	rt := http.RoundTripper(upgradeRoundTripper)
 	if sess.creds != nil {
@@ -1898,6 +2077,12 @@ type clusterSession struct {
 	upgradeToHTTP2 bool
 	// monitorCancel is the conn monitor monitorCancel function.
 	monitorCancel context.CancelFunc
+	// identityHeaders, if set, replaces Impersonate-* headers with the
+	// cluster's configured identity headers. See identityHeaderLabelPrefix.
+	identityHeaders *identityHeaders
+	// forwardRewrite, if set, injects the cluster's static headers and
+	// rewrites the base path of forwarded requests.
+	forwardRewrite *forwardRewrite
 }
 
 // close cancels the connection monitor context if available.
@@ -2069,6 +2254,10 @@ func (f *Forwarder) newClusterSessionLocal(ctx authContext) (*clusterSession, er
 		return nil, trace.NotFound("kubernetes cluster %q not found", ctx.kubeClusterName)
 	}
 
+	if err := details.checkCredentialHealth(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	f.log.Debugf("Handling kubernetes session for %v using local credentials.", ctx)
 	return &clusterSession{
 		parent:               f,
@@ -2076,6 +2265,8 @@ func (f *Forwarder) newClusterSessionLocal(ctx authContext) (*clusterSession, er
 		creds:                details.kubeCreds,
 		kubeClusterEndpoints: []kubeClusterEndpoint{{addr: details.getTargetAddr()}},
 		tlsConfig:            details.getTLSConfig().Clone(),
+		identityHeaders:      details.identityHeaders,
+		forwardRewrite:       details.forwardRewrite,
 	}, nil
 }
 
@@ -2128,6 +2319,23 @@ func (f *Forwarder) makeSessionForwarder(sess *clusterSession) (*forward.Forward
 		}
 	}
 
+	if f.cfg.DebugHeaders {
+		rt = &debugHeadersRoundTripper{
+			inner:    rt,
+			clock:    f.cfg.Clock,
+			endpoint: sess.kubeAddress,
+			serverID: f.cfg.HostID,
+			cluster:  f.cfg.ClusterName,
+		}
+	}
+
+	rt = &throttleRetryRoundTripper{
+		inner:     rt,
+		clock:     f.cfg.Clock,
+		log:       f.log,
+		retryGets: f.cfg.RetryThrottledGets,
+	}
+
 	forwarder, err := forward.New(
 		forward.FlushInterval(100*time.Millisecond),
 		forward.RoundTripper(rt),
@@ -2562,68 +2770,88 @@ func (f *Forwarder) handleDeleteCollectionReq(req *http.Request, authCtx *authCo
 	if err != nil {
 		return internalErrStatus, trace.Wrap(err)
 	}
+	// For each item, we compute the kubernetes_groups and kubernetes_users
+	// that are applicable and we will forward them as the delete request.
+	// If request is a dry-run.
+	// TODO (tigrato):
+	//  - parallelize loop
+	//  -  check if the request should stop at the first fail.
 	switch o := obj.(type) {
 	case *metav1.Status:
 		// Do nothing.
 	case *corev1.PodList:
 		// At this point, items already include the list of pods the filtered pods the
 		// user has access to.
-		// For each Pod, we compute the kubernetes_groups and kubernetes_labels
-		// that are applicable and we will forward them as the delete request.
-		// If request is a dry-run.
-		// TODO (tigrato):
-		//  - parallelize loop
-		//  -  check if the request should stop at the first fail.
 		items := make([]corev1.Pod, 0, len(o.Items))
 		for _, item := range o.Items {
-			// Compute users and groups from available roles that match the
-			// cluster labels and kubernetes resources.
-			allowedKubeGroups, allowedKubeUsers, err := authCtx.Checker.CheckKubeGroupsAndUsers(
-				authCtx.sessionTTL,
-				false,
-				services.NewKubernetesClusterLabelMatcher(authCtx.kubeClusterLabels),
-				services.NewKubernetesResourceMatcher(
-					types.KubernetesResource{
-						Kind:      types.KindKubePod,
-						Name:      item.Name,
-						Namespace: item.Namespace,
-					},
-				),
+			deleted, err := f.deleteCollectionItem(req, authCtx, details,
+				types.KubernetesResource{Kind: types.KindKubePod, Name: item.Name, Namespace: item.Namespace},
+				func(client *kubernetes.Clientset) error {
+					return client.CoreV1().Pods(item.Namespace).Delete(req.Context(), item.Name, deleteOptions)
+				},
 			)
-			// no match was found, we ignore the request.
 			if err != nil {
-				continue
+				return internalErrStatus, trace.Wrap(err)
 			}
-			allowedKubeUsers, allowedKubeGroups = fillDefaultKubePrincipalDetails(allowedKubeUsers, allowedKubeGroups, authCtx.User.GetName())
-
-			impersonatedUsers, impersonatedGroups, err := computeImpersonatedPrincipals(
-				f.log, utils.StringsSet(allowedKubeUsers), utils.StringsSet(allowedKubeGroups),
-				req.Header,
+			if deleted {
+				items = append(items, item)
+			}
+		}
+		// reset items.
+		o.Items = items
+	case *corev1.SecretList:
+		items := make([]corev1.Secret, 0, len(o.Items))
+		for _, item := range o.Items {
+			deleted, err := f.deleteCollectionItem(req, authCtx, details,
+				types.KubernetesResource{Kind: kindKubeSecret, Name: item.Name, Namespace: item.Namespace},
+				func(client *kubernetes.Clientset) error {
+					return client.CoreV1().Secrets(item.Namespace).Delete(req.Context(), item.Name, deleteOptions)
+				},
 			)
 			if err != nil {
-				continue
+				return internalErrStatus, trace.Wrap(err)
 			}
-
-			// create a new kubernetes.Client using the impersonated users and groups
-			// that matched the current pod.
-			client, err := newImpersonatedKubeClient(details.kubeCreds, impersonatedUsers, impersonatedGroups)
+			if deleted {
+				items = append(items, item)
+			}
+		}
+		o.Items = items
+	case *appsv1.DeploymentList:
+		items := make([]appsv1.Deployment, 0, len(o.Items))
+		for _, item := range o.Items {
+			deleted, err := f.deleteCollectionItem(req, authCtx, details,
+				types.KubernetesResource{Kind: kindKubeDeployment, Name: item.Name, Namespace: item.Namespace},
+				func(client *kubernetes.Clientset) error {
+					return client.AppsV1().Deployments(item.Namespace).Delete(req.Context(), item.Name, deleteOptions)
+				},
+			)
 			if err != nil {
 				return internalErrStatus, trace.Wrap(err)
 			}
-			// delete each pod individually.
-			err = client.CoreV1().Pods(item.Namespace).Delete(req.Context(), item.Name, deleteOptions)
+			if deleted {
+				items = append(items, item)
+			}
+		}
+		o.Items = items
+	case *batchv1.JobList:
+		items := make([]batchv1.Job, 0, len(o.Items))
+		for _, item := range o.Items {
+			deleted, err := f.deleteCollectionItem(req, authCtx, details,
+				types.KubernetesResource{Kind: kindKubeJob, Name: item.Name, Namespace: item.Namespace},
+				func(client *kubernetes.Clientset) error {
+					return client.BatchV1().Jobs(item.Namespace).Delete(req.Context(), item.Name, deleteOptions)
+				},
+			)
 			if err != nil {
-				// TODO(tigrato): check what should we do when delete returns an error.
-				// Should we check if it's permission error?
-				// Check if the Pod has already been deleted by a concurrent request
-				continue
+				return internalErrStatus, trace.Wrap(err)
+			}
+			if deleted {
+				items = append(items, item)
 			}
-			items = append(items, item)
 		}
-		// reset items.
 		o.Items = items
 	default:
-		return internalErrStatus, trace.BadParameter("expected *corev1.PodList, got: %T", obj)
+		return internalErrStatus, trace.BadParameter("unsupported list type for delete collection: %T", obj)
 	}
 	// reset the memory buffer.
 	memWriter.Buffer().Reset()
@@ -2742,7 +2970,7 @@ func errorToKubeStatusReason(err error) metav1.StatusReason {
 // If the response is not compressed, it does nothing.
 func decompressInplace(memoryRW *responsewriters.MemoryResponseWriter) error {
 	switch memoryRW.Header().Get(contentEncodingHeader) {
-	case contentEncodingGZIP:
+	case contentEncodingGZIP, contentEncodingZSTD:
 		_, decompressor, err := getResponseCompressorDecompressor(memoryRW.Header())
 		if err != nil {
 			return trace.Wrap(err)
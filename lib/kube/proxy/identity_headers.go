@@ -0,0 +1,128 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+// identityHeaderLabelPrefix is the kube_cluster static label prefix that
+// configures identity header passthrough for a cluster. The label key suffix
+// names the header to set and the label value is a Go template rendered with
+// identityHeaderData, e.g.:
+//
+//	identity-headers.teleport.dev/X-Remote-User: "{{.User}}"
+//	identity-headers.teleport.dev/X-Remote-Groups: "{{join .KubeGroups \",\"}}"
+//
+// When a cluster has at least one such label, the forwarder sends the
+// rendered headers to the cluster instead of the Impersonate-* headers. This
+// supports clusters fronted by OIDC/webhook authenticators that expect the
+// original user identity in custom headers rather than impersonation.
+const identityHeaderLabelPrefix = "identity-headers.teleport.dev/"
+
+// identityHeaderData is the data available to identity header templates.
+type identityHeaderData struct {
+	// User is the Teleport username.
+	User string
+	// Groups are the Teleport roles of the user.
+	Groups []string
+	// KubeUsers are the Kubernetes users the identity resolves to, sorted.
+	KubeUsers []string
+	// KubeGroups are the Kubernetes groups the identity resolves to, sorted.
+	KubeGroups []string
+}
+
+// identityHeaders holds the compiled per-cluster identity header templates.
+type identityHeaders struct {
+	templates map[string]*template.Template
+}
+
+// parseIdentityHeaders compiles the identity header templates configured on
+// the cluster's static labels. It returns nil when the cluster has no such
+// labels, in which case regular impersonation headers are used.
+func parseIdentityHeaders(cluster types.KubeCluster) (*identityHeaders, error) {
+	var templates map[string]*template.Template
+	for key, value := range cluster.GetStaticLabels() {
+		if !strings.HasPrefix(key, identityHeaderLabelPrefix) {
+			continue
+		}
+		header := strings.TrimPrefix(key, identityHeaderLabelPrefix)
+		if header == "" {
+			return nil, trace.BadParameter("identity header label %q of cluster %q is missing a header name", key, cluster.GetName())
+		}
+		if strings.HasPrefix(http.CanonicalHeaderKey(header), ImpersonateHeaderPrefix) {
+			return nil, trace.BadParameter("identity header %q of cluster %q must not use the %v prefix", header, cluster.GetName(), ImpersonateHeaderPrefix)
+		}
+		tmpl, err := template.New(header).Funcs(template.FuncMap{
+			"join": strings.Join,
+		}).Parse(value)
+		if err != nil {
+			return nil, trace.BadParameter("failed to parse identity header template %q of cluster %q: %v", key, cluster.GetName(), err)
+		}
+		if templates == nil {
+			templates = make(map[string]*template.Template)
+		}
+		templates[header] = tmpl
+	}
+	if templates == nil {
+		return nil, nil
+	}
+	return &identityHeaders{templates: templates}, nil
+}
+
+// setHeaders renders the configured identity headers for the given identity
+// and sets them on the request, removing any client-supplied values for the
+// same headers as well as all Impersonate-* headers so clients cannot spoof
+// either mechanism.
+func (h *identityHeaders) setHeaders(ctx authContext, headers http.Header) error {
+	data := identityHeaderData{
+		User:       ctx.Context.User.GetName(),
+		Groups:     ctx.Context.Identity.GetIdentity().Groups,
+		KubeUsers:  sortedKeys(ctx.kubeUsers),
+		KubeGroups: sortedKeys(ctx.kubeGroups),
+	}
+
+	for header := range headers {
+		if strings.HasPrefix(header, ImpersonateHeaderPrefix) {
+			headers.Del(header)
+		}
+	}
+
+	for header, tmpl := range h.templates {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return trace.Wrap(err, "failed to render identity header %q", header)
+		}
+		headers.Set(header, buf.String())
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/tlsca"
+)
+
+func TestParseIdentityHeaders(t *testing.T) {
+	t.Parallel()
+
+	newCluster := func(t *testing.T, labels map[string]string) types.KubeCluster {
+		cluster, err := types.NewKubernetesClusterV3(types.Metadata{
+			Name:   "test",
+			Labels: labels,
+		}, types.KubernetesClusterSpecV3{})
+		require.NoError(t, err)
+		return cluster
+	}
+
+	t.Run("no labels", func(t *testing.T) {
+		headers, err := parseIdentityHeaders(newCluster(t, map[string]string{"env": "prod"}))
+		require.NoError(t, err)
+		require.Nil(t, headers)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := parseIdentityHeaders(newCluster(t, map[string]string{
+			identityHeaderLabelPrefix + "X-Remote-User": "{{.User",
+		}))
+		require.Error(t, err)
+	})
+
+	t.Run("impersonation header rejected", func(t *testing.T) {
+		_, err := parseIdentityHeaders(newCluster(t, map[string]string{
+			identityHeaderLabelPrefix + "impersonate-user": "{{.User}}",
+		}))
+		require.Error(t, err)
+	})
+
+	t.Run("renders identity", func(t *testing.T) {
+		headers, err := parseIdentityHeaders(newCluster(t, map[string]string{
+			identityHeaderLabelPrefix + "X-Remote-User":   "{{.User}}",
+			identityHeaderLabelPrefix + "X-Remote-Groups": `{{join .KubeGroups ","}}`,
+		}))
+		require.NoError(t, err)
+		require.NotNil(t, headers)
+
+		user, err := types.NewUser("bob")
+		require.NoError(t, err)
+		ctx := authContext{
+			Context: auth.Context{
+				User:     user,
+				Identity: auth.WrapIdentity(tlsca.Identity{Username: "bob", Groups: []string{"access"}}),
+			},
+			kubeGroups: map[string]struct{}{"viewers": {}, "admins": {}},
+		}
+
+		req := http.Header{
+			"Impersonate-User":  []string{"spoofed"},
+			"Impersonate-Group": []string{"spoofed-group"},
+		}
+		require.NoError(t, headers.setHeaders(ctx, req))
+		require.Equal(t, "bob", req.Get("X-Remote-User"))
+		require.Equal(t, "admins,viewers", req.Get("X-Remote-Groups"))
+		require.Empty(t, req.Values(ImpersonateUserHeader))
+		require.Empty(t, req.Values(ImpersonateGroupHeader))
+	})
+}
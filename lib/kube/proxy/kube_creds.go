@@ -39,6 +39,12 @@ type kubeCreds interface {
 	getKubeRestConfig() *rest.Config
 	getKubeClient() *kubernetes.Clientset
 	wrapTransport(http.RoundTripper) (http.RoundTripper, error)
+	// credentialType describes the kind of credential backing the cluster,
+	// e.g. "kubeconfig exec plugin", for health checks and error messages.
+	credentialType() string
+	// validUntil returns when the credentials expire. A zero time means no
+	// known expiry.
+	validUntil() time.Time
 	close() error
 }
 
@@ -62,6 +68,12 @@ type staticKubeCreds struct {
 	kubeClient *kubernetes.Clientset
 	// clientRestCfg is the Kubernetes Rest config for the cluster.
 	clientRestCfg *rest.Config
+	// credType describes the kind of credential in the kubeconfig entry.
+	credType string
+	// expiry is when the credentials stop being valid. Zero when the
+	// kubeconfig entry carries no parseable expiry (e.g. bearer tokens), or
+	// when the credentials are refreshed on use (exec plugins).
+	expiry time.Time
 }
 
 func (s *staticKubeCreds) getTLSConfig() *tls.Config {
@@ -91,6 +103,14 @@ func (s *staticKubeCreds) wrapTransport(rt http.RoundTripper) (http.RoundTripper
 	return transport.HTTPWrappersForConfig(s.transportConfig, rt)
 }
 
+func (s *staticKubeCreds) credentialType() string {
+	return s.credType
+}
+
+func (s *staticKubeCreds) validUntil() time.Time {
+	return s.expiry
+}
+
 func (s *staticKubeCreds) close() error {
 	return nil
 }
@@ -110,6 +130,8 @@ type dynamicKubeCreds struct {
 	closeC      chan struct{}
 	client      dynamicCredsClient
 	checker     ImpersonationPermissionsChecker
+	// expiry is when the last generated credentials stop being valid.
+	expiry time.Time
 	sync.RWMutex
 }
 
@@ -181,6 +203,16 @@ func (d *dynamicKubeCreds) wrapTransport(rt http.RoundTripper) (http.RoundTrippe
 	return d.staticCreds.wrapTransport(rt)
 }
 
+func (d *dynamicKubeCreds) credentialType() string {
+	return "short-lived cloud token"
+}
+
+func (d *dynamicKubeCreds) validUntil() time.Time {
+	d.RLock()
+	defer d.RUnlock()
+	return d.expiry
+}
+
 func (d *dynamicKubeCreds) close() error {
 	close(d.closeC)
 	return nil
@@ -201,6 +233,7 @@ func (d *dynamicKubeCreds) renewClientset(cluster types.KubeCluster) error {
 	d.Lock()
 	defer d.Unlock()
 	d.staticCreds = creds
+	d.expiry = exp
 	// prepares the next renew cycle
 	if !exp.IsZero() {
 		d.renewTicker.Reset(time.Until(exp) / 2)
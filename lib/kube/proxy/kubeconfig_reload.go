@@ -0,0 +1,115 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/exp/maps"
+)
+
+// kubeconfigReloadLoop periodically re-reads the kubeconfig file and
+// reconciles the proxied clusters with its contexts, so contexts added or
+// removed on disk are picked up without a restart.
+func (f *Forwarder) kubeconfigReloadLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := f.reloadKubeconfigClusters(ctx); err != nil {
+				f.log.WithError(err).Warn("Failed to reload kubeconfig clusters.")
+			}
+		case <-ctx.Done():
+			f.log.Debug("Kubeconfig reloader done.")
+			return
+		}
+	}
+}
+
+// reloadKubeconfigClusters re-reads the kubeconfig and registers contexts
+// that appeared and unregisters kubeconfig-sourced contexts that vanished.
+// Credentials of contexts that are still present are left untouched so
+// in-flight requests against them are not disrupted; sessions against
+// removed clusters are closed.
+func (f *Forwarder) reloadKubeconfigClusters(ctx context.Context) error {
+	details, err := getKubeDetails(ctx, f.log, f.cfg.ClusterName, f.cfg.KubeClusterName, f.cfg.KubeconfigPath, f.cfg.KubeServiceType, f.cfg.CheckImpersonationPermissions)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	added, removed := f.reconcileKubeconfigDetails(details)
+	for _, name := range added {
+		f.log.Infof("Registered kubeconfig cluster %q.", name)
+	}
+
+	var errs []error
+	for _, name := range removed {
+		f.log.Infof("Unregistered kubeconfig cluster %q.", name)
+		errs = append(errs, f.closeSessionsForCluster(name))
+	}
+	return trace.NewAggregate(errs...)
+}
+
+// reconcileKubeconfigDetails updates f.clusterDetails against the freshly
+// loaded kubeconfig contexts and returns the names of the clusters that were
+// added and removed. Clusters registered from other sources (e.g. dynamic
+// kube_cluster resources) are not touched.
+func (f *Forwarder) reconcileKubeconfigDetails(details map[string]*kubeDetails) (added, removed []string) {
+	f.rwMutexDetails.Lock()
+	defer f.rwMutexDetails.Unlock()
+
+	for name, detail := range details {
+		if _, ok := f.clusterDetails[name]; ok {
+			// Keep the live credentials of known clusters untouched.
+			detail.Close()
+			continue
+		}
+		f.clusterDetails[name] = detail
+		f.kubeconfigClusters[name] = struct{}{}
+		added = append(added, name)
+	}
+
+	for name := range f.kubeconfigClusters {
+		if _, ok := details[name]; ok {
+			continue
+		}
+		if oldDetails, ok := f.clusterDetails[name]; ok {
+			oldDetails.Close()
+			delete(f.clusterDetails, name)
+		}
+		delete(f.kubeconfigClusters, name)
+		removed = append(removed, name)
+	}
+	return added, removed
+}
+
+// closeSessionsForCluster closes all in-flight sessions targeting the given
+// kube cluster.
+func (f *Forwarder) closeSessionsForCluster(name string) error {
+	f.mu.Lock()
+	sessions := maps.Values(f.sessions)
+	f.mu.Unlock()
+
+	var errs []error
+	for _, sess := range sessions {
+		if sess.ctx.kubeClusterName == name {
+			errs = append(errs, sess.Close())
+		}
+	}
+	return trace.NewAggregate(errs...)
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileKubeconfigDetails(t *testing.T) {
+	t.Parallel()
+
+	newDetails := func() *kubeDetails {
+		return &kubeDetails{kubeCreds: &staticKubeCreds{}}
+	}
+
+	f := &Forwarder{
+		log: log.StandardLogger(),
+		clusterDetails: map[string]*kubeDetails{
+			"kubeconfig-a": newDetails(),
+			"kubeconfig-b": newDetails(),
+			"dynamic":      newDetails(),
+		},
+		kubeconfigClusters: map[string]struct{}{
+			"kubeconfig-a": {},
+			"kubeconfig-b": {},
+		},
+	}
+
+	// kubeconfig-b was removed from disk, kubeconfig-c appeared.
+	added, removed := f.reconcileKubeconfigDetails(map[string]*kubeDetails{
+		"kubeconfig-a": newDetails(),
+		"kubeconfig-c": newDetails(),
+	})
+	require.Equal(t, []string{"kubeconfig-c"}, added)
+	require.Equal(t, []string{"kubeconfig-b"}, removed)
+
+	require.Contains(t, f.clusterDetails, "kubeconfig-a")
+	require.Contains(t, f.clusterDetails, "kubeconfig-c")
+	require.NotContains(t, f.clusterDetails, "kubeconfig-b")
+	// Clusters from other sources are not touched.
+	require.Contains(t, f.clusterDetails, "dynamic")
+	require.NotContains(t, f.kubeconfigClusters, "dynamic")
+
+	// A second reconciliation against the same contexts is a no-op.
+	added, removed = f.reconcileKubeconfigDetails(map[string]*kubeDetails{
+		"kubeconfig-a": newDetails(),
+		"kubeconfig-c": newDetails(),
+	})
+	require.Empty(t, added)
+	require.Empty(t, removed)
+}
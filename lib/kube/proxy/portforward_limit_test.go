@@ -0,0 +1,61 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"testing"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortForwardStreamLimit(t *testing.T) {
+	t.Parallel()
+
+	h := &portForwardProxy{
+		Entry:              log.NewEntry(log.StandardLogger()),
+		portForwardRequest: portForwardRequest{maxStreams: 2},
+		streamPairs:        make(map[string]*httpStreamPair),
+	}
+
+	// The first two pairs fit within the limit.
+	_, created, err := h.getStreamPair("1")
+	require.NoError(t, err)
+	require.True(t, created)
+	_, created, err = h.getStreamPair("2")
+	require.NoError(t, err)
+	require.True(t, created)
+
+	// Looking up an existing pair does not count against the limit.
+	_, created, err = h.getStreamPair("1")
+	require.NoError(t, err)
+	require.False(t, created)
+
+	// A third pair exceeds the limit.
+	_, _, err = h.getStreamPair("3")
+	require.True(t, trace.IsLimitExceeded(err))
+
+	// Removing a pair frees up room for a new one.
+	h.removeStreamPair("1")
+	_, created, err = h.getStreamPair("3")
+	require.NoError(t, err)
+	require.True(t, created)
+
+	counts := h.countsSnapshot()
+	require.Equal(t, uint32(3), counts.total)
+	require.Equal(t, uint32(2), counts.peak)
+	require.Equal(t, uint32(1), counts.rejected)
+}
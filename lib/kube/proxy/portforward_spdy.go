@@ -24,6 +24,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gravitational/trace"
@@ -43,9 +44,14 @@ type portForwardRequest struct {
 	httpRequest        *http.Request
 	httpResponseWriter http.ResponseWriter
 	onPortForward      portForwardCallback
+	onPortForwardData  portForwardDataCallback
+	onPortForwardEnd   portForwardEndCallback
 	context            context.Context
 	targetDialer       httpstream.Dialer
 	pingPeriod         time.Duration
+	// maxStreams caps the number of concurrent stream pairs a single port
+	// forwarding session may have open. Zero means no limit.
+	maxStreams int
 }
 
 func (p portForwardRequest) String() string {
@@ -55,6 +61,27 @@ func (p portForwardRequest) String() string {
 // portForwardCallback is a callback to be called on every port forward request
 type portForwardCallback func(addr string, success bool)
 
+// portForwardDataCallback is an optional callback called when a port forward
+// data stream completes, carrying the bytes copied in each direction and the
+// stream duration. Payload contents are never captured.
+type portForwardDataCallback func(addr string, txBytes, rxBytes uint64, d time.Duration)
+
+// portForwardStreamCounts summarizes the stream activity of a single port
+// forwarding session.
+type portForwardStreamCounts struct {
+	// total is the number of stream pairs opened over the session's lifetime.
+	total uint32
+	// peak is the highest number of concurrently open stream pairs.
+	peak uint32
+	// rejected is the number of stream pairs rejected by the per-session
+	// stream limit.
+	rejected uint32
+}
+
+// portForwardEndCallback is an optional callback called when a port forwarding
+// session completes, carrying its stream counts.
+type portForwardEndCallback func(counts portForwardStreamCounts)
+
 // parsePortString parses a port from a given string.
 func parsePortString(pString string) (uint16, error) {
 	port, err := strconv.ParseUint(pString, 10, 16)
@@ -106,6 +133,9 @@ func runPortForwardingHTTPStreams(req portForwardRequest) error {
 	h.Debugf("Setting port forwarding streaming connection idle timeout to %v", IdleTimeout)
 	conn.SetIdleTimeout(IdleTimeout)
 	h.run()
+	if req.onPortForwardEnd != nil {
+		req.onPortForwardEnd(h.countsSnapshot())
+	}
 	return nil
 }
 
@@ -153,6 +183,7 @@ type portForwardProxy struct {
 	streamChan            chan httpstream.Stream
 	streamPairsLock       sync.RWMutex
 	streamPairs           map[string]*httpStreamPair
+	streamCounts          portForwardStreamCounts
 	streamCreationTimeout time.Duration
 
 	targetConn httpstream.Connection
@@ -208,11 +239,22 @@ func (h *portForwardProxy) forwardStreamPair(p *httpStreamPair, remotePort int64
 	localError := make(chan struct{})
 	remoteDone := make(chan struct{})
 
+	var txBytes, rxBytes uint64
+	start := time.Now()
+	if h.onPortForwardData != nil {
+		defer func() {
+			h.onPortForwardData(fmt.Sprintf("%v:%v", h.podName, remotePort),
+				atomic.LoadUint64(&txBytes), atomic.LoadUint64(&rxBytes), time.Since(start))
+		}()
+	}
+
 	go func() {
 		// inform the select below that the remote copy is done
 		defer close(remoteDone)
 		// Copy from the remote side to the local port.
-		if _, err := io.Copy(p.dataStream, dataStream); err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
+		n, err := io.Copy(p.dataStream, dataStream)
+		atomic.AddUint64(&rxBytes, uint64(n))
+		if err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
 			log.Error(fmt.Errorf("error copying from remote stream to local connection: %v", err))
 		}
 	}()
@@ -222,7 +264,9 @@ func (h *portForwardProxy) forwardStreamPair(p *httpStreamPair, remotePort int64
 		defer dataStream.Close()
 
 		// Copy from the local port to the target side.
-		if _, err := io.Copy(dataStream, p.dataStream); err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
+		n, err := io.Copy(dataStream, p.dataStream)
+		atomic.AddUint64(&txBytes, uint64(n))
+		if err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
 			h.Warningf("Error copying from local connection to remote stream: %v.", err)
 			// break out of the select below without waiting for the other copy to finish
 			close(localError)
@@ -251,22 +295,39 @@ func (h *portForwardProxy) forwardStreamPair(p *httpStreamPair, remotePort int64
 
 // getStreamPair returns a httpStreamPair for requestID. This creates a
 // new pair if one does not yet exist for the requestID. The returned bool is
-// true if the pair was created.
-func (h *portForwardProxy) getStreamPair(requestID string) (*httpStreamPair, bool) {
+// true if the pair was created. It returns an error if creating a new pair
+// would exceed the session's concurrent stream limit.
+func (h *portForwardProxy) getStreamPair(requestID string) (*httpStreamPair, bool, error) {
 	h.streamPairsLock.Lock()
 	defer h.streamPairsLock.Unlock()
 
 	if p, ok := h.streamPairs[requestID]; ok {
 		log.Debugf("Request %s, found existing stream pair", requestID)
-		return p, false
+		return p, false, nil
+	}
+
+	if h.maxStreams > 0 && len(h.streamPairs) >= h.maxStreams {
+		h.streamCounts.rejected++
+		return nil, false, trace.LimitExceeded("too many concurrent port forwarding streams: session limit is %d", h.maxStreams)
 	}
 
 	h.Debugf("Request %s, creating new stream pair.", requestID)
 
 	p := newPortForwardPair(requestID)
 	h.streamPairs[requestID] = p
+	h.streamCounts.total++
+	if concurrent := uint32(len(h.streamPairs)); concurrent > h.streamCounts.peak {
+		h.streamCounts.peak = concurrent
+	}
+
+	return p, true, nil
+}
 
-	return p, true
+// countsSnapshot returns a copy of the session's stream counts.
+func (h *portForwardProxy) countsSnapshot() portForwardStreamCounts {
+	h.streamPairsLock.RLock()
+	defer h.streamPairsLock.RUnlock()
+	return h.streamCounts
 }
 
 // monitorStreamPair waits for the pair to receive both its error and data
@@ -321,7 +382,13 @@ func (h *portForwardProxy) run() {
 			streamType := stream.Headers().Get(StreamType)
 			h.Debugf("Received new stream %v of type %v.", requestID, streamType)
 
-			p, created := h.getStreamPair(requestID)
+			p, created, err := h.getStreamPair(requestID)
+			if err != nil {
+				h.Warningf("Request %s, rejecting stream: %v.", requestID, err)
+				fmt.Fprint(stream, err.Error())
+				stream.Close()
+				continue
+			}
 			if created {
 				go h.monitorStreamPair(p, time.After(h.streamCreationTimeout))
 			}
@@ -24,6 +24,8 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gravitational/trace"
 	"github.com/sirupsen/logrus"
@@ -57,6 +59,12 @@ func runPortForwardingWebSocket(req portForwardRequest) error {
 		return trace.Wrap(err)
 	}
 
+	// WebSocket streams are fixed at upgrade time, one pair per port, so the
+	// per-session stream limit can be enforced before upgrading.
+	if req.maxStreams > 0 && len(ports) > req.maxStreams {
+		return trace.LimitExceeded("requested %d port forwarding streams, session limit is %d", len(ports), req.maxStreams)
+	}
+
 	// When dialing to the upstream server (Teleport or Kubernetes API server),
 	// Teleport uses the SPDY implementation instead of WebSockets.
 	targetConn, _, err := req.targetDialer.Dial(PortForwardProtocolV1Name)
@@ -136,11 +144,12 @@ func runPortForwardingWebSocket(req portForwardRequest) error {
 	}
 
 	h := &websocketPortforwardHandler{
-		conn:          conn,
-		streamPairs:   streamPairs,
-		podName:       req.podName,
-		targetConn:    targetConn,
-		onPortForward: req.onPortForward,
+		conn:              conn,
+		streamPairs:       streamPairs,
+		podName:           req.podName,
+		targetConn:        targetConn,
+		onPortForward:     req.onPortForward,
+		onPortForwardData: req.onPortForwardData,
 		FieldLogger: logrus.WithFields(logrus.Fields{
 			trace.Component:   teleport.Component(teleport.ComponentProxyKube),
 			events.RemoteAddr: req.httpRequest.RemoteAddr,
@@ -149,6 +158,10 @@ func runPortForwardingWebSocket(req portForwardRequest) error {
 	}
 	// run the portforward request until termination.
 	h.run()
+	if req.onPortForwardEnd != nil {
+		n := uint32(len(ports))
+		req.onPortForwardEnd(portForwardStreamCounts{total: n, peak: n})
+	}
 	return nil
 }
 
@@ -201,11 +214,12 @@ func (w *websocketChannelPair) sendErr(err error) {
 // websocketPortforwardHandler is capable of processing a single port forward
 // request over a websocket connection
 type websocketPortforwardHandler struct {
-	conn          *wsstream.Conn
-	streamPairs   []*websocketChannelPair
-	podName       string
-	targetConn    httpstream.Connection
-	onPortForward portForwardCallback
+	conn              *wsstream.Conn
+	streamPairs       []*websocketChannelPair
+	podName           string
+	targetConn        httpstream.Connection
+	onPortForward     portForwardCallback
+	onPortForwardData portForwardDataCallback
 	logrus.FieldLogger
 	context context.Context
 }
@@ -273,10 +287,18 @@ func (h *websocketPortforwardHandler) forwardStreamPair(p *websocketChannelPair)
 	}
 	defer dataStream.Close()
 
+	clientStream := p.dataStream
+	var counter *countingReadWriteCloser
+	start := time.Now()
+	if h.onPortForwardData != nil {
+		counter = &countingReadWriteCloser{inner: p.dataStream}
+		clientStream = counter
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := utils.ProxyConn(h.context, p.dataStream, dataStream); err != nil {
+		if err := utils.ProxyConn(h.context, clientStream, dataStream); err != nil {
 			h.WithError(err).Debugf("Unable to proxy portforward data-stream.")
 		}
 	}()
@@ -285,5 +307,44 @@ func (h *websocketPortforwardHandler) forwardStreamPair(p *websocketChannelPair)
 	// Wait until every goroutine exits.
 	wg.Wait()
 
+	if counter != nil {
+		h.onPortForwardData(fmt.Sprintf("%v:%v", h.podName, p.port),
+			counter.readBytes(), counter.writtenBytes(), time.Since(start))
+	}
+
 	h.Infof("Port forwarding pair completed.")
 }
+
+// countingReadWriteCloser wraps a stream and counts the bytes passing
+// through it in each direction. Contents are never retained.
+type countingReadWriteCloser struct {
+	inner   io.ReadWriteCloser
+	read    uint64
+	written uint64
+}
+
+func (c *countingReadWriteCloser) Read(p []byte) (int, error) {
+	n, err := c.inner.Read(p)
+	atomic.AddUint64(&c.read, uint64(n))
+	return n, err
+}
+
+func (c *countingReadWriteCloser) Write(p []byte) (int, error) {
+	n, err := c.inner.Write(p)
+	atomic.AddUint64(&c.written, uint64(n))
+	return n, err
+}
+
+func (c *countingReadWriteCloser) Close() error {
+	return c.inner.Close()
+}
+
+// readBytes returns the number of bytes read from the wrapped stream.
+func (c *countingReadWriteCloser) readBytes() uint64 {
+	return atomic.LoadUint64(&c.read)
+}
+
+// writtenBytes returns the number of bytes written to the wrapped stream.
+func (c *countingReadWriteCloser) writtenBytes() uint64 {
+	return atomic.LoadUint64(&c.written)
+}
@@ -71,6 +71,9 @@ type SpdyRoundTripper struct {
 	pingPeriod time.Duration
 	// originalHeaders are the headers that were passed from the original request.
 	originalHeaders http.Header
+	// identityHeaders, if set, replaces Impersonate-* headers with the
+	// cluster's configured identity headers. See identityHeaderLabelPrefix.
+	identityHeaders *identityHeaders
 }
 
 var (
@@ -89,12 +92,13 @@ type roundTripperConfig struct {
 	tlsConfig       *tls.Config
 	pingPeriod      time.Duration
 	originalHeaders http.Header
+	identityHeaders *identityHeaders
 }
 
 // NewSpdyRoundTripperWithDialer creates a new SpdyRoundTripper that will use
 // the specified tlsConfig. This function is mostly meant for unit tests.
 func NewSpdyRoundTripperWithDialer(cfg roundTripperConfig) *SpdyRoundTripper {
-	return &SpdyRoundTripper{tlsConfig: cfg.tlsConfig, dialWithContext: cfg.dial, ctx: cfg.ctx, authCtx: cfg.authCtx, pingPeriod: cfg.pingPeriod, originalHeaders: cfg.originalHeaders}
+	return &SpdyRoundTripper{tlsConfig: cfg.tlsConfig, dialWithContext: cfg.dial, ctx: cfg.ctx, authCtx: cfg.authCtx, pingPeriod: cfg.pingPeriod, originalHeaders: cfg.originalHeaders, identityHeaders: cfg.identityHeaders}
 }
 
 // TLSClientConfig implements pkg/util/net.TLSClientConfigHolder for proper TLS checking during
@@ -163,7 +167,11 @@ func (s *SpdyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	copyImpersonationHeaders(header, s.originalHeaders)
 	header.Set(httpstream.HeaderConnection, httpstream.HeaderUpgrade)
 	header.Set(httpstream.HeaderUpgrade, streamspdy.HeaderSpdy31)
-	if err := setupImpersonationHeaders(log.StandardLogger(), s.authCtx, header); err != nil {
+	if s.identityHeaders != nil {
+		if err := s.identityHeaders.setHeaders(s.authCtx, header); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	} else if err := setupImpersonationHeaders(log.StandardLogger(), s.authCtx, header); err != nil {
 		return nil, trace.Wrap(err)
 	}
 
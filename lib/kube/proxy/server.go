@@ -89,6 +89,10 @@ type TLSServerConfig struct {
 	CloudLabels labels.Importer
 	// IngressReporter reports new and active connections.
 	IngressReporter *ingress.Reporter
+	// EnableProxyProtocol enables proxy protocol support on the listener, so
+	// connections arriving through a load balancer carry the real client
+	// address for authorization and audit purposes.
+	EnableProxyProtocol bool
 }
 
 // CheckAndSetDefaults checks and sets default values
@@ -215,7 +219,7 @@ func (t *TLSServer) Serve(listener net.Listener) error {
 		Context:                     t.Context,
 		Listener:                    listener,
 		Clock:                       t.Clock,
-		EnableExternalProxyProtocol: true,
+		EnableExternalProxyProtocol: t.EnableProxyProtocol,
 		ID:                          t.Component,
 		// Increases deadline until the agent receives the first byte to 10s.
 		// It's required to accommodate setups with high latency and where the time
@@ -380,6 +384,18 @@ func (t *TLSServer) getKubeClusterForHeartbeat(name string) (*types.KubernetesCl
 
 	t.setServiceLabels(clusterWithoutCreds)
 
+	// Surface the credential state so expired kubeconfig credentials are
+	// visible from heartbeats rather than only at request time.
+	staticLabels := clusterWithoutCreds.GetStaticLabels()
+	if staticLabels == nil {
+		staticLabels = make(map[string]string)
+	}
+	staticLabels[credentialHealthLabel] = details.credentialHealth()
+	// Likewise for the session recording upload queue, so a backlog on slow
+	// disks is alertable before recordings are lost with the pod.
+	staticLabels[uploadQueueHealthLabel] = t.fwd.uploadMonitor.health()
+	clusterWithoutCreds.SetStaticLabels(staticLabels)
+
 	return clusterWithoutCreds, nil
 }
 
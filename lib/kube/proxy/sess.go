@@ -540,6 +540,7 @@ func (s *session) launch() error {
 			ServerNamespace: s.forwarder.cfg.Namespace,
 			ServerHostname:  s.sess.teleportCluster.name,
 			ServerAddr:      s.sess.kubeAddress,
+			ServerLabels:    map[string]string{kubeSessionTypeLabel: kubeSessionType(isAttachRequest(s.req))},
 		},
 		SessionMetadata: apievents.SessionMetadata{
 			SessionID: s.id.String(),
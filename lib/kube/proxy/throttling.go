@@ -0,0 +1,143 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/gravitational/teleport"
+)
+
+const (
+	// maxThrottleRetries bounds client-transparent retries of throttled
+	// idempotent requests.
+	maxThrottleRetries = 2
+	// maxThrottleRetryWait caps how long a single upstream Retry-After hint
+	// is honored before retrying; longer hints fail through to the client.
+	maxThrottleRetryWait = 5 * time.Second
+	// defaultThrottleRetryWait is used when the upstream response carries no
+	// usable Retry-After header.
+	defaultThrottleRetryWait = time.Second
+)
+
+var kubeUpstreamThrottledRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: teleport.MetricNamespace,
+		Name:      "kube_upstream_throttled_requests_total",
+		Help:      "Number of responses from upstream Kubernetes API servers indicating throttling, partitioned by status code.",
+	},
+	[]string{"status_code"},
+)
+
+// throttleRetryRoundTripper wraps a session round tripper to surface
+// upstream API server throttling (429/503): every throttled response is
+// counted and logged, Retry-After headers are passed through to the client
+// untouched, and idempotent GETs are optionally retried a bounded number of
+// times before the throttled response is returned.
+type throttleRetryRoundTripper struct {
+	inner http.RoundTripper
+	clock clockwork.Clock
+	log   log.FieldLogger
+	// retryGets enables the bounded client-transparent retry of throttled
+	// GET requests.
+	retryGets bool
+}
+
+// RoundTrip sends the request upstream, recording and optionally retrying
+// throttled responses.
+func (t *throttleRetryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if t.retryGets && req.Method == http.MethodGet && req.Body == nil {
+		attempts += maxThrottleRetries
+	}
+
+	var resp *http.Response
+	for attempt := 1; ; attempt++ {
+		var err error
+		resp, err = t.inner.RoundTrip(req)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if !isThrottledResponse(resp) {
+			return resp, nil
+		}
+
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), t.clock)
+		kubeUpstreamThrottledRequests.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+		t.log.WithFields(log.Fields{
+			"method":      req.Method,
+			"path":        req.URL.Path,
+			"status_code": resp.StatusCode,
+			"retry_after": retryAfter,
+		}).Warn("Upstream Kubernetes API server throttled the request.")
+
+		if attempt >= attempts {
+			break
+		}
+		wait := retryAfter
+		if wait <= 0 {
+			wait = defaultThrottleRetryWait
+		}
+		if wait > maxThrottleRetryWait {
+			// The upstream asked for a longer backoff than we're willing to
+			// hold the client connection for; let the client handle it.
+			break
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		select {
+		case <-req.Context().Done():
+			return nil, trace.Wrap(req.Context().Err())
+		case <-t.clock.After(wait):
+		}
+	}
+
+	// The throttled response is returned as-is so the Retry-After header
+	// reaches the client.
+	return resp, nil
+}
+
+// isThrottledResponse returns true when the response indicates the upstream
+// API server is throttling or shedding load.
+func isThrottledResponse(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+}
+
+// parseRetryAfter parses a Retry-After header value, either a delay in
+// seconds or an HTTP date. Zero is returned when the value is missing or
+// unparsable.
+func parseRetryAfter(value string, clock clockwork.Clock) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if wait := when.Sub(clock.Now()); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -0,0 +1,153 @@
+// Copyright 2023 Gravitational, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRoundTripper serves a canned sequence of responses.
+type stubRoundTripper struct {
+	responses []*http.Response
+	calls     int
+}
+
+func (s *stubRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp := s.responses[s.calls]
+	s.calls++
+	return resp, nil
+}
+
+func stubResponse(statusCode string, code int, retryAfter string) *http.Response {
+	resp := &http.Response{
+		Status:     statusCode,
+		StatusCode: code,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+	if retryAfter != "" {
+		resp.Header.Set("Retry-After", retryAfter)
+	}
+	return resp
+}
+
+func TestThrottleRetryRoundTripper(t *testing.T) {
+	t.Parallel()
+
+	newRoundTripper := func(retryGets bool, responses ...*http.Response) (*throttleRetryRoundTripper, *stubRoundTripper) {
+		inner := &stubRoundTripper{responses: responses}
+		return &throttleRetryRoundTripper{
+			inner:     inner,
+			clock:     clockwork.NewRealClock(),
+			log:       log.StandardLogger(),
+			retryGets: retryGets,
+		}, inner
+	}
+
+	t.Run("passes through non-throttled responses", func(t *testing.T) {
+		rt, inner := newRoundTripper(true, stubResponse("200 OK", http.StatusOK, ""))
+		req, err := http.NewRequest(http.MethodGet, "https://kube/api/v1/pods", nil)
+		require.NoError(t, err)
+
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, 1, inner.calls)
+	})
+
+	t.Run("retries throttled GETs and succeeds", func(t *testing.T) {
+		rt, inner := newRoundTripper(true,
+			stubResponse("429 Too Many Requests", http.StatusTooManyRequests, "0"),
+			stubResponse("200 OK", http.StatusOK, ""),
+		)
+		req, err := http.NewRequest(http.MethodGet, "https://kube/api/v1/pods", nil)
+		require.NoError(t, err)
+
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, 2, inner.calls)
+	})
+
+	t.Run("preserves Retry-After after retries are exhausted", func(t *testing.T) {
+		rt, inner := newRoundTripper(true,
+			stubResponse("429 Too Many Requests", http.StatusTooManyRequests, "0"),
+			stubResponse("429 Too Many Requests", http.StatusTooManyRequests, "0"),
+			stubResponse("503 Service Unavailable", http.StatusServiceUnavailable, "7"),
+		)
+		req, err := http.NewRequest(http.MethodGet, "https://kube/api/v1/pods", nil)
+		require.NoError(t, err)
+
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		require.Equal(t, "7", resp.Header.Get("Retry-After"))
+		require.Equal(t, 1+maxThrottleRetries, inner.calls)
+	})
+
+	t.Run("does not retry non-GET requests", func(t *testing.T) {
+		rt, inner := newRoundTripper(true, stubResponse("429 Too Many Requests", http.StatusTooManyRequests, "1"))
+		req, err := http.NewRequest(http.MethodDelete, "https://kube/api/v1/pods/p", nil)
+		require.NoError(t, err)
+
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.Equal(t, 1, inner.calls)
+	})
+
+	t.Run("does not retry when disabled", func(t *testing.T) {
+		rt, inner := newRoundTripper(false, stubResponse("429 Too Many Requests", http.StatusTooManyRequests, "1"))
+		req, err := http.NewRequest(http.MethodGet, "https://kube/api/v1/pods", nil)
+		require.NoError(t, err)
+
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.Equal(t, 1, inner.calls)
+	})
+
+	t.Run("gives up on long Retry-After hints", func(t *testing.T) {
+		rt, inner := newRoundTripper(true, stubResponse("429 Too Many Requests", http.StatusTooManyRequests, "3600"))
+		req, err := http.NewRequest(http.MethodGet, "https://kube/api/v1/pods", nil)
+		require.NoError(t, err)
+
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.Equal(t, 1, inner.calls)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	clock := clockwork.NewFakeClock()
+	require.Equal(t, time.Duration(0), parseRetryAfter("", clock))
+	require.Equal(t, 30*time.Second, parseRetryAfter("30", clock))
+	require.Equal(t, time.Duration(0), parseRetryAfter("garbage", clock))
+	require.Equal(t, time.Duration(0), parseRetryAfter("-5", clock))
+
+	when := clock.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	require.Equal(t, time.Minute, parseRetryAfter(when, clock))
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/gravitational/teleport"
+	apidefaults "github.com/gravitational/teleport/api/defaults"
+	"github.com/gravitational/teleport/api/types"
+	"github.com/gravitational/teleport/lib/events"
+)
+
+const (
+	// uploadQueueHealthLabel is the heartbeat label that surfaces the state
+	// of the session recording upload queue, so alerts can fire before
+	// recordings are lost with the pod.
+	uploadQueueHealthLabel = types.TeleportInternalLabelPrefix + "upload-queue-health"
+	// uploadQueueHealthy means the queue is below the warning threshold.
+	uploadQueueHealthy = "healthy"
+	// uploadQueueBacklogged means the queue exceeded the warning threshold
+	// and new exec sessions are being slowed down.
+	uploadQueueBacklogged = "backlogged"
+	// uploadQueueSaturated means the queue exceeded the maximum threshold
+	// and new exec sessions are refused.
+	uploadQueueSaturated = "saturated"
+
+	// uploadQueueWarnBytes is the queue size past which new exec sessions
+	// are delayed by uploadQueueThrottleDelay.
+	uploadQueueWarnBytes = 512 * 1024 * 1024
+	// uploadQueueMaxBytes is the queue size past which new exec sessions
+	// are refused until the uploader catches up.
+	uploadQueueMaxBytes = 2 * 1024 * 1024 * 1024
+	// uploadQueueThrottleDelay is how long a new exec session is held back
+	// while the queue is backlogged.
+	uploadQueueThrottleDelay = 2 * time.Second
+	// uploadQueueScanInterval is how long scan results are cached; the
+	// queue directory is not walked more often than this.
+	uploadQueueScanInterval = 10 * time.Second
+)
+
+var (
+	kubeUploadQueueBytes = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: teleport.MetricNamespace,
+			Name:      "kube_upload_queue_bytes",
+			Help:      "Size in bytes of session recording chunks buffered on disk awaiting upload.",
+		},
+	)
+	kubeUploadQueueFiles = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: teleport.MetricNamespace,
+			Name:      "kube_upload_queue_files",
+			Help:      "Number of session recording chunk files buffered on disk awaiting upload.",
+		},
+	)
+	kubeThrottledExecSessions = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: teleport.MetricNamespace,
+			Name:      "kube_throttled_exec_sessions_total",
+			Help:      "Number of exec sessions delayed or refused because the session recording upload queue backed up.",
+		},
+	)
+)
+
+// uploadQueueMonitor watches the directory where the async streamer buffers
+// session recording chunks before upload and applies backpressure to new
+// exec sessions when the uploader falls behind, e.g. on slow disks.
+type uploadQueueMonitor struct {
+	dir   string
+	clock clockwork.Clock
+
+	mu sync.Mutex
+	// lastScan is when the directory was last walked.
+	lastScan time.Time
+	// queueBytes and queueFiles are the cached results of the last walk.
+	queueBytes int64
+	queueFiles int
+}
+
+// newUploadQueueMonitor creates a monitor for the streaming upload directory
+// under the given data dir.
+func newUploadQueueMonitor(dataDir string, clock clockwork.Clock) *uploadQueueMonitor {
+	return &uploadQueueMonitor{
+		dir: filepath.Join(
+			dataDir, teleport.LogsDir, teleport.ComponentUpload,
+			events.StreamingSessionsDir, apidefaults.Namespace,
+		),
+		clock: clock,
+	}
+}
+
+// queueSize returns the total size and file count of the upload queue,
+// walking the directory at most once per uploadQueueScanInterval.
+func (m *uploadQueueMonitor) queueSize() (int64, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now := m.clock.Now(); now.Sub(m.lastScan) >= uploadQueueScanInterval {
+		m.lastScan = now
+		m.queueBytes, m.queueFiles = scanUploadDir(m.dir)
+		kubeUploadQueueBytes.Set(float64(m.queueBytes))
+		kubeUploadQueueFiles.Set(float64(m.queueFiles))
+	}
+	return m.queueBytes, m.queueFiles
+}
+
+// health returns the heartbeat label value describing the queue state.
+func (m *uploadQueueMonitor) health() string {
+	if m == nil {
+		return uploadQueueHealthy
+	}
+	bytes, _ := m.queueSize()
+	switch {
+	case bytes > uploadQueueMaxBytes:
+		return uploadQueueSaturated
+	case bytes > uploadQueueWarnBytes:
+		return uploadQueueBacklogged
+	default:
+		return uploadQueueHealthy
+	}
+}
+
+// waitForCapacity applies backpressure before a new exec session is
+// accepted: backlogged queues delay the session, saturated queues refuse it.
+// The returned error is a trace.LimitExceeded so clients can tell the
+// refusal apart from authorization failures.
+func (m *uploadQueueMonitor) waitForCapacity() error {
+	bytes, files := m.queueSize()
+	switch {
+	case bytes > uploadQueueMaxBytes:
+		kubeThrottledExecSessions.Inc()
+		return trace.LimitExceeded(
+			"session recording upload queue holds %v bytes in %v files, refusing new exec sessions until the uploader catches up",
+			bytes, files)
+	case bytes > uploadQueueWarnBytes:
+		kubeThrottledExecSessions.Inc()
+		m.clock.Sleep(uploadQueueThrottleDelay)
+	}
+	return nil
+}
+
+// scanUploadDir sums the sizes of the regular files below dir. A missing
+// directory counts as an empty queue since the streamer creates it lazily.
+func scanUploadDir(dir string) (int64, int) {
+	var bytes int64
+	var files int
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			// Files may disappear mid-walk as uploads complete.
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			bytes += info.Size()
+			files++
+		}
+		return nil
+	})
+	return bytes, files
+}
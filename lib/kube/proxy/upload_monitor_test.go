@@ -0,0 +1,99 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadQueueMonitor(t *testing.T) {
+	t.Parallel()
+
+	clock := clockwork.NewFakeClock()
+	dataDir := t.TempDir()
+	monitor := newUploadQueueMonitor(dataDir, clock)
+
+	// Missing directory counts as an empty, healthy queue.
+	bytes, files := monitor.queueSize()
+	require.Zero(t, bytes)
+	require.Zero(t, files)
+	require.Equal(t, uploadQueueHealthy, monitor.health())
+	require.NoError(t, monitor.waitForCapacity())
+
+	// Buffer some chunks and verify the scan picks them up after the cache
+	// interval elapses.
+	require.NoError(t, os.MkdirAll(monitor.dir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(monitor.dir, "chunk-1"), make([]byte, 100), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(monitor.dir, "chunk-2"), make([]byte, 50), 0o600))
+
+	// Still cached from the first scan.
+	bytes, files = monitor.queueSize()
+	require.Zero(t, bytes)
+	require.Zero(t, files)
+
+	clock.Advance(uploadQueueScanInterval)
+	bytes, files = monitor.queueSize()
+	require.EqualValues(t, 150, bytes)
+	require.Equal(t, 2, files)
+	require.Equal(t, uploadQueueHealthy, monitor.health())
+}
+
+func TestUploadQueueMonitorBackpressure(t *testing.T) {
+	t.Parallel()
+
+	clock := clockwork.NewFakeClock()
+	monitor := newUploadQueueMonitor(t.TempDir(), clock)
+
+	// Simulate scans by seeding the cached results directly; writing
+	// gigabytes of fixture data is not reasonable in a unit test.
+	monitor.mu.Lock()
+	monitor.lastScan = clock.Now()
+	monitor.queueBytes = uploadQueueWarnBytes + 1
+	monitor.queueFiles = 1
+	monitor.mu.Unlock()
+
+	require.Equal(t, uploadQueueBacklogged, monitor.health())
+	done := make(chan error, 1)
+	go func() {
+		done <- monitor.waitForCapacity()
+	}()
+	// The backlogged queue delays the session by the throttle delay.
+	clock.BlockUntil(1)
+	clock.Advance(uploadQueueThrottleDelay)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for throttled session to be released")
+	}
+
+	monitor.mu.Lock()
+	monitor.queueBytes = uploadQueueMaxBytes + 1
+	monitor.mu.Unlock()
+
+	require.Equal(t, uploadQueueSaturated, monitor.health())
+	err := monitor.waitForCapacity()
+	require.Error(t, err)
+	require.True(t, trace.IsLimitExceeded(err))
+}
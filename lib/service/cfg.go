@@ -774,6 +774,17 @@ type KubeConfig struct {
 	// Optional.
 	ListenAddr *utils.NetAddr
 
+	// EnableProxyProtocol enables proxy protocol support
+	EnableProxyProtocol bool
+
+	// DebugHeaders enables injection of diagnostic headers into kube
+	// responses to aid troubleshooting.
+	DebugHeaders bool
+
+	// RecordPortForwardData enables metadata-only audit events for kubectl
+	// port-forward data streams (ports, byte counts and duration).
+	RecordPortForwardData bool
+
 	// PublicAddrs is a list of the public addresses the Teleport kubernetes
 	// service can be reached by the proxy service.
 	PublicAddrs []utils.NetAddr
@@ -1592,6 +1603,8 @@ func ApplyDefaults(cfg *Config) {
 
 	// Kubernetes service defaults.
 	cfg.Kube.Enabled = false
+	cfg.Kube.EnableProxyProtocol = true
+	cfg.Kube.RecordPortForwardData = true
 	defaults.ConfigureLimiter(&cfg.Kube.Limiter)
 
 	// Apps service defaults. It's disabled by default.
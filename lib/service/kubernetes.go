@@ -231,6 +231,8 @@ func (process *TeleportProcess) initKubernetesService(log *logrus.Entry, conn *C
 			LockWatcher:                   lockWatcher,
 			CheckImpersonationPermissions: cfg.Kube.CheckImpersonationPermissions,
 			PublicAddr:                    publicAddr,
+			DebugHeaders:                  cfg.Kube.DebugHeaders,
+			RecordPortForwardData:         cfg.Kube.RecordPortForwardData,
 		},
 		TLS:                  tlsConfig,
 		AccessPoint:          accessPoint,
@@ -243,6 +245,7 @@ func (process *TeleportProcess) initKubernetesService(log *logrus.Entry, conn *C
 		DynamicLabels:        dynLabels,
 		CloudLabels:          process.cloudLabels,
 		Log:                  log,
+		EnableProxyProtocol:  cfg.Kube.EnableProxyProtocol,
 	})
 	if err != nil {
 		return trace.Wrap(err)
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+
+	"github.com/gravitational/trace"
+
+	"github.com/gravitational/teleport/api/types"
+)
+
+// RoleAccessDecision records how a single role affected an access check.
+type RoleAccessDecision struct {
+	// Role is the name of the role.
+	Role string
+	// Allows is true when the role's allow rules matched the resource.
+	Allows bool
+	// Denies is true when the role's deny rules matched the resource.
+	Denies bool
+	// Reason is a human-readable explanation of why the role did or did
+	// not match.
+	Reason string
+}
+
+// AccessExplanation is the outcome of evaluating a role set against a
+// resource, with a per-role breakdown of what allowed or denied access.
+type AccessExplanation struct {
+	// Allowed is true when at least one role allows access and no role
+	// denies it.
+	Allowed bool
+	// Decisions holds one entry per role in the set, in role-set order.
+	Decisions []RoleAccessDecision
+}
+
+// ExplainAccess evaluates access to the given resource for every role in
+// the set and explains each role's contribution, mirroring the logic of
+// checkAccess. It is meant for pre-flight "why can't I see this resource"
+// debugging; per-session MFA and device trust restrictions still apply at
+// connection time and are not reflected here.
+func (set RoleSet) ExplainAccess(r AccessCheckable, matchers ...RoleMatcher) (*AccessExplanation, error) {
+	getRoleLabels, _, err := roleLabelsForKind(r.GetKind())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	namespace := types.ProcessNamespace(r.GetMetadata().Namespace)
+	allLabels := r.GetAllLabels()
+
+	explanation := &AccessExplanation{}
+	var anyAllow, anyDeny bool
+	for _, role := range set {
+		decision := RoleAccessDecision{Role: role.GetName()}
+
+		// Deny rules are greedy: a namespace match plus either a label or
+		// any matcher match denies access.
+		matchNamespace, _ := MatchNamespace(role.GetNamespaces(types.Deny), namespace)
+		if matchNamespace {
+			matchLabels, labelsMessage, err := MatchLabels(getRoleLabels(role, types.Deny), allLabels)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			matchMatchers, matchersMessage, err := RoleMatchers(matchers).MatchAny(role, types.Deny)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			switch {
+			case matchLabels:
+				decision.Denies = true
+				decision.Reason = fmt.Sprintf("deny rule matched; match(label=%v)", labelsMessage)
+			case matchMatchers:
+				decision.Denies = true
+				decision.Reason = fmt.Sprintf("deny rule matched; match(matcher=%v)", matchersMessage)
+			}
+		}
+		if decision.Denies {
+			anyDeny = true
+			explanation.Decisions = append(explanation.Decisions, decision)
+			continue
+		}
+
+		// Allow rules require the namespace, labels and all matchers to
+		// match.
+		matchNamespace, namespaceMessage := MatchNamespace(role.GetNamespaces(types.Allow), namespace)
+		if !matchNamespace {
+			decision.Reason = fmt.Sprintf("no match; match(namespace=%v)", namespaceMessage)
+			explanation.Decisions = append(explanation.Decisions, decision)
+			continue
+		}
+		matchLabels, labelsMessage, err := MatchLabels(getRoleLabels(role, types.Allow), allLabels)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if !matchLabels {
+			decision.Reason = fmt.Sprintf("no match; match(label=%v)", labelsMessage)
+			explanation.Decisions = append(explanation.Decisions, decision)
+			continue
+		}
+		matchMatchers, err := RoleMatchers(matchers).MatchAll(role, types.Allow)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if !matchMatchers {
+			decision.Reason = "no match; at least one matcher did not match the allow rule"
+			explanation.Decisions = append(explanation.Decisions, decision)
+			continue
+		}
+
+		decision.Allows = true
+		decision.Reason = fmt.Sprintf("allow rule matched; match(namespace=%v, label=%v)", namespaceMessage, labelsMessage)
+		anyAllow = true
+		explanation.Decisions = append(explanation.Decisions, decision)
+	}
+
+	explanation.Allowed = anyAllow && !anyDeny
+	return explanation, nil
+}
+
+type kubeGroupMatcher struct {
+	group string
+}
+
+// NewKubeGroupMatcher creates a RoleMatcher that checks whether the role's
+// Kubernetes groups match the specified condition.
+func NewKubeGroupMatcher(group string) RoleMatcher {
+	return &kubeGroupMatcher{group: group}
+}
+
+// Match matches a Kubernetes group against a role.
+func (m *kubeGroupMatcher) Match(role types.Role, typ types.RoleConditionType) (bool, error) {
+	for _, group := range role.GetKubeGroups(typ) {
+		if m.group == group {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/stretchr/testify/require"
+
+	apidefaults "github.com/gravitational/teleport/api/defaults"
+	"github.com/gravitational/teleport/api/types"
+)
+
+func TestExplainAccess(t *testing.T) {
+	t.Parallel()
+
+	server := &types.ServerV2{
+		Kind: types.KindNode,
+		Metadata: types.Metadata{
+			Name:      "node-a",
+			Namespace: apidefaults.Namespace,
+			Labels:    map[string]string{"env": "prod"},
+		},
+	}
+
+	allowProd := newRole(func(r *types.RoleV6) {
+		r.Metadata.Name = "allow-prod"
+		r.Spec.Allow.Logins = []string{"root"}
+		r.Spec.Allow.NodeLabels = types.Labels{"env": []string{"prod"}}
+	})
+	allowDev := newRole(func(r *types.RoleV6) {
+		r.Metadata.Name = "allow-dev"
+		r.Spec.Allow.Logins = []string{"root"}
+		r.Spec.Allow.NodeLabels = types.Labels{"env": []string{"dev"}}
+	})
+	denyProd := newRole(func(r *types.RoleV6) {
+		r.Metadata.Name = "deny-prod"
+		r.Spec.Allow.Logins = []string{"root"}
+		r.Spec.Deny.Namespaces = []string{apidefaults.Namespace}
+		r.Spec.Deny.NodeLabels = types.Labels{"env": []string{"prod"}}
+	})
+
+	tests := []struct {
+		name        string
+		set         RoleSet
+		login       string
+		wantAllowed bool
+		wantAllows  map[string]bool
+		wantDenies  map[string]bool
+	}{
+		{
+			name:        "allowed by matching role",
+			set:         RoleSet{allowProd, allowDev},
+			login:       "root",
+			wantAllowed: true,
+			wantAllows:  map[string]bool{"allow-prod": true, "allow-dev": false},
+			wantDenies:  map[string]bool{"allow-prod": false, "allow-dev": false},
+		},
+		{
+			name:        "denied by deny rule despite allow",
+			set:         RoleSet{allowProd, denyProd},
+			login:       "root",
+			wantAllowed: false,
+			wantAllows:  map[string]bool{"allow-prod": true, "deny-prod": false},
+			wantDenies:  map[string]bool{"allow-prod": false, "deny-prod": true},
+		},
+		{
+			name:        "denied when login doesn't match",
+			set:         RoleSet{allowProd},
+			login:       "admin",
+			wantAllowed: false,
+			wantAllows:  map[string]bool{"allow-prod": false},
+			wantDenies:  map[string]bool{"allow-prod": false},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			explanation, err := tt.set.ExplainAccess(server, NewLoginMatcher(tt.login))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAllowed, explanation.Allowed)
+			require.Len(t, explanation.Decisions, len(tt.set))
+			for _, decision := range explanation.Decisions {
+				require.Equal(t, tt.wantAllows[decision.Role], decision.Allows, "role %v allows", decision.Role)
+				require.Equal(t, tt.wantDenies[decision.Role], decision.Denies, "role %v denies", decision.Role)
+				require.NotEmpty(t, decision.Reason)
+			}
+		})
+	}
+
+	t.Run("unsupported kind", func(t *testing.T) {
+		unknown := &types.ServerV2{
+			Kind: "unknown",
+			Metadata: types.Metadata{
+				Name: "unknown",
+			},
+		}
+		_, err := RoleSet{allowProd}.ExplainAccess(unknown)
+		require.True(t, trace.IsBadParameter(err))
+	})
+}
+
+func TestKubeGroupMatcher(t *testing.T) {
+	t.Parallel()
+
+	role := newRole(func(r *types.RoleV6) {
+		r.Spec.Allow.KubeGroups = []string{"viewers"}
+		r.Spec.Deny.KubeGroups = []string{"admins"}
+	})
+
+	match, err := NewKubeGroupMatcher("viewers").Match(role, types.Allow)
+	require.NoError(t, err)
+	require.True(t, match)
+
+	match, err = NewKubeGroupMatcher("admins").Match(role, types.Allow)
+	require.NoError(t, err)
+	require.False(t, match)
+
+	match, err = NewKubeGroupMatcher("admins").Match(role, types.Deny)
+	require.NoError(t, err)
+	require.True(t, match)
+}
@@ -2294,6 +2294,35 @@ type AccessCheckable interface {
 	GetAllLabels() map[string]string
 }
 
+// roleLabelsForKind returns the role label selector getter for the given
+// resource kind, along with an additional message appended to access denied
+// errors so there's more context on why the user might not have access.
+func roleLabelsForKind(kind string) (getRoleLabels func(types.Role, types.RoleConditionType) types.Labels, additionalDeniedMessage string, err error) {
+	switch kind {
+	case types.KindDatabase:
+		getRoleLabels = types.Role.GetDatabaseLabels
+		additionalDeniedMessage = "Confirm database user and name."
+	case types.KindDatabaseService:
+		getRoleLabels = types.Role.GetDatabaseServiceLabels
+	case types.KindApp:
+		getRoleLabels = types.Role.GetAppLabels
+	case types.KindNode:
+		getRoleLabels = types.Role.GetNodeLabels
+		additionalDeniedMessage = "Confirm SSH login."
+	case types.KindKubernetesCluster:
+		getRoleLabels = types.Role.GetKubernetesLabels
+		additionalDeniedMessage = "Confirm Kubernetes user or group."
+	case types.KindWindowsDesktop:
+		getRoleLabels = types.Role.GetWindowsDesktopLabels
+		additionalDeniedMessage = "Confirm Windows user."
+	case types.KindWindowsDesktopService:
+		getRoleLabels = types.Role.GetWindowsDesktopLabels
+	default:
+		return nil, "", trace.BadParameter("cannot match labels for kind %v", kind)
+	}
+	return getRoleLabels, additionalDeniedMessage, nil
+}
+
 // rbacDebugLogger creates a debug logger for Teleport's RBAC component.
 // It also returns a flag indicating whether debug logging is enabled,
 // allowing the RBAC system to generate more verbose errors in debug mode.
@@ -2319,33 +2348,9 @@ func (set RoleSet) checkAccess(r AccessCheckable, state AccessState, matchers ..
 	namespace := types.ProcessNamespace(r.GetMetadata().Namespace)
 	allLabels := r.GetAllLabels()
 
-	// Additional message depending on kind of resource
-	// so there's more context on why the user might not have access.
-	additionalDeniedMessage := ""
-
-	var getRoleLabels func(types.Role, types.RoleConditionType) types.Labels
-
-	switch r.GetKind() {
-	case types.KindDatabase:
-		getRoleLabels = types.Role.GetDatabaseLabels
-		additionalDeniedMessage = "Confirm database user and name."
-	case types.KindDatabaseService:
-		getRoleLabels = types.Role.GetDatabaseServiceLabels
-	case types.KindApp:
-		getRoleLabels = types.Role.GetAppLabels
-	case types.KindNode:
-		getRoleLabels = types.Role.GetNodeLabels
-		additionalDeniedMessage = "Confirm SSH login."
-	case types.KindKubernetesCluster:
-		getRoleLabels = types.Role.GetKubernetesLabels
-		additionalDeniedMessage = "Confirm Kubernetes user or group."
-	case types.KindWindowsDesktop:
-		getRoleLabels = types.Role.GetWindowsDesktopLabels
-		additionalDeniedMessage = "Confirm Windows user."
-	case types.KindWindowsDesktopService:
-		getRoleLabels = types.Role.GetWindowsDesktopLabels
-	default:
-		return trace.BadParameter("cannot match labels for kind %v", r.GetKind())
+	getRoleLabels, additionalDeniedMessage, err := roleLabelsForKind(r.GetKind())
+	if err != nil {
+		return trace.Wrap(err)
 	}
 
 	// Check deny rules.
@@ -131,6 +131,30 @@ type SubsystemReq struct {
 	Name string
 }
 
+// FileTransferReq specifies the parameters for a "file-transfer-request"
+// request, asking session moderators to approve a file transfer.
+type FileTransferReq struct {
+	// RequestID uniquely identifies the request so the decision can be
+	// matched to it.
+	RequestID string
+	// Download is true for downloads and false for uploads.
+	Download bool
+	// Location is the file path on the node.
+	Location string
+	// Filename is the name of the file being transferred.
+	Filename string
+}
+
+// FileTransferDecisionReq specifies the parameters for a
+// "file-transfer-decision" request carrying a moderator's decision on a
+// pending file transfer request.
+type FileTransferDecisionReq struct {
+	// RequestID identifies the file transfer request being decided.
+	RequestID string
+	// Approved is true when the transfer was approved.
+	Approved bool
+}
+
 // SessionEnvVar is environment variable for SSH session
 const SessionEnvVar = "TELEPORT_SESSION"
 
@@ -168,6 +192,14 @@ const (
 	// PuTTYSimpleRequest is a PuTTY-specific channel name which it automatically requests when it proxies
 	// connections. Teleport does not support this channel type, so deliberately ignores requests for it.
 	PuTTYSimpleRequest = "simple@putty.projects.tartarus.org"
+
+	// FileTransferRequest is a request to initiate a file transfer in a
+	// moderated session, pending approval by the session moderators.
+	FileTransferRequest = "file-transfer-request@goteleport.com"
+
+	// FileTransferDecisionRequest carries a moderator's approval or denial
+	// of a pending file transfer request.
+	FileTransferDecisionRequest = "file-transfer-decision@goteleport.com"
 )
 
 const (
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/pkg/sftp"
+)
+
+// chownFS is implemented by filesystems that can change file ownership.
+type chownFS interface {
+	// Chown sets the owner and group of a file
+	Chown(ctx context.Context, path string, uid, gid int) error
+}
+
+// sparseTarget is the subset of file methods needed to recreate holes on
+// the destination; both *os.File and *sftp.File satisfy it.
+type sparseTarget interface {
+	io.Writer
+	io.Seeker
+	Truncate(int64) error
+}
+
+// fileOwner returns the uid and gid of the file described by fi, if the
+// information is available.
+func fileOwner(fi os.FileInfo) (uid, gid int, ok bool) {
+	if stat, ok := fi.Sys().(*sftp.FileStat); ok {
+		return int(stat.UID), int(stat.GID), true
+	}
+	return statOwner(fi)
+}
+
+// notePreserveFailure records an attribute that could not be preserved so
+// it can be reported in the transfer summary.
+func (c *Config) notePreserveFailure(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	c.Log.Debugf("Attribute not preserved: %s.", msg)
+	c.unpreserved = append(c.unpreserved, msg)
+}
+
+// UnpreservedAttrs returns a summary of the file attributes that could not
+// be preserved during the transfer, one entry per attribute and file. It
+// returns nil when every requested attribute was preserved.
+func (c *Config) UnpreservedAttrs() []string {
+	return c.unpreserved
+}
+
+// preserveAttrs applies the optional attribute preservation settings
+// (ownership and extended attributes) to a transferred file or directory,
+// degrading gracefully when the source doesn't expose an attribute or the
+// destination refuses it.
+func (c *Config) preserveAttrs(ctx context.Context, dstPath, srcPath string, srcFileInfo os.FileInfo) {
+	if c.opts.PreserveOwner {
+		c.preserveOwner(ctx, dstPath, srcFileInfo)
+	}
+	if c.opts.PreserveXattrs {
+		c.preserveXattrs(dstPath, srcPath)
+	}
+}
+
+func (c *Config) preserveOwner(ctx context.Context, dstPath string, srcFileInfo os.FileInfo) {
+	uid, gid, ok := fileOwner(srcFileInfo)
+	if !ok {
+		c.notePreserveFailure("ownership of %q: not available from %s source", dstPath, c.srcFS.Type())
+		return
+	}
+	cfs, ok := c.dstFS.(chownFS)
+	if !ok {
+		c.notePreserveFailure("ownership of %q: not supported by %s destination", dstPath, c.dstFS.Type())
+		return
+	}
+	if err := cfs.Chown(ctx, dstPath, uid, gid); err != nil {
+		c.notePreserveFailure("ownership of %s %q: %v", c.dstFS.Type(), dstPath, err)
+	}
+}
+
+func (c *Config) preserveXattrs(dstPath, srcPath string) {
+	// The SFTP protocol has no portable extended attribute support, so
+	// xattrs can only be copied when both sides are local filesystems.
+	if _, ok := c.srcFS.(*localFS); !ok {
+		c.notePreserveFailure("extended attributes of %q: not supported over SFTP", srcPath)
+		return
+	}
+	if _, ok := c.dstFS.(*localFS); !ok {
+		c.notePreserveFailure("extended attributes of %q: not supported over SFTP", dstPath)
+		return
+	}
+	if err := copyXattrs(srcPath, dstPath); err != nil {
+		c.notePreserveFailure("extended attributes of %q: %v", dstPath, err)
+	}
+}
+
+// trySparseTransfer copies only the data regions of a sparse source file,
+// recreating the holes on the destination. It returns true if the file was
+// fully transferred this way; false means the caller should fall back to a
+// regular copy.
+func (c *Config) trySparseTransfer(ctx context.Context, dstFile io.WriteCloser, srcFile fs.File, srcFileInfo os.FileInfo) (bool, error) {
+	// Hole detection requires a local source file.
+	wrapper, ok := srcFile.(*fileWrapper)
+	if !ok || !isSparse(srcFileInfo) {
+		return false, nil
+	}
+	dst, ok := dstFile.(sparseTarget)
+	if !ok {
+		c.notePreserveFailure("sparse regions of %q: destination does not support seeking", srcFileInfo.Name())
+		return false, nil
+	}
+
+	err := copySparse(ctx, dst, wrapper.file, srcFileInfo.Size())
+	return err == nil, err
+}
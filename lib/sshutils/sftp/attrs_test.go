@@ -0,0 +1,63 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopySparse(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("sparse file transfer is only supported on Linux and macOS")
+	}
+	t.Parallel()
+
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "src")
+	dstPath := filepath.Join(dir, "dst")
+
+	// create a file with a hole between two data regions and a
+	// trailing hole
+	src, err := os.Create(srcPath)
+	require.NoError(t, err)
+	defer src.Close()
+	_, err = src.Write([]byte("start"))
+	require.NoError(t, err)
+	_, err = src.WriteAt([]byte("middle"), 1<<20)
+	require.NoError(t, err)
+	require.NoError(t, src.Truncate(1<<21))
+
+	dst, err := os.Create(dstPath)
+	require.NoError(t, err)
+	defer dst.Close()
+
+	fi, err := src.Stat()
+	require.NoError(t, err)
+	require.NoError(t, copySparse(context.Background(), dst, src, fi.Size()))
+
+	srcContents, err := os.ReadFile(srcPath)
+	require.NoError(t, err)
+	dstContents, err := os.ReadFile(dstPath)
+	require.NoError(t, err)
+	require.Equal(t, srcContents, dstContents)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftp
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+// Typed errors for common remote SFTP failures. TransferFiles surfaces them
+// so callers can branch with errors.Is instead of parsing error strings.
+var (
+	// ErrPermissionDenied indicates the remote server denied access to a
+	// path.
+	ErrPermissionDenied = errors.New("sftp: permission denied")
+	// ErrNoSuchFile indicates a remote path does not exist.
+	ErrNoSuchFile = errors.New("sftp: no such file or directory")
+	// ErrNoSpace indicates the remote server ran out of disk space or
+	// quota.
+	ErrNoSpace = errors.New("sftp: no space left on device")
+)
+
+// classifiedError pairs the original remote error with its failure class, so
+// errors.Is can match the class while the full remote message is preserved.
+type classifiedError struct {
+	err   error
+	class error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+func (e *classifiedError) Is(target error) bool {
+	return target == e.class
+}
+
+// classifyError attaches one of the typed errors above to err when the
+// remote SFTP status code (or, for generic failures, the status message)
+// identifies a known failure class. Unrecognized errors pass through
+// unchanged.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var statusErr *sftp.StatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+
+	switch statusErr.FxCode() {
+	case sftp.ErrSSHFxPermissionDenied:
+		return &classifiedError{err: err, class: ErrPermissionDenied}
+	case sftp.ErrSSHFxNoSuchFile:
+		return &classifiedError{err: err, class: ErrNoSuchFile}
+	case sftp.ErrSSHFxFailure:
+		// SFTPv3 has no dedicated code for exhausted disk space or quota,
+		// servers report it as a generic failure with a descriptive message.
+		msg := strings.ToLower(statusErr.Error())
+		for _, marker := range []string{"no space", "disk full", "quota"} {
+			if strings.Contains(msg, marker) {
+				return &classifiedError{err: err, class: ErrNoSpace}
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftp
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	t.Parallel()
+
+	permErr := &sftp.StatusError{Code: uint32(sftp.ErrSSHFxPermissionDenied)}
+	missingErr := &sftp.StatusError{Code: uint32(sftp.ErrSSHFxNoSuchFile)}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "permission denied",
+			err:  permErr,
+			want: ErrPermissionDenied,
+		},
+		{
+			name: "no such file",
+			err:  missingErr,
+			want: ErrNoSuchFile,
+		},
+		{
+			name: "wrapped status error",
+			err:  trace.Wrap(permErr),
+			want: ErrPermissionDenied,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			classified := classifyError(tt.err)
+			require.True(t, errors.Is(classified, tt.want))
+			// The original message and error remain reachable.
+			require.Equal(t, tt.err.Error(), classified.Error())
+			var statusErr *sftp.StatusError
+			require.True(t, errors.As(classified, &statusErr))
+		})
+	}
+
+	// Errors without an SFTP status pass through unchanged.
+	require.Equal(t, io.EOF, classifyError(io.EOF))
+	require.NoError(t, classifyError(nil))
+}
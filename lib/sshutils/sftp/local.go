@@ -115,6 +115,14 @@ func (l localFS) Chmod(ctx context.Context, path string, mode os.FileMode) error
 	return trace.Wrap(os.Chmod(path, mode))
 }
 
+func (l localFS) Chown(ctx context.Context, path string, uid, gid int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return trace.ConvertSystemError(os.Chown(path, uid, gid))
+}
+
 func (l localFS) Chtimes(ctx context.Context, path string, atime, mtime time.Time) error {
 	if err := ctx.Err(); err != nil {
 		return err
@@ -122,3 +130,19 @@ func (l localFS) Chtimes(ctx context.Context, path string, atime, mtime time.Tim
 
 	return trace.ConvertSystemError(os.Chtimes(path, atime, mtime))
 }
+
+func (l localFS) Rename(ctx context.Context, oldPath, newPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return trace.ConvertSystemError(os.Rename(oldPath, newPath))
+}
+
+func (l localFS) Remove(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return trace.ConvertSystemError(os.Remove(path))
+}
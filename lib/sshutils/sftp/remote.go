@@ -110,6 +110,14 @@ func (r *remoteFS) Chmod(ctx context.Context, path string, mode os.FileMode) err
 	return trace.Wrap(r.c.Chmod(path, mode))
 }
 
+func (r *remoteFS) Chown(ctx context.Context, path string, uid, gid int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return trace.Wrap(r.c.Chown(path, uid, gid))
+}
+
 func (r *remoteFS) Chtimes(ctx context.Context, path string, atime, mtime time.Time) error {
 	if err := ctx.Err(); err != nil {
 		return err
@@ -117,3 +125,19 @@ func (r *remoteFS) Chtimes(ctx context.Context, path string, atime, mtime time.T
 
 	return trace.Wrap(r.c.Chtimes(path, atime, mtime))
 }
+
+func (r *remoteFS) Rename(ctx context.Context, oldPath, newPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return trace.Wrap(r.c.PosixRename(oldPath, newPath))
+}
+
+func (r *remoteFS) Remove(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return trace.Wrap(r.c.Remove(path))
+}
@@ -47,6 +47,21 @@ type Options struct {
 	// PreserveAttrs preserves access and modification times
 	// from the original file
 	PreserveAttrs bool
+	// PreserveOwner preserves the uid and gid of the original file,
+	// when the destination side permits it
+	PreserveOwner bool
+	// PreserveXattrs preserves extended attributes of the original file;
+	// only possible when both sides are local filesystems
+	PreserveXattrs bool
+	// PreserveSparse transfers only the data regions of sparse files,
+	// recreating holes on the destination instead of writing out zeros
+	PreserveSparse bool
+	// KeepPartialFiles writes directly to the destination path instead of
+	// a temporary file, leaving partially transferred files behind if the
+	// transfer is interrupted so they can be resumed later. By default
+	// files are written to a temporary file that is renamed into place
+	// once the transfer completes.
+	KeepPartialFiles bool
 }
 
 type homeDirRetriever func() (string, error)
@@ -68,6 +83,10 @@ type Config struct {
 	ProgressStream func(fileInfo os.FileInfo) io.ReadWriter
 	// Log optionally specifies the logger
 	Log log.FieldLogger
+
+	// unpreserved collects the attributes that could not be preserved
+	// during the transfer; see UnpreservedAttrs
+	unpreserved []string
 }
 
 // FileSystem describes file operations to be done either locally or over SFTP
@@ -88,6 +107,10 @@ type FileSystem interface {
 	Chmod(ctx context.Context, path string, mode os.FileMode) error
 	// Chtimes sets file access and modification time
 	Chtimes(ctx context.Context, path string, atime, mtime time.Time) error
+	// Rename renames a file
+	Rename(ctx context.Context, oldPath, newPath string) error
+	// Remove removes a file
+	Remove(ctx context.Context, path string) error
 }
 
 // CreateUploadConfig returns a Config ready to upload files
@@ -145,8 +168,11 @@ func (c *Config) setDefaults() {
 		trace.ComponentFields: log.Fields{
 			"SrcPaths":      c.srcPaths,
 			"DstPath":       c.dstPath,
-			"Recursive":     c.opts.Recursive,
-			"PreserveAttrs": c.opts.PreserveAttrs,
+			"Recursive":      c.opts.Recursive,
+			"PreserveAttrs":  c.opts.PreserveAttrs,
+			"PreserveOwner":  c.opts.PreserveOwner,
+			"PreserveXattrs": c.opts.PreserveXattrs,
+			"PreserveSparse": c.opts.PreserveSparse,
 		},
 	})
 }
@@ -169,7 +195,7 @@ func (c *Config) TransferFiles(ctx context.Context, sshClient *ssh.Client) error
 	transferErr := c.transfer(ctx)
 	closeErr := sftpClient.Close()
 	if transferErr != nil {
-		return trace.Wrap(transferErr)
+		return trace.Wrap(classifyError(transferErr))
 	}
 
 	return trace.Wrap(closeErr)
@@ -370,12 +396,55 @@ func (c *Config) transferDir(ctx context.Context, dstPath, srcPath string, srcFi
 			return trace.Errorf("error changing times of %s directory %q: %w", c.dstFS.Type(), dstPath, err)
 		}
 	}
+	c.preserveAttrs(ctx, dstPath, srcPath, srcFileInfo)
 
 	return nil
 }
 
-// transferFile transfers a file
+// partialSuffix is appended to the name of the temporary file a transfer
+// writes to before it is renamed into place.
+const partialSuffix = ".part"
+
+// transferFile transfers a file. Unless Options.KeepPartialFiles is set, the
+// file is written to a temporary file that is renamed into place once the
+// transfer completes, so an interrupted transfer doesn't leave a truncated
+// file at the destination.
 func (c *Config) transferFile(ctx context.Context, dstPath, srcPath string, srcFileInfo os.FileInfo) error {
+	writePath := dstPath
+	if !c.opts.KeepPartialFiles {
+		writePath = dstPath + partialSuffix
+	}
+
+	if err := c.writeFile(ctx, writePath, srcPath, srcFileInfo); err != nil {
+		if writePath != dstPath {
+			// Clean up with a fresh context so the partial file is removed
+			// even when the transfer was canceled.
+			if rmErr := c.dstFS.Remove(context.Background(), writePath); rmErr != nil {
+				c.Log.WithError(rmErr).Warnf("Failed to remove partial %s file %q.", c.dstFS.Type(), writePath)
+			}
+		}
+		return trace.Wrap(err)
+	}
+
+	if writePath != dstPath {
+		if err := c.dstFS.Rename(ctx, writePath, dstPath); err != nil {
+			return trace.Errorf("error renaming %s file %q to %q: %w", c.dstFS.Type(), writePath, dstPath, err)
+		}
+	}
+
+	if c.opts.PreserveAttrs {
+		err := c.dstFS.Chtimes(ctx, dstPath, getAtime(srcFileInfo), srcFileInfo.ModTime())
+		if err != nil {
+			return trace.Errorf("error changing times of %s file %q: %w", c.dstFS.Type(), dstPath, err)
+		}
+	}
+	c.preserveAttrs(ctx, dstPath, srcPath, srcFileInfo)
+
+	return nil
+}
+
+// writeFile copies the source file's contents to dstPath.
+func (c *Config) writeFile(ctx context.Context, dstPath, srcPath string, srcFileInfo os.FileInfo) error {
 	srcFile, err := c.srcFS.Open(ctx, srcPath)
 	if err != nil {
 		return trace.Errorf("error opening %s file %q: %w", c.srcFS.Type(), srcPath, err)
@@ -388,38 +457,51 @@ func (c *Config) transferFile(ctx context.Context, dstPath, srcPath string, srcF
 	}
 	defer dstFile.Close()
 
-	var progressBar io.ReadWriter
-	if c.ProgressStream != nil {
-		progressBar = c.ProgressStream(srcFileInfo)
+	var transferred bool
+	if c.opts.PreserveSparse {
+		transferred, err = c.trySparseTransfer(ctx, dstFile, srcFile, srcFileInfo)
+		if err != nil {
+			return trace.Errorf("error copying sparse %s file %q to %s file %q: %w",
+				c.srcFS.Type(),
+				srcPath,
+				c.dstFS.Type(),
+				dstPath,
+				err,
+			)
+		}
 	}
 
-	reader, writer := prepareStreams(ctx, srcFile, dstFile, progressBar)
+	if !transferred {
+		var progressBar io.ReadWriter
+		if c.ProgressStream != nil {
+			progressBar = c.ProgressStream(srcFileInfo)
+		}
 
-	if err := assertStreamsType(reader, writer); err != nil {
-		return trace.Wrap(err)
-	}
+		reader, writer := prepareStreams(ctx, srcFile, dstFile, progressBar)
 
-	n, err := io.Copy(writer, reader)
-	if err != nil {
-		return trace.Errorf("error copying %s file %q to %s file %q: %w",
-			c.srcFS.Type(),
-			srcPath,
-			c.dstFS.Type(),
-			dstPath,
-			err,
-		)
-	}
-	if n != srcFileInfo.Size() {
-		return trace.Errorf("short write: written %v, expected %v", n, srcFileInfo.Size())
-	}
+		if err := assertStreamsType(reader, writer); err != nil {
+			return trace.Wrap(err)
+		}
 
-	if c.opts.PreserveAttrs {
-		err := c.dstFS.Chtimes(ctx, dstPath, getAtime(srcFileInfo), srcFileInfo.ModTime())
+		n, err := io.Copy(writer, reader)
 		if err != nil {
-			return trace.Errorf("error changing times of %s file %q: %w", c.dstFS.Type(), dstPath, err)
+			return trace.Errorf("error copying %s file %q to %s file %q: %w",
+				c.srcFS.Type(),
+				srcPath,
+				c.dstFS.Type(),
+				dstPath,
+				err,
+			)
+		}
+		if n != srcFileInfo.Size() {
+			return trace.Errorf("short write: written %v, expected %v", n, srcFileInfo.Size())
 		}
 	}
 
+	if err := dstFile.Close(); err != nil {
+		return trace.Errorf("error closing %s file %q: %w", c.dstFS.Type(), dstPath, err)
+	}
+
 	return nil
 }
 
@@ -19,8 +19,10 @@ package sftp
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -329,6 +331,73 @@ func TestHomeDirExpansion(t *testing.T) {
 	}
 }
 
+func TestPartialFileCleanup(t *testing.T) {
+	t.Parallel()
+
+	newConfig := func(t *testing.T, srcPath, dstPath string, opts Options) *Config {
+		cfg, err := CreateDownloadConfig(srcPath, dstPath, opts)
+		require.NoError(t, err)
+		// use all local filesystems to avoid SSH overhead, failing
+		// reads mid-transfer
+		cfg.srcFS = failingReadFS{&localFS{}}
+		require.NoError(t, cfg.initFS(nil, nil))
+		return cfg
+	}
+
+	t.Run("partial file removed by default", func(t *testing.T) {
+		tempDir := t.TempDir()
+		createFile(t, tempDir, "file")
+		dstPath := filepath.Join(tempDir, "copied-file")
+
+		cfg := newConfig(t, filepath.Join(tempDir, "file"), dstPath, Options{})
+		require.Error(t, cfg.transfer(context.Background()))
+
+		// neither the destination file nor the temporary file remain
+		_, err := os.Stat(dstPath)
+		require.True(t, os.IsNotExist(err))
+		_, err = os.Stat(dstPath + partialSuffix)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("partial file kept when requested", func(t *testing.T) {
+		tempDir := t.TempDir()
+		createFile(t, tempDir, "file")
+		dstPath := filepath.Join(tempDir, "copied-file")
+
+		cfg := newConfig(t, filepath.Join(tempDir, "file"), dstPath, Options{
+			KeepPartialFiles: true,
+		})
+		require.Error(t, cfg.transfer(context.Background()))
+
+		// the partially transferred destination file remains
+		_, err := os.Stat(dstPath)
+		require.NoError(t, err)
+	})
+}
+
+// failingReadFS wraps a FileSystem and returns files whose reads always
+// fail, simulating an interrupted transfer.
+type failingReadFS struct {
+	FileSystem
+}
+
+func (f failingReadFS) Open(ctx context.Context, path string) (fs.File, error) {
+	file, err := f.FileSystem.Open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return failingReadFile{file}, nil
+}
+
+// failingReadFile is a file whose reads always fail.
+type failingReadFile struct {
+	fs.File
+}
+
+func (f failingReadFile) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
 func createFile(t *testing.T, rootDir, path string) {
 	dir := filepath.Dir(path)
 	if dir != path {
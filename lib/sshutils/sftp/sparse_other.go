@@ -0,0 +1,38 @@
+//go:build !linux && !darwin
+
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftp
+
+import (
+	"context"
+	"os"
+
+	"github.com/gravitational/trace"
+)
+
+// isSparse reports whether fi describes a local file that contains holes.
+// Hole detection is not supported on this platform.
+func isSparse(fi os.FileInfo) bool {
+	return false
+}
+
+// copySparse copies only the data regions of src to dst. Not supported on
+// this platform.
+func copySparse(ctx context.Context, dst sparseTarget, src *os.File, size int64) error {
+	return trace.NotImplemented("sparse file transfer is not supported on this platform")
+}
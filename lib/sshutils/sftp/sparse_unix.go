@@ -0,0 +1,77 @@
+//go:build linux || darwin
+
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"syscall"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/sys/unix"
+)
+
+// isSparse reports whether fi describes a local file that occupies fewer
+// disk blocks than its logical size, i.e. contains holes.
+func isSparse(fi os.FileInfo) bool {
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	// stat block counts are always in 512-byte units
+	return ok && stat.Blocks*512 < stat.Size
+}
+
+// copySparse copies only the data regions of src to dst, seeking over holes
+// so they are recreated instead of being written out as zeros, and extends
+// dst to the logical size of src to preserve a trailing hole.
+func copySparse(ctx context.Context, dst sparseTarget, src *os.File, size int64) error {
+	fd := int(src.Fd())
+	var offset int64
+	for offset < size {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		dataStart, err := unix.Seek(fd, offset, unix.SEEK_DATA)
+		if err != nil {
+			// ENXIO means there is no more data, only a trailing hole.
+			if errors.Is(err, unix.ENXIO) {
+				break
+			}
+			return trace.ConvertSystemError(err)
+		}
+		dataEnd, err := unix.Seek(fd, dataStart, unix.SEEK_HOLE)
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		// finding the end of the data region moved the file offset,
+		// reposition to the region start before copying
+		if _, err := src.Seek(dataStart, io.SeekStart); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		if _, err := dst.Seek(dataStart, io.SeekStart); err != nil {
+			return trace.Wrap(err)
+		}
+		if _, err := io.CopyN(dst, src, dataEnd-dataStart); err != nil {
+			return trace.Wrap(err)
+		}
+		offset = dataEnd
+	}
+
+	return trace.Wrap(dst.Truncate(size))
+}
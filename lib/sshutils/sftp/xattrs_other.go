@@ -0,0 +1,27 @@
+//go:build !linux && !darwin
+
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftp
+
+import "github.com/gravitational/trace"
+
+// copyXattrs copies all extended attributes of the local file src to the
+// local file dst. Not supported on this platform.
+func copyXattrs(src, dst string) error {
+	return trace.NotImplemented("extended attributes are not supported on this platform")
+}
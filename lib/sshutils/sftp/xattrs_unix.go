@@ -0,0 +1,75 @@
+//go:build linux || darwin
+
+/*
+Copyright 2023 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sftp
+
+import (
+	"bytes"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/sys/unix"
+)
+
+// copyXattrs copies all extended attributes of the local file src to the
+// local file dst.
+func copyXattrs(src, dst string) error {
+	size, err := unix.Listxattr(src, nil)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if size == 0 {
+		return nil
+	}
+	buf := make([]byte, size)
+	size, err = unix.Listxattr(src, buf)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+
+	// the attribute list is a sequence of NUL-terminated names
+	for _, name := range bytes.Split(bytes.TrimRight(buf[:size], "\x00"), []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		value, err := getXattr(src, string(name))
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if err := unix.Setxattr(dst, string(name), value, 0); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+	}
+
+	return nil
+}
+
+func getXattr(path, name string) ([]byte, error) {
+	size, err := unix.Getxattr(path, name, nil)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	value := make([]byte, size)
+	if size == 0 {
+		return value, nil
+	}
+	size, err = unix.Getxattr(path, name, value)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return value[:size], nil
+}
@@ -25,7 +25,7 @@ import (
 
 var reProxyJump = regexp.MustCompile(
 	// optional username, note that outside group
-	`(?:(?P<username>[^\:]+)@)?(?P<hostport>[^\@]+)`,
+	`^(?:(?P<username>[^\:]+)@)?(?P<hostport>[^\@]+)$`,
 )
 
 // JumpHost is a target jump host
@@ -36,23 +36,51 @@ type JumpHost struct {
 	Addr NetAddr
 }
 
-// ParseProxyJump parses strings like user@host:port,bob@host:port
+// ParseProxyJump parses an OpenSSH -J proxy jump specification: a comma
+// separated chain of [user@]host[:port] hops, e.g.
+// "alice@host:port,bob@host2". IPv6 addresses must be enclosed in square
+// brackets when a port is given, matching ssh(1). Errors identify the
+// malformed hop.
 func ParseProxyJump(in string) ([]JumpHost, error) {
-	if in == "" {
+	if strings.TrimSpace(in) == "" {
 		return nil, trace.BadParameter("missing proxyjump")
 	}
 	parts := strings.Split(in, ",")
 	out := make([]JumpHost, 0, len(parts))
-	for _, part := range parts {
-		match := reProxyJump.FindStringSubmatch(strings.TrimSpace(part))
-		if len(match) == 0 {
-			return nil, trace.BadParameter("could not parse %q, expected format user@host:port,user@host:port", in)
-		}
-		addr, err := ParseAddr(match[2])
+	for i, part := range parts {
+		hop, err := parseProxyJumpHop(strings.TrimSpace(part))
 		if err != nil {
+			if len(parts) > 1 {
+				return nil, trace.BadParameter("could not parse hop %d of %d (%q): %v", i+1, len(parts), strings.TrimSpace(part), err)
+			}
 			return nil, trace.Wrap(err)
 		}
-		out = append(out, JumpHost{Username: match[1], Addr: *addr})
+		out = append(out, hop)
 	}
 	return out, nil
 }
+
+// parseProxyJumpHop parses a single [user@]host[:port] hop. The username may
+// itself contain "@" (e.g. email-style logins); everything after the last
+// "@" is treated as the address.
+func parseProxyJumpHop(in string) (JumpHost, error) {
+	if in == "" {
+		return JumpHost{}, trace.BadParameter("empty hop, expected format user@host:port")
+	}
+	match := reProxyJump.FindStringSubmatch(in)
+	if len(match) == 0 {
+		return JumpHost{}, trace.BadParameter("could not parse %q, expected format user@host:port", in)
+	}
+	username, hostport := match[1], match[2]
+	if strings.Contains(hostport, "[") != strings.Contains(hostport, "]") {
+		return JumpHost{}, trace.BadParameter("unbalanced brackets in address %q", hostport)
+	}
+	if !strings.Contains(hostport, "[") && strings.Count(hostport, ":") > 1 {
+		return JumpHost{}, trace.BadParameter("IPv6 addresses must be enclosed in square brackets, e.g. [::1]:3023")
+	}
+	addr, err := ParseAddr(hostport)
+	if err != nil {
+		return JumpHost{}, trace.Wrap(err)
+	}
+	return JumpHost{Username: username, Addr: *addr}, nil
+}
@@ -68,3 +68,45 @@ func TestProxyJumpParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestProxyJumpParsingErrors(t *testing.T) {
+	t.Parallel()
+
+	type tc struct {
+		in  string
+		err string
+	}
+	testCases := []tc{
+		{
+			in:  "",
+			err: "missing proxyjump",
+		},
+		{
+			in:  " , ",
+			err: "hop 1 of 2",
+		},
+		{
+			in:  "alice@host1,,bob@host2",
+			err: "hop 2 of 3",
+		},
+		{
+			in:  "alice@host1:22,bob@host2@",
+			err: "hop 2 of 2",
+		},
+		{
+			in:  "alice@::1:7777",
+			err: "square brackets",
+		},
+		{
+			in:  "alice@[::1:7777",
+			err: "unbalanced brackets",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%q", tc.in), func(t *testing.T) {
+			_, err := ParseProxyJump(tc.in)
+			require.Error(t, err)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// package socks implements a SOCKS5 handshake.
+// package socks implements SOCKS4, SOCKS4a, and SOCKS5 handshakes.
 package socks
 
 import (
@@ -43,20 +43,39 @@ const (
 	socks5AddressTypeDomainName byte = 0x03
 	socks5AddressTypeIPv6       byte = 0x04
 	socks5Succeeded             byte = 0x00
+
+	socks4Version        byte = 0x04
+	socks4CommandConnect byte = 0x01
+	socks4Granted        byte = 0x5a
+	socks4Rejected       byte = 0x5b
+
+	// socks4MaxStringLength caps the null-terminated user ID and hostname
+	// fields of a SOCKS4/4a request, protecting against unbounded reads.
+	socks4MaxStringLength = 2048
 )
 
-// Handshake performs a SOCKS5 handshake with the client and returns
-// the remote address to proxy the connection to.
+// Handshake performs a SOCKS handshake with the client and returns the remote
+// address to proxy the connection to. The protocol version is detected from
+// the first byte, so SOCKS5 and legacy SOCKS4/SOCKS4a clients are both
+// served on the same listener.
 func Handshake(conn net.Conn) (string, error) {
-	// Read in the version and reject anything other than SOCKS5.
 	version, err := readByte(conn)
 	if err != nil {
 		return "", trace.Wrap(err)
 	}
-	if version != socks5Version {
-		return "", trace.BadParameter("only SOCKS5 is supported")
+	switch version {
+	case socks5Version:
+		return handshakeSOCKS5(conn)
+	case socks4Version:
+		return handshakeSOCKS4(conn)
+	default:
+		return "", trace.BadParameter("unsupported SOCKS version %#x, only SOCKS4, SOCKS4a, and SOCKS5 are supported", version)
 	}
+}
 
+// handshakeSOCKS5 performs the remainder of a SOCKS5 handshake, after the
+// version byte has been consumed.
+func handshakeSOCKS5(conn net.Conn) (string, error) {
 	// Read in the authentication method requested by the client and write back
 	// the method that was selected. At the moment only "no authentication
 	// required" is supported.
@@ -87,6 +106,106 @@ func Handshake(conn net.Conn) (string, error) {
 	return remoteAddr, nil
 }
 
+// handshakeSOCKS4 performs the remainder of a SOCKS4 or SOCKS4a handshake,
+// after the version byte has been consumed. A SOCKS4a request is detected
+// from the marker destination address 0.0.0.x (x non-zero), in which case the
+// destination hostname follows the user ID.
+func handshakeSOCKS4(conn net.Conn) (string, error) {
+	// Read in the command the client is requesting. Only CONNECT is supported.
+	command, err := readByte(conn)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	if command != socks4CommandConnect {
+		writeSOCKS4Reply(conn, socks4Rejected)
+		return "", trace.BadParameter("only CONNECT command is supported")
+	}
+
+	// Unlike SOCKS5, the port comes before the address.
+	var destPort uint16
+	err = binary.Read(conn, binary.BigEndian, &destPort)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	destIP := make([]byte, net.IPv4len)
+	_, err = io.ReadFull(conn, destIP)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+
+	// Read in and throw away the null-terminated user ID; like OpenSSH,
+	// identd verification is not performed.
+	_, err = readNullTerminated(conn)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+
+	destAddr := net.IP(destIP).String()
+	if isSOCKS4aMarker(destIP) {
+		// SOCKS4a: the client could not resolve the destination itself and
+		// sends the hostname after the user ID.
+		hostname, err := readNullTerminated(conn)
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		if len(hostname) == 0 {
+			writeSOCKS4Reply(conn, socks4Rejected)
+			return "", trace.BadParameter("empty SOCKS4a destination hostname")
+		}
+		destAddr = string(hostname)
+	}
+
+	err = writeSOCKS4Reply(conn, socks4Granted)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+
+	return net.JoinHostPort(destAddr, strconv.Itoa(int(destPort))), nil
+}
+
+// isSOCKS4aMarker reports whether the destination address of a SOCKS4
+// request is the 0.0.0.x (x non-zero) marker that indicates a SOCKS4a
+// request with a trailing hostname.
+func isSOCKS4aMarker(ip []byte) bool {
+	return ip[0] == 0 && ip[1] == 0 && ip[2] == 0 && ip[3] != 0
+}
+
+// writeSOCKS4Reply writes a SOCKS4 reply with the given code. The destination
+// port and address in the reply are ignored by clients for CONNECT and are
+// left zeroed, as in the request granted reply of OpenSSH.
+func writeSOCKS4Reply(conn net.Conn, code byte) error {
+	message := []byte{0x00, code, 0, 0, 0, 0, 0, 0}
+	n, err := conn.Write(message)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if n != len(message) {
+		return trace.BadParameter("wrote: %v wanted to write: %v", n, len(message))
+	}
+
+	return nil
+}
+
+// readNullTerminated reads bytes from the connection until a null byte is
+// seen, returning the bytes before it. Reads are capped at
+// socks4MaxStringLength bytes to protect against misbehaving clients.
+func readNullTerminated(conn net.Conn) ([]byte, error) {
+	var out []byte
+	for {
+		b, err := readByte(conn)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if b == 0x00 {
+			return out, nil
+		}
+		if len(out) >= socks4MaxStringLength {
+			return nil, trace.BadParameter("SOCKS4 string field exceeds %v bytes", socks4MaxStringLength)
+		}
+		out = append(out, b)
+	}
+}
+
 // readAuthenticationMethod reads in the authentication methods the client
 // supports.
 func readAuthenticationMethod(conn net.Conn) ([]byte, error) {
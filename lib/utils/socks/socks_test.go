@@ -69,6 +69,60 @@ func TestHandshake(t *testing.T) {
 	}
 }
 
+func TestHandshakeSOCKS4(t *testing.T) {
+	t.Parallel()
+
+	socksServer, err := newDebugServer()
+	require.NoError(t, err)
+	go socksServer.Serve()
+
+	tests := []struct {
+		desc       string
+		request    []byte
+		remoteAddr string
+	}{
+		{
+			desc: "SOCKS4 with IPv4 destination",
+			// CONNECT to 9.8.7.6:443 with user ID "user".
+			request:    []byte{0x04, 0x01, 0x01, 0xbb, 9, 8, 7, 6, 'u', 's', 'e', 'r', 0x00},
+			remoteAddr: "9.8.7.6:443",
+		},
+		{
+			desc: "SOCKS4a with hostname destination",
+			// CONNECT to example.com:443, marker address 0.0.0.1 with the
+			// hostname trailing the empty user ID.
+			request:    append([]byte{0x04, 0x01, 0x01, 0xbb, 0, 0, 0, 1, 0x00}, append([]byte("example.com"), 0x00)...),
+			remoteAddr: "example.com:443",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conn, err := net.Dial("tcp", socksServer.Addr().String())
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = conn.Write(tt.request)
+			require.NoError(t, err)
+
+			// Read in the 8 byte reply and check the request was granted.
+			reply := make([]byte, 8)
+			_, err = io.ReadFull(conn, reply)
+			require.NoError(t, err)
+			require.Equal(t, byte(0x00), reply[0])
+			require.Equal(t, byte(0x5a), reply[1])
+
+			// The debug server echoes back the address requested.
+			buf := make([]byte, len(tt.remoteAddr))
+			_, err = io.ReadFull(conn, buf)
+			require.NoError(t, err)
+			require.Equal(t, tt.remoteAddr, string(buf))
+		})
+	}
+}
+
 // debugServer is a debug SOCKS5 server that performs a SOCKS5 handshake
 // then writes the remote address and closes the connection.
 type debugServer struct {
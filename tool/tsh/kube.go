@@ -888,6 +888,7 @@ type kubeLoginCommand struct {
 	impersonateGroups []string
 	namespace         string
 	all               bool
+	prefetchCerts     bool
 }
 
 func newKubeLoginCommand(parent *kingpin.CmdClause) *kubeLoginCommand {
@@ -901,6 +902,7 @@ func newKubeLoginCommand(parent *kingpin.CmdClause) *kubeLoginCommand {
 	// TODO (tigrato): move this back to namespace once teleport drops the namespace flag.
 	c.Flag("kube-namespace", "Configure the default Kubernetes namespace.").Short('n').StringVar(&c.namespace)
 	c.Flag("all", "Generate a kubeconfig with every cluster the user has access to.").BoolVar(&c.all)
+	c.Flag("prefetch-certs", "Issue certificates for the selected clusters up front instead of on first kubectl use.").BoolVar(&c.prefetchCerts)
 	return c
 }
 
@@ -947,6 +949,13 @@ func (c *kubeLoginCommand) run(cf *CLIConf) error {
 	if err := updateKubeConfig(cf, tc, profileKubeconfigPath); err != nil {
 		return trace.Wrap(err)
 	}
+
+	if c.prefetchCerts {
+		if err := c.prefetchClusterCerts(cf, tc, clusterNames); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
 	if c.kubeCluster != "" {
 		fmt.Printf("Logged into Kubernetes cluster %q. Try 'kubectl version' to test the connection.\n", c.kubeCluster)
 	} else {
@@ -955,6 +964,37 @@ func (c *kubeLoginCommand) run(cf *CLIConf) error {
 	return nil
 }
 
+// prefetchClusterCerts issues certificates for the selected kubernetes
+// clusters in one parallel pass so the first kubectl call doesn't have to.
+func (c *kubeLoginCommand) prefetchClusterCerts(cf *CLIConf, tc *client.TeleportClient, clusterNames []string) error {
+	selected := clusterNames
+	if !c.all {
+		selected = []string{c.kubeCluster}
+	}
+	logins := make([]client.KubeClusterLogin, 0, len(selected))
+	for _, name := range selected {
+		logins = append(logins, client.KubeClusterLogin{KubeCluster: name})
+	}
+
+	return client.RetryWithRelogin(cf.Context, tc, func() error {
+		results, err := tc.LoginToKubeClusters(cf.Context, logins, func(result client.KubeLoginResult) {
+			if result.Err != nil {
+				fmt.Printf("Failed to fetch certificate for Kubernetes cluster %q: %v\n", result.Login.KubeCluster, result.Err)
+			} else {
+				fmt.Printf("Fetched certificate for Kubernetes cluster %q\n", result.Login.KubeCluster)
+			}
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		var errs []error
+		for _, result := range results {
+			errs = append(errs, result.Err)
+		}
+		return trace.NewAggregate(errs...)
+	})
+}
+
 func fetchKubeClusters(ctx context.Context, tc *client.TeleportClient) (teleportCluster string, kubeClusters []types.KubeCluster, err error) {
 	err = client.RetryWithRelogin(ctx, tc, func() error {
 		pc, err := tc.ConnectToProxy(ctx)
@@ -1110,6 +1150,13 @@ func updateKubeConfig(cf *CLIConf, tc *client.TeleportClient, path string) error
 		return trace.Wrap(err)
 	}
 
+	// Apply profile-level kubeconfig conventions (context naming template,
+	// per-cluster default namespaces).
+	values.ContextNameTemplate = tc.KubeContextTemplate
+	if len(tc.KubeNamespaces) > 0 {
+		values.Namespaces = tc.KubeNamespaces
+	}
+
 	path = getKubeConfigPath(cf, path)
 
 	// If this is a profile specific kubeconfig, we only need
@@ -152,6 +152,8 @@ type CLIConf struct {
 	NodeLogin string
 	// InsecureSkipVerify bypasses verification of HTTPS certificate when talking to web proxy
 	InsecureSkipVerify bool
+	// CAPins are SPKI hashes the proxy certificate chain is verified against
+	CAPins []string
 	// SessionID identifies the session tsh is operating on.
 	// For `tsh join`, it is the ID of the session to join.
 	// For `tsh play`, it is either the ID of the session to play,
@@ -174,6 +176,9 @@ type CLIConf struct {
 	ProxyJump string
 	// --local flag for ssh
 	LocalExec bool
+	// LocalRecordingDir is the directory interactive sessions write local
+	// typescript/timing captures to. Empty disables local capture.
+	LocalRecordingDir string
 	// SiteName specifies remote site go login to
 	SiteName string
 	// KubernetesCluster specifies the kubernetes cluster to login to.
@@ -584,6 +589,9 @@ func Run(ctx context.Context, args []string, opts ...cliOption) error {
 		app.Flag("insecure", "Do not verify server's certificate and host name. Use only in test environments").
 			Default("false").
 			BoolVar(&cf.InsecureSkipVerify)
+		app.Flag("ca-pin", "Verify the proxy against this CA pin (format: sha256:<hex>). Can be repeated").
+			Envar("TELEPORT_CA_PIN").
+			StringsVar(&cf.CAPins)
 	}
 
 	app.Flag("auth", "Specify the name of authentication connector to use.").Envar(authEnvVar).StringVar(&cf.AuthConnector)
@@ -633,6 +641,7 @@ func Run(ctx context.Context, args []string, opts ...cliOption) error {
 	ssh.Flag("participant-req", "Displays a verbose list of required participants in a moderated session.").BoolVar(&cf.displayParticipantRequirements)
 	ssh.Flag("request-reason", "Reason for requesting access").StringVar(&cf.RequestReason)
 	ssh.Flag("disable-access-request", "Disable automatic resource access requests").BoolVar(&cf.disableAccessRequest)
+	ssh.Flag("local-recording-dir", "Write a script(1)-compatible typescript and timing file of interactive sessions to this directory").StringVar(&cf.LocalRecordingDir)
 
 	// Daemon service for teleterm client
 	daemon := app.Command("daemon", "Daemon is the tsh daemon service").Hidden()
@@ -3275,6 +3284,7 @@ func makeClientForProxy(cf *CLIConf, proxy string, useProfileLogin bool) (*clien
 	c.Labels = labels
 	c.KeyTTL = time.Minute * time.Duration(cf.MinsToLive)
 	c.InsecureSkipVerify = cf.InsecureSkipVerify
+	c.CAPins = cf.CAPins
 	c.PredicateExpression = cf.PredicateExpression
 
 	if cf.SearchKeywords != "" {
@@ -3347,6 +3357,7 @@ func makeClientForProxy(cf *CLIConf, proxy string, useProfileLogin bool) (*clien
 	}
 
 	c.EnableEscapeSequences = cf.EnableEscapeSequences
+	c.LocalRecordingDir = cf.LocalRecordingDir
 
 	// pass along mock sso login if provided (only used in tests)
 	c.MockSSOLogin = cf.mockSSOLogin